@@ -2,7 +2,10 @@ module github.com/youpele52/lazysetup
 
 go 1.25.0
 
-require github.com/jesseduffield/gocui v0.3.0
+require (
+	github.com/jesseduffield/gocui v0.3.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/mattn/go-runewidth v0.0.9 // indirect
@@ -3,23 +3,43 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/jesseduffield/gocui"
+	"github.com/youpele52/lazysetup/pkg/cli"
+	"github.com/youpele52/lazysetup/pkg/colors"
+	"github.com/youpele52/lazysetup/pkg/config"
 	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/handlers"
+	"github.com/youpele52/lazysetup/pkg/history"
 	"github.com/youpele52/lazysetup/pkg/models"
 	"github.com/youpele52/lazysetup/pkg/ui"
 	"github.com/youpele52/lazysetup/pkg/updater"
 )
 
 func main() {
-	state := models.NewState()
+	// Subcommands (export, restore, ...) run without starting the TUI
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	cfg, err := loadUserConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lazysetup: %v\n", err)
+		os.Exit(1)
+	}
+	colors.Setup(cfg.GetTheme())
+	state := models.NewStateFromConfig(cfg)
+	loadDurationHistory(state)
 
 	g := gocui.NewGui()
 	if err := g.Init(); err != nil {
 		log.Panicln(err)
 	}
-	defer g.Close()
 
 	g.SetLayout(ui.Layout(state))
 	ui.SetupKeybindings(g, state)
@@ -27,17 +47,73 @@ func main() {
 	// Start UI refresh goroutine for animations and status updates
 	go refreshUI(g, state)
 
-	// Check for updates on startup (in background)
-	go checkForUpdates(state)
+	// Check for updates on startup (in background) unless the config file turns it off
+	if cfg.Updates.CheckEnabled() {
+		go checkForUpdates(state, cfg.Updates)
+	}
+
+	// SIGINT, SIGTERM and SIGHUP (terminal closed) quit like a confirmed Ctrl+C
+	// Once the TUI has closed, a signal stops waiting for cancelled actions and exits
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	var received atomic.Value
+	var uiClosed atomic.Bool
+	go func() {
+		for sig := range signals {
+			if uiClosed.Load() {
+				os.Exit(128 + int(sig.(syscall.Signal)))
+			}
+			received.Store(sig)
+			g.Execute(func(*gocui.Gui) error { return gocui.ErrQuit })
+		}
+	}()
 
-	if err := g.MainLoop(); err != nil && err != gocui.ErrQuit {
+	err = g.MainLoop()
+	g.Close()
+	uiClosed.Store(true)
+	if err != nil && err != gocui.ErrQuit {
 		log.Panicln(err)
 	}
+
+	// Running actions are cancelled and waited for after the TUI closes, so their
+	// package managers get the chance to stop cleanly
+	stopped := handlers.Shutdown(state, os.Stdout, handlers.ShutdownTimeout)
+	if sig, ok := received.Load().(syscall.Signal); ok {
+		os.Exit(128 + int(sig))
+	}
+	if !stopped {
+		os.Exit(1)
+	}
 }
 
-// checkForUpdates checks for available updates on startup
-func checkForUpdates(state *models.State) {
-	info := updater.CheckForUpdates()
+// loadUserConfig reads and validates the config file, an empty config when there is none
+func loadUserConfig() (*config.UserConfig, error) {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadUserConfig(path)
+}
+
+// loadDurationHistory restores past action durations for ETAs
+// An unreadable file is not fatal, it only means there are no estimates yet
+func loadDurationHistory(state *models.State) {
+	path, err := history.Path()
+	if err != nil {
+		return
+	}
+	store, _ := history.Load(path)
+	state.SetDurationHistory(store)
+}
+
+// checkForUpdates checks for available updates on startup, at most once per configured interval
+func checkForUpdates(state *models.State, updates config.UpdatesConfig) {
+	var info *updater.UpdateInfo
+	if path, err := updater.CachePath(); err == nil {
+		info = updater.CheckForUpdatesCached(updates.Source(), path, updates.GetInterval())
+	} else {
+		info = updater.CheckForUpdates(updates.Source())
+	}
 	if info.Error != nil {
 		return
 	}
@@ -45,6 +121,9 @@ func checkForUpdates(state *models.State) {
 		state.UpdateAvailable = true
 		state.UpdateVersion = info.LatestVersion
 		state.UpdateDownloadURL = info.DownloadURL
+		state.UpdateAssetName = info.AssetName
+		state.UpdateChecksumURL = info.ChecksumURL
+		state.UpdateNotes = info.Notes
 		state.UpdateMessage = fmt.Sprintf(constants.UpdateAvailable, info.CurrentVersion, info.LatestVersion)
 		state.UpdateMessageTime = time.Now().Unix()
 	}
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxOutputBytes caps how much command output is sent to a provider
+// The end of the output usually holds the error, so the tail is kept
+const maxOutputBytes = 4000
+
+// Request describes a failed tool action for a provider to explain
+type Request struct {
+	Tool     string // Tool that failed, e.g. "lazygit"
+	Action   string // Action that failed: install, update or uninstall
+	Method   string // Package manager the action ran with
+	Command  string // Command that was run
+	Output   string // Combined command output
+	Category string // Category from the failure classifier, if any
+	OS       string // runtime.GOOS
+	Arch     string // runtime.GOARCH
+}
+
+// Provider suggests a fix for a failed tool action
+type Provider interface {
+	// Name identifies the provider in the UI, e.g. "local (llama3.2)"
+	Name() string
+	// Suggest returns a short, human-readable suggested fix
+	Suggest(ctx context.Context, req Request) (string, error)
+}
+
+// systemPrompt frames the request for every provider
+const systemPrompt = "You help developers fix failed command-line tool installations. " +
+	"Reply with the most likely cause in one sentence, then at most three numbered steps " +
+	"with exact shell commands. Be concise and do not repeat the output back."
+
+// BuildPrompt renders a request as the user message sent to a provider
+func BuildPrompt(req Request) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Tool: %s\n", req.Tool)
+	fmt.Fprintf(&b, "Action: %s via %s\n", req.Action, req.Method)
+	fmt.Fprintf(&b, "System: %s/%s\n", req.OS, req.Arch)
+	if req.Category != "" {
+		fmt.Fprintf(&b, "Classified as: %s\n", req.Category)
+	}
+	if req.Command != "" {
+		fmt.Fprintf(&b, "Command:\n%s\n", req.Command)
+	}
+	fmt.Fprintf(&b, "Output:\n%s\n", tail(strings.TrimSpace(req.Output), maxOutputBytes))
+	return b.String()
+}
+
+// tail returns the last n bytes of s, starting at a line boundary when possible
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[len(s)-n:]
+	if i := strings.IndexByte(s, '\n'); i >= 0 && i < len(s)-1 {
+		s = s[i+1:]
+	}
+	return s
+}
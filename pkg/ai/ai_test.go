@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+)
+
+var testRequest = Request{
+	Tool:     "lazygit",
+	Action:   "install",
+	Method:   "APT",
+	Command:  "apt-get install -y lazygit",
+	Output:   "E: Unable to locate package lazygit",
+	Category: "package not found",
+	OS:       "linux",
+	Arch:     "amd64",
+}
+
+// TestNew tests building the configured provider.
+// Priority: P0 - Nothing may be sent to a provider without explicit opt-in.
+// Tests the disabled default, missing API keys and provider selection.
+func TestNew(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		if _, err := New(config.AIConfig{Provider: config.AIProviderOpenAI}); err != ErrDisabled {
+			t.Errorf("Expected ErrDisabled, got %v", err)
+		}
+	})
+
+	t.Run("local provider needs no API key", func(t *testing.T) {
+		provider, err := New(config.AIConfig{Enabled: true})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		local, ok := provider.(*OpenAICompatible)
+		if !ok || !strings.HasPrefix(local.BaseURL, "http://localhost") {
+			t.Errorf("Expected a localhost OpenAI-compatible provider, got %#v", provider)
+		}
+	})
+
+	t.Run("remote provider without API key fails", func(t *testing.T) {
+		t.Setenv("ANTHROPIC_API_KEY", "")
+		if _, err := New(config.AIConfig{Enabled: true, Provider: config.AIProviderAnthropic}); err == nil {
+			t.Error("Expected an error for a missing API key")
+		}
+	})
+
+	t.Run("API key is read from the configured variable", func(t *testing.T) {
+		t.Setenv("MY_KEY", "sk-test")
+		provider, err := New(config.AIConfig{Enabled: true, Provider: config.AIProviderAnthropic, APIKeyEnv: "MY_KEY"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if provider.(*Anthropic).APIKey != "sk-test" {
+			t.Error("Expected API key from MY_KEY")
+		}
+	})
+}
+
+// TestOpenAICompatible_Suggest tests the OpenAI-compatible provider.
+// Priority: P1 - Local and OpenAI-compatible servers are the default provider.
+// Tests the request sent and the parsed suggestion.
+func TestOpenAICompatible_Suggest(t *testing.T) {
+	t.Run("sends prompt and returns the first choice", func(t *testing.T) {
+		var got chatRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/chat/completions" {
+				t.Errorf("Unexpected path %s", r.URL.Path)
+			}
+			if r.Header.Get("Authorization") != "" {
+				t.Error("Expected no Authorization header without an API key")
+			}
+			json.NewDecoder(r.Body).Decode(&got)
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" Use the Curl method. "}}]}`))
+		}))
+		defer server.Close()
+
+		provider := &OpenAICompatible{Label: "local", BaseURL: server.URL + "/v1", Model: "llama3.2"}
+		text, err := provider.Suggest(context.Background(), testRequest)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if text != "Use the Curl method." {
+			t.Errorf("Expected trimmed suggestion, got %q", text)
+		}
+		if got.Model != "llama3.2" || len(got.Messages) != 2 || !strings.Contains(got.Messages[1].Content, "Unable to locate package") {
+			t.Errorf("Unexpected request %+v", got)
+		}
+	})
+
+	t.Run("HTTP errors are returned", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "model not found", http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		provider := &OpenAICompatible{Label: "local", BaseURL: server.URL, Model: "missing"}
+		if _, err := provider.Suggest(context.Background(), testRequest); err == nil || !strings.Contains(err.Error(), "404") {
+			t.Errorf("Expected a 404 error, got %v", err)
+		}
+	})
+}
+
+// TestAnthropic_Suggest tests the Anthropic provider.
+// Priority: P2 - Remote provider requests must carry the key and version headers.
+// Tests headers and text block parsing.
+func TestAnthropic_Suggest(t *testing.T) {
+	t.Run("sends headers and joins text blocks", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "sk-test" || r.Header.Get("anthropic-version") == "" {
+				t.Errorf("Unexpected request %s %v", r.URL.Path, r.Header)
+			}
+			w.Write([]byte(`{"content":[{"type":"text","text":"Install from Homebrew."}]}`))
+		}))
+		defer server.Close()
+
+		provider := &Anthropic{BaseURL: server.URL, Model: "claude-3-5-haiku-latest", APIKey: "sk-test"}
+		text, err := provider.Suggest(context.Background(), testRequest)
+		if err != nil || text != "Install from Homebrew." {
+			t.Errorf("Expected suggestion, got %q (err %v)", text, err)
+		}
+	})
+}
+
+// TestBuildPrompt tests the prompt sent to providers.
+// Priority: P2 - Huge outputs must not be sent whole.
+// Tests included context and output truncation.
+func TestBuildPrompt(t *testing.T) {
+	t.Run("includes context", func(t *testing.T) {
+		prompt := BuildPrompt(testRequest)
+		for _, want := range []string{"lazygit", "install via APT", "linux/amd64", "apt-get install -y lazygit", "package not found"} {
+			if !strings.Contains(prompt, want) {
+				t.Errorf("Expected prompt to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("keeps only the tail of long output", func(t *testing.T) {
+		req := testRequest
+		req.Output = strings.Repeat("noise line\n", 2000) + "E: the real error"
+		prompt := BuildPrompt(req)
+		if len(prompt) > maxOutputBytes+500 || !strings.Contains(prompt, "E: the real error") {
+			t.Errorf("Expected truncated prompt ending with the error, got %d bytes", len(prompt))
+		}
+	})
+}
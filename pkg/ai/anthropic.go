@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// anthropicVersion is the Messages API version header value
+const anthropicVersion = "2023-06-01"
+
+// Anthropic talks to the Anthropic Messages API
+type Anthropic struct {
+	BaseURL string // Defaults to https://api.anthropic.com
+	Model   string
+	APIKey  string
+	Client  *http.Client
+}
+
+type anthropicRequest struct {
+	Model     string        `json:"model"`
+	MaxTokens int           `json:"max_tokens"`
+	System    string        `json:"system"`
+	Messages  []chatMessage `json:"messages"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Name returns the provider label and model
+func (p *Anthropic) Name() string {
+	return fmt.Sprintf("anthropic (%s)", p.Model)
+}
+
+// Suggest sends the failure to the Messages API
+func (p *Anthropic) Suggest(ctx context.Context, req Request) (string, error) {
+	body, err := json.Marshal(anthropicRequest{
+		Model:     p.Model,
+		MaxTokens: 512,
+		System:    systemPrompt,
+		Messages:  []chatMessage{{Role: "user", Content: BuildPrompt(req)}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com"
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", strings.TrimRight(baseURL, "/")+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.APIKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+
+	var resp anthropicResponse
+	if err := doJSON(p.Client, httpReq, &resp); err != nil {
+		return "", err
+	}
+	if resp.Error != nil {
+		return "", fmt.Errorf("%s", resp.Error.Message)
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", fmt.Errorf("empty response from anthropic")
+	}
+	return strings.TrimSpace(text.String()), nil
+}
@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"context"
+	"sync"
+)
+
+// Mock is a Provider for tests that returns a fixed suggestion or error and records requests
+type Mock struct {
+	Suggestion string
+	Err        error
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// Name returns "mock"
+func (m *Mock) Name() string {
+	return "mock"
+}
+
+// Suggest records the request and returns the configured suggestion or error
+func (m *Mock) Suggest(ctx context.Context, req Request) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, req)
+	return m.Suggestion, m.Err
+}
+
+// Requests returns the requests received so far
+func (m *Mock) Requests() []Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	requests := make([]Request, len(m.requests))
+	copy(requests, m.requests)
+	return requests
+}
@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAICompatible talks to any endpoint implementing the OpenAI chat completions API
+// This covers OpenAI, OpenRouter and local servers such as Ollama, llama.cpp or LM Studio
+type OpenAICompatible struct {
+	Label   string // Provider name shown in the UI
+	BaseURL string // e.g. http://localhost:11434/v1
+	Model   string
+	APIKey  string // Optional for local servers
+	Client  *http.Client
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Name returns the provider label and model
+func (p *OpenAICompatible) Name() string {
+	return fmt.Sprintf("%s (%s)", p.Label, p.Model)
+}
+
+// Suggest sends the failure to the chat completions endpoint
+func (p *OpenAICompatible) Suggest(ctx context.Context, req Request) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: p.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: BuildPrompt(req)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", strings.TrimRight(p.BaseURL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	var resp chatResponse
+	if err := doJSON(p.Client, httpReq, &resp); err != nil {
+		return "", err
+	}
+	if resp.Error != nil {
+		return "", fmt.Errorf("%s", resp.Error.Message)
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("empty response from %s", p.Label)
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
+
+// doJSON performs an HTTP request and decodes a JSON response body
+// Non-2xx responses are returned as errors that include a snippet of the body
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("provider returned status %d: %s", resp.StatusCode, tail(strings.TrimSpace(string(data)), 200))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+)
+
+// ErrDisabled is returned by New when AI suggestions are not enabled in the config file
+var ErrDisabled = errors.New("AI suggestions are off; set ai.enabled: true in the config file to opt in")
+
+// providerDefaults holds the endpoint, model and API key variable used when the config leaves them unset
+var providerDefaults = map[string]struct {
+	endpoint, model, apiKeyEnv string
+}{
+	config.AIProviderLocal:      {"http://localhost:11434/v1", "llama3.2", ""},
+	config.AIProviderOpenAI:     {"https://api.openai.com/v1", "gpt-4o-mini", "OPENAI_API_KEY"},
+	config.AIProviderOpenRouter: {"https://openrouter.ai/api/v1", "mistralai/mixtral-8x7b-instruct", "OPENROUTER_API_KEY"},
+	config.AIProviderAnthropic:  {"https://api.anthropic.com", "claude-3-5-haiku-latest", "ANTHROPIC_API_KEY"},
+}
+
+// New builds the provider configured in the config file
+// Returns ErrDisabled unless the user opted in, and an error when a remote provider has no API key
+func New(cfg config.AIConfig) (Provider, error) {
+	if !cfg.Enabled {
+		return nil, ErrDisabled
+	}
+
+	name := cfg.Provider
+	if name == "" {
+		name = config.AIProviderLocal
+	}
+	defaults, ok := providerDefaults[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown AI provider %q", name)
+	}
+
+	endpoint := valueOr(cfg.Endpoint, defaults.endpoint)
+	model := valueOr(cfg.Model, defaults.model)
+	keyEnv := valueOr(cfg.APIKeyEnv, defaults.apiKeyEnv)
+
+	var apiKey string
+	if keyEnv != "" {
+		apiKey = os.Getenv(keyEnv)
+		if apiKey == "" && name != config.AIProviderLocal {
+			return nil, fmt.Errorf("%s needs an API key in $%s", name, keyEnv)
+		}
+	}
+
+	if name == config.AIProviderAnthropic {
+		return &Anthropic{BaseURL: endpoint, Model: model, APIKey: apiKey}, nil
+	}
+	return &OpenAICompatible{Label: name, BaseURL: endpoint, Model: model, APIKey: apiKey}, nil
+}
+
+func valueOr(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,230 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestVersion is the schema version written to new bundle manifests
+const ManifestVersion = 1
+
+// DefaultPath is the bundle `lazysetup bundle create` writes when no -o is given
+const DefaultPath = "lazysetup-bundle"
+
+// ManifestFile is the name of the manifest at the root of a bundle
+const ManifestFile = "manifest.json"
+
+// filesDir holds one directory of downloaded files per tool
+const filesDir = "files"
+
+// Manifest describes the files a bundle holds and the machine it was created for
+// Downloads resolve the architecture at creation time, so a bundle only installs on the
+// same OS and architecture
+type Manifest struct {
+	Version   int     `json:"version"`
+	CreatedAt string  `json:"created_at"`
+	OS        string  `json:"os"`
+	Arch      string  `json:"arch"`
+	Tools     []Entry `json:"tools"`
+}
+
+// Entry is one bundled tool: the method it installs with and its downloaded files
+type Entry struct {
+	Tool   string `json:"tool"`
+	Method string `json:"method"`
+	Files  []File `json:"files"`
+}
+
+// File is a downloaded file, relative to the bundle root, and its checksum
+type File struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// IsArchive reports whether path names a bundle tarball rather than a directory
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// LoadManifest reads and validates the manifest of the bundle directory dir
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	if manifest.Version == 0 || manifest.Version > ManifestVersion {
+		return nil, fmt.Errorf("unsupported bundle manifest version %d", manifest.Version)
+	}
+	return &manifest, nil
+}
+
+// saveManifest writes the manifest to the root of the bundle directory dir
+func saveManifest(dir string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write bundle manifest: %w", err)
+	}
+	return nil
+}
+
+// hashFile returns the hex SHA-256 and size of the file at path
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// verify checks that every file of entry is present in dir with its recorded checksum
+func verify(dir string, entry Entry) error {
+	for _, file := range entry.Files {
+		sum, _, err := hashFile(filepath.Join(dir, file.Path))
+		if err != nil {
+			return fmt.Errorf("missing bundle file %s: %w", file.Path, err)
+		}
+		if sum != file.SHA256 {
+			return fmt.Errorf("checksum mismatch for %s", file.Path)
+		}
+	}
+	return nil
+}
+
+// Pack writes the bundle directory dir to a gzipped tarball at path
+func Pack(dir, path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle archive: %w", err)
+	}
+	defer out.Close()
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || file == dir {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write bundle archive: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle archive: %w", err)
+	}
+	return out.Close()
+}
+
+// Unpack extracts the bundle tarball at path into dir
+// Entries that would land outside dir are rejected
+func Unpack(path, dir string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle archive: %w", err)
+	}
+	defer in.Close()
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read bundle archive: %w", err)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("bundle archive entry %q escapes the bundle", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode)&0755|0600)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Open returns the directory of the bundle at path, extracting a tarball to a temporary
+// directory; cleanup removes anything Open created
+func Open(path string) (dir string, cleanup func(), err error) {
+	if !IsArchive(path) {
+		return path, func() {}, nil
+	}
+	dir, err = os.MkdirTemp("", "lazysetup-bundle-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	if err := Unpack(path, dir); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return dir, cleanup, nil
+}
@@ -0,0 +1,219 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestRecipeFor tests which installs can be bundled and how.
+// Priority: P1 - A bundled install that reaches the network defeats offline hosts.
+// Tests Curl downloads, installer scripts, package managers and unsupported methods.
+func TestRecipeFor(t *testing.T) {
+	t.Run("bundles the file a Curl install downloads", func(t *testing.T) {
+		r, err := recipeFor("Curl", "jq")
+		if err != nil {
+			t.Fatalf("Expected jq to be bundleable, got %v", err)
+		}
+		if !strings.HasPrefix(r.fetch, "curl -fsSL https://") || !strings.HasSuffix(r.fetch, "-o 'jq'") {
+			t.Errorf("Unexpected fetch command %q", r.fetch)
+		}
+		install := r.install([]string{"/b/files/jq/jq"})
+		if !strings.HasPrefix(install, "cp '/b/files/jq/jq' '/tmp/jq' && chmod +x /tmp/jq") {
+			t.Errorf("Unexpected install command %q", install)
+		}
+		if strings.Contains(install, "curl") {
+			t.Errorf("Expected offline install, got %q", install)
+		}
+	})
+
+	t.Run("refuses installer scripts and piped installs", func(t *testing.T) {
+		for _, tool := range []string{"git", "lazygit", "zoxide", "helm"} {
+			if _, err := recipeFor("Curl", tool); err == nil {
+				t.Errorf("Expected %s to be refused", tool)
+			}
+		}
+	})
+
+	t.Run("installs package files without downloading", func(t *testing.T) {
+		r, err := recipeFor("APT", "git")
+		if err != nil {
+			t.Fatalf("Expected APT to be bundleable, got %v", err)
+		}
+		want := `mkdir -p partial && apt-get install -y --download-only --reinstall -o Dir::Cache::archives="$PWD" 'git' && rm -rf partial lock`
+		if r.fetch != want {
+			t.Errorf("Expected %s, got %s", want, r.fetch)
+		}
+		if got := r.install([]string{"/b/git.deb"}); got != "apt-get install -y --no-download '/b/git.deb'" {
+			t.Errorf("Unexpected install command %q", got)
+		}
+	})
+
+	t.Run("homebrew bundles the dependencies too", func(t *testing.T) {
+		r, _ := recipeFor("Homebrew", "nvim")
+		if !strings.HasPrefix(r.fetch, "brew fetch --deps 'nvim' && for f in $(brew deps 'nvim') 'nvim'; do") {
+			t.Errorf("Unexpected fetch command %q", r.fetch)
+		}
+	})
+
+	t.Run("rejects methods without a recipe", func(t *testing.T) {
+		if _, err := recipeFor("Scoop", "git"); err == nil {
+			t.Error("Expected Scoop to be refused")
+		}
+	})
+}
+
+// fakeFetch writes one file named after the download into the tool's bundle directory
+func fakeFetch(ctx context.Context, dir, command string) error {
+	return os.WriteFile(filepath.Join(dir, "artifact"), []byte(command), 0644)
+}
+
+// TestCreateAndInstall tests creating a bundle and installing from it.
+// Priority: P0 - Offline hosts rely on the bundle holding exactly what installs need.
+// Tests the manifest, skipped tools, checksums, pre-flights, tarballs and tool selection.
+func TestCreateAndInstall(t *testing.T) {
+	ctx := context.Background()
+	create := func(t *testing.T) (string, *Manifest, []Result) {
+		t.Helper()
+		dir := filepath.Join(t.TempDir(), "bundle")
+		manifest, results, err := Create(ctx, dir, CreateOptions{Method: "Curl", Tools: []string{"jq", "git"}, Fetch: fakeFetch})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return dir, manifest, results
+	}
+	install := func(t *testing.T, dir string, tools ...string) ([]string, []Result, error) {
+		t.Helper()
+		var ran []string
+		results, err := Install(ctx, dir, InstallOptions{Tools: tools, Install: func(ctx context.Context, method, tool, command string) error {
+			ran = append(ran, command)
+			return nil
+		}})
+		return ran, results, err
+	}
+
+	t.Run("bundles what it can and records checksums", func(t *testing.T) {
+		dir, manifest, results := create(t)
+		if len(results) != 2 || results[0].Status != StatusBundled || results[1].Status != StatusSkipped {
+			t.Fatalf("Expected jq bundled and git skipped, got %+v", results)
+		}
+		if manifest.OS != runtime.GOOS || manifest.Arch != runtime.GOARCH {
+			t.Errorf("Expected this machine's platform, got %s/%s", manifest.OS, manifest.Arch)
+		}
+		loaded, err := LoadManifest(dir)
+		if err != nil {
+			t.Fatalf("Expected manifest, got %v", err)
+		}
+		if len(loaded.Tools) != 1 || loaded.Tools[0].Files[0].Path != "files/jq/artifact" || len(loaded.Tools[0].Files[0].SHA256) != 64 {
+			t.Errorf("Unexpected manifest %+v", loaded.Tools)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "files", "git")); err == nil {
+			t.Error("Expected no directory for a skipped tool")
+		}
+	})
+
+	t.Run("installs from the bundled files", func(t *testing.T) {
+		dir, _, _ := create(t)
+		ran, results, err := install(t, dir)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(results) != 1 || results[0].Status != StatusInstalled {
+			t.Fatalf("Expected jq installed, got %+v", results)
+		}
+		abs, _ := filepath.Abs(dir)
+		if len(ran) != 1 || !strings.HasPrefix(ran[0], "cd '"+abs+"' && cp '"+filepath.Join("files", "jq", "artifact")+"' '") {
+			t.Fatalf("Expected install from the bundle, got %v", ran)
+		}
+		if strings.Contains(ran[0], "'/tmp/jq'") || !strings.Contains(ran[0], "lazysetup-run-") {
+			t.Errorf("Expected the download copied into a work directory of the run, got %s", ran[0])
+		}
+	})
+
+	t.Run("refuses tampered files", func(t *testing.T) {
+		dir, _, _ := create(t)
+		os.WriteFile(filepath.Join(dir, "files", "jq", "artifact"), []byte("tampered"), 0644)
+		ran, results, _ := install(t, dir)
+		if len(ran) != 0 || results[0].Status != StatusFailed || !strings.Contains(results[0].Detail, "checksum") {
+			t.Errorf("Expected checksum failure, got %+v", results)
+		}
+	})
+
+	t.Run("rejects tools missing from the bundle", func(t *testing.T) {
+		dir, _, _ := create(t)
+		if _, _, err := install(t, dir, "git"); err == nil {
+			t.Error("Expected error for a tool that is not bundled")
+		}
+	})
+
+	t.Run("rejects bundles for another platform", func(t *testing.T) {
+		dir, manifest, _ := create(t)
+		manifest.Arch = "other"
+		saveManifest(dir, manifest)
+		if _, _, err := install(t, dir); err == nil {
+			t.Error("Expected error for another architecture")
+		}
+	})
+
+	t.Run("runs each method's pre-flight once and goes on when it fails", func(t *testing.T) {
+		dir, _, _ := create(t)
+		var preflights, failed []string
+		_, err := Install(ctx, dir, InstallOptions{
+			Install: func(ctx context.Context, method, tool, command string) error { return nil },
+			Preflight: func(ctx context.Context, method string) error {
+				preflights = append(preflights, method)
+				return errors.New("offline")
+			},
+			PreflightFailed: func(method string, err error) { failed = append(failed, method) },
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(preflights) != 1 || preflights[0] != "Curl" || len(failed) != 1 {
+			t.Errorf("Expected one reported Curl pre-flight, got %v and %v", preflights, failed)
+		}
+	})
+
+	t.Run("round-trips through a tarball", func(t *testing.T) {
+		dir, _, _ := create(t)
+		archive := filepath.Join(t.TempDir(), "bundle.tar.gz")
+		if err := Pack(dir, archive); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		opened, cleanup, err := Open(archive)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer cleanup()
+		if _, results, err := install(t, opened); err != nil || len(results) != 1 || results[0].Status != StatusInstalled {
+			t.Errorf("Expected jq installed from the tarball, got %+v, %v", results, err)
+		}
+	})
+}
+
+// TestUnpack tests extracting bundle tarballs.
+// Priority: P1 - A crafted tarball must not write outside the bundle directory.
+// Tests path traversal.
+func TestUnpack(t *testing.T) {
+	t.Run("rejects entries escaping the bundle", func(t *testing.T) {
+		archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+		f, _ := os.Create(archive)
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+		tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+		tw.Write([]byte("x"))
+		tw.Close()
+		gz.Close()
+		f.Close()
+
+		if err := Unpack(archive, t.TempDir()); err == nil {
+			t.Error("Expected error for an entry outside the bundle")
+		}
+	})
+}
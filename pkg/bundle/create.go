@@ -0,0 +1,149 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/commands"
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/executor"
+)
+
+// Status describes what happened to one tool while creating or installing a bundle
+type Status string
+
+const (
+	StatusBundled   Status = "bundled"   // Its files were downloaded into the bundle
+	StatusInstalled Status = "installed" // Installed from the bundle
+	StatusSkipped   Status = "skipped"   // Cannot be bundled with its method, see Detail
+	StatusFailed    Status = "failed"    // Downloading or installing failed, see Detail
+)
+
+// Result is the outcome of bundling or installing one tool
+type Result struct {
+	Tool   string
+	Method string
+	Status Status
+	Detail string
+}
+
+// CreateOptions controls which tools a bundle holds
+// Fetch runs a download command in a tool's bundle directory; it defaults to the shell
+// with the configured action timeout
+type CreateOptions struct {
+	Method   string
+	Tools    []string
+	Timeouts config.Timeouts
+	Fetch    func(ctx context.Context, dir, command string) error
+}
+
+// Create downloads the files of each tool into the bundle directory dir and writes its
+// manifest; tools that cannot be bundled or fail to download are reported and left out
+func Create(ctx context.Context, dir string, opts CreateOptions) (*Manifest, []Result, error) {
+	if opts.Fetch == nil {
+		opts.Fetch = func(ctx context.Context, dir, command string) error {
+			return run(ctx, "cd "+commands.ShellQuote(dir)+" && "+command, opts.Timeouts.Action(opts.Method, ""))
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+
+	manifest := &Manifest{
+		Version:   ManifestVersion,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	var results []Result
+	for _, tool := range opts.Tools {
+		result := Result{Tool: tool, Method: opts.Method}
+		r, err := recipeFor(opts.Method, tool)
+		if err != nil {
+			result.Status, result.Detail = StatusSkipped, err.Error()
+			results = append(results, result)
+			continue
+		}
+		entry, err := fetchTool(ctx, dir, opts, tool, r)
+		if err != nil {
+			result.Status, result.Detail = StatusFailed, err.Error()
+		} else {
+			result.Status = StatusBundled
+			manifest.Tools = append(manifest.Tools, entry)
+		}
+		results = append(results, result)
+	}
+
+	if err := saveManifest(dir, manifest); err != nil {
+		return nil, results, err
+	}
+	return manifest, results, nil
+}
+
+// fetchTool downloads one tool's files into dir/files/<tool> and describes them
+// A failed download leaves nothing behind
+func fetchTool(ctx context.Context, dir string, opts CreateOptions, tool string, r recipe) (Entry, error) {
+	toolDir := filepath.Join(dir, filesDir, tool)
+	if err := os.RemoveAll(toolDir); err != nil {
+		return Entry{}, err
+	}
+	if err := os.MkdirAll(toolDir, 0755); err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{Tool: tool, Method: opts.Method}
+	err := opts.Fetch(ctx, toolDir, r.fetch)
+	if err == nil {
+		entry.Files, err = describeFiles(dir, toolDir)
+	}
+	if err == nil && len(entry.Files) == 0 {
+		err = fmt.Errorf("nothing was downloaded")
+	}
+	if err != nil {
+		os.RemoveAll(toolDir)
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// describeFiles lists the files in toolDir with paths relative to the bundle root, sorted
+func describeFiles(root, toolDir string) ([]File, error) {
+	entries, err := os.ReadDir(toolDir)
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		path := filepath.Join(toolDir, e.Name())
+		sum, size, err := hashFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(root, path)
+		files = append(files, File{Path: filepath.ToSlash(rel), SHA256: sum, Size: size})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// run executes a shell command, returning its last output line as the error on failure
+func run(ctx context.Context, command string, timeout time.Duration) error {
+	result := executor.ExecuteWithTimeout(ctx, command, timeout)
+	if result.IsSuccess() {
+		return nil
+	}
+	if output := strings.TrimSpace(result.Output); output != "" {
+		lines := strings.Split(output, "\n")
+		return fmt.Errorf("%s", strings.TrimSpace(lines[len(lines)-1]))
+	}
+	return fmt.Errorf("%s", result.GetErrorMessage())
+}
@@ -0,0 +1,153 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/cache"
+	"github.com/youpele52/lazysetup/pkg/commands"
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/privilege"
+)
+
+// InstallOptions controls installing from a bundle
+// Tools limits the install to some of the bundled tools, all of them when empty
+// Install runs an install command; it defaults to the shell with the configured timeouts,
+// escalated as Escalation says, which may ask for the password on the terminal
+// Preflight runs once per method, before its first tool. Bundled packages need no index and
+// the host may be offline, so none runs unless RunPreflight asks the default install to run
+// the method's pre-flight command from Flights. A failure is passed to PreflightFailed and the
+// install goes on
+type InstallOptions struct {
+	Tools      []string
+	Timeouts   config.Timeouts
+	Escalation privilege.Escalation
+	Flights    config.Flights
+	Install    func(ctx context.Context, method, tool, command string) error
+
+	RunPreflight    bool
+	Preflight       func(ctx context.Context, method string) error
+	PreflightFailed func(method string, err error)
+}
+
+// Install installs the bundled tools from the bundle directory dir without the network
+// Each tool's files are checked against the manifest before its install runs
+func Install(ctx context.Context, dir string, opts InstallOptions) ([]Result, error) {
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.OS != runtime.GOOS || manifest.Arch != runtime.GOARCH {
+		return nil, fmt.Errorf("bundle was created for %s/%s, this machine is %s/%s",
+			manifest.OS, manifest.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+	if opts.Install == nil {
+		opts.Install = func(ctx context.Context, method, tool, command string) error {
+			return runEscalated(ctx, method, command, opts.Timeouts.Action(method, tool), opts.Escalation)
+		}
+		if opts.Preflight == nil && opts.RunPreflight {
+			opts.Preflight = func(ctx context.Context, method string) error {
+				cmd := opts.Flights.PreflightCommand(method, constants.ToolActionInstall)
+				if cmd == "" {
+					return nil
+				}
+				return runEscalated(ctx, method, cmd, opts.Timeouts.Action(method, ""), opts.Escalation)
+			}
+		}
+	}
+
+	entries, err := selectEntries(manifest, opts.Tools)
+	if err != nil {
+		return nil, err
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	preflighted := make(map[string]bool)
+	for _, entry := range entries {
+		if !preflighted[entry.Method] && opts.Preflight != nil {
+			preflighted[entry.Method] = true
+			if err := opts.Preflight(ctx, entry.Method); err != nil && opts.PreflightFailed != nil {
+				opts.PreflightFailed(entry.Method, err)
+			}
+		}
+		result := Result{Tool: entry.Tool, Method: entry.Method, Status: StatusInstalled}
+		if err := installEntry(ctx, abs, entry, opts); err != nil {
+			result.Status, result.Detail = StatusFailed, err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// runEscalated runs a method's command escalated without a password, after letting the
+// escalation tool ask for one on the terminal
+func runEscalated(ctx context.Context, method, command string, timeout time.Duration, escalation privilege.Escalation) error {
+	if err := escalation.Authenticate(method, command); err != nil {
+		return err
+	}
+	command, _ = escalation.Wrap(method, command, "")
+	return run(ctx, command, timeout)
+}
+
+// selectEntries returns the manifest entries of tools, or every entry when tools is empty
+func selectEntries(manifest *Manifest, tools []string) ([]Entry, error) {
+	if len(tools) == 0 {
+		return manifest.Tools, nil
+	}
+	var entries []Entry
+	for _, tool := range tools {
+		found := false
+		for _, entry := range manifest.Tools {
+			if entry.Tool == tool {
+				entries = append(entries, entry)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s is not in the bundle", tool)
+		}
+	}
+	return entries, nil
+}
+
+// installEntry verifies one tool's files and runs its offline install
+func installEntry(ctx context.Context, dir string, entry Entry, opts InstallOptions) error {
+	r, err := recipeFor(entry.Method, entry.Tool)
+	if err != nil {
+		return err
+	}
+	if len(entry.Files) == 0 {
+		return fmt.Errorf("no files bundled for %s", entry.Tool)
+	}
+	if err := verify(dir, entry); err != nil {
+		return err
+	}
+	paths := make([]string, len(entry.Files))
+	for i, file := range entry.Files {
+		paths[i] = filepath.Join(dir, filepath.FromSlash(file.Path))
+	}
+	if entry.Method != "Curl" {
+		return opts.Install(ctx, entry.Method, entry.Tool, r.install(paths))
+	}
+
+	// Like online installs, a Curl recipe's /tmp files go to a work directory of this run;
+	// the bundled files are named from the bundle directory, which may itself be under /tmp
+	for i, file := range entry.Files {
+		paths[i] = filepath.FromSlash(file.Path)
+	}
+	prepared, err := (*cache.Cache)(nil).Prepare(ctx, r.install(paths))
+	if err != nil {
+		return err
+	}
+	defer prepared.Cleanup()
+	return opts.Install(ctx, entry.Method, entry.Tool, fmt.Sprintf("cd %s && %s", commands.ShellQuote(dir), prepared.Command))
+}
@@ -0,0 +1,75 @@
+package bundle
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/youpele52/lazysetup/pkg/commands"
+)
+
+// Methods lists the methods whose installs can be bundled
+var Methods = []string{"Curl", "APT", "Homebrew"}
+
+// recipe is how one tool is downloaded into a bundle and installed from it
+type recipe struct {
+	fetch   string                      // Run in the tool's bundle directory to download its files
+	install func(files []string) string // Installs from the downloaded files, given by path, without the network
+}
+
+// recipeFor returns how to bundle tool with method, or why it cannot be bundled
+func recipeFor(method, tool string) (recipe, error) {
+	switch method {
+	case "Curl":
+		return curlRecipe(tool)
+	case "APT":
+		// --reinstall downloads the package even where it is installed; dependencies
+		// installed here are not downloaded and must be installed on the offline host too
+		pkg := commands.ShellQuote(commands.GetPackageName(tool, method))
+		return recipe{
+			fetch: "mkdir -p partial && apt-get install -y --download-only --reinstall -o Dir::Cache::archives=\"$PWD\" " + pkg +
+				" && rm -rf partial lock",
+			install: func(files []string) string {
+				return "apt-get install -y --no-download " + quoteAll(files)
+			},
+		}, nil
+	case "Homebrew":
+		pkg := commands.ShellQuote(commands.GetPackageName(tool, method))
+		return recipe{
+			fetch: fmt.Sprintf("brew fetch --deps %s && for f in $(brew deps %s) %s; do cp \"$(brew --cache \"$f\")\" .; done", pkg, pkg, pkg),
+			install: func(files []string) string {
+				return "HOMEBREW_NO_AUTO_UPDATE=1 brew install " + quoteAll(files)
+			},
+		}, nil
+	}
+	return recipe{}, fmt.Errorf("%s installs cannot be bundled (use one of %v)", method, Methods)
+}
+
+// curlRecipe bundles the file a Curl install downloads and replays the rest of the
+// install on a copy of it; Install moves the copy into a work directory of its own
+// Installer scripts and piped installs are refused, they download more at install time
+func curlRecipe(tool string) (recipe, error) {
+	cmd := commands.GetInstallCommand("Curl", tool)
+	if cmd == "" {
+		return recipe{}, fmt.Errorf("no Curl install for %s", tool)
+	}
+	d, ok := commands.ParseCurlDownload(cmd)
+	if !ok || strings.HasSuffix(d.Path, ".sh") || strings.Contains(d.Rest, "curl ") || strings.Contains(d.Rest, "wget ") {
+		return recipe{}, fmt.Errorf("the Curl install of %s downloads more files while installing", tool)
+	}
+	return recipe{
+		fetch: fmt.Sprintf("curl -fsSL %s -o %s", d.URL, commands.ShellQuote(path.Base(d.Path))),
+		install: func(files []string) string {
+			return fmt.Sprintf("cp %s %s && %s", commands.ShellQuote(files[0]), commands.ShellQuote(d.Path), d.Rest)
+		},
+	}, nil
+}
+
+// quoteAll quotes each path and joins them with spaces
+func quoteAll(paths []string) string {
+	quoted := make([]string, len(paths))
+	for i, path := range paths {
+		quoted[i] = commands.ShellQuote(path)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,311 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxAge is how long a download from a URL that can change, such as a
+// releases/latest link, is reused before it is fetched again
+const DefaultMaxAge = 24 * time.Hour
+
+const (
+	blobsDir = "blobs" // Downloaded files, named by their SHA-256
+	indexDir = "index" // One entry per URL, named by the SHA-256 of the URL
+	tmpDir   = "tmp"   // Downloads in progress
+)
+
+// versionedPattern matches a version number in a URL, which then names a fixed release
+var versionedPattern = regexp.MustCompile(`\d+\.\d+`)
+
+// mutableMarkers are URL parts naming something that changes between downloads
+var mutableMarkers = []string{"latest", "/master/", "/main/", "stable", "nightly"}
+
+// Entry records what a URL downloaded to and when
+type Entry struct {
+	URL       string    `json:"url"`
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	FetchedAt time.Time `json:"fetched_at"`
+	UsedAt    time.Time `json:"used_at"`
+}
+
+// Cache is a content-addressed store of downloads shared by every run
+// A cached file is only reused while it still matches its recorded SHA-256; files from
+// versioned URLs are reused until cleaned, others for at most MaxAge
+// Safe for concurrent use; processes sharing a cache never see partial files
+type Cache struct {
+	mu     sync.Mutex
+	dir    string
+	maxAge time.Duration
+	client *http.Client
+	now    func() time.Time
+}
+
+// Dir returns the download cache, $XDG_CACHE_HOME/lazysetup/downloads
+// (~/.cache when XDG_CACHE_HOME is unset)
+func Dir() (string, error) {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate cache directory: %w", err)
+		}
+		dir = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(dir, "lazysetup", "downloads"), nil
+}
+
+// New returns the cache stored in dir; maxAge limits reuse of downloads from URLs that can change
+func New(dir string, maxAge time.Duration) *Cache {
+	return &Cache{dir: dir, maxAge: maxAge, client: http.DefaultClient, now: time.Now}
+}
+
+// Path returns the directory the cache is stored in
+func (c *Cache) Path() string {
+	return c.dir
+}
+
+// Fetch copies the file at url to dest, from the cache when a reusable copy is there and
+// otherwise by downloading it into the cache first
+// hit reports whether the cached copy was used
+func (c *Cache) Fetch(ctx context.Context, url, dest string) (hit bool, err error) {
+	if entry, ok := c.lookup(url); ok && c.reusable(entry) {
+		if err := copyVerified(c.blobPath(entry.SHA256), dest, entry.SHA256); err == nil {
+			entry.UsedAt = c.now()
+			c.saveEntry(entry)
+			return true, nil
+		}
+		// The cached file is gone or corrupt, forget it and download again
+		os.Remove(c.blobPath(entry.SHA256))
+		os.Remove(c.entryPath(url))
+	}
+
+	entry, err := c.download(ctx, url)
+	if err != nil {
+		return false, err
+	}
+	if err := copyVerified(c.blobPath(entry.SHA256), dest, entry.SHA256); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// download stores the file at url in the cache and records it
+func (c *Cache) download(ctx context.Context, url string) (Entry, error) {
+	if err := os.MkdirAll(filepath.Join(c.dir, tmpDir), 0755); err != nil {
+		return Entry{}, fmt.Errorf("failed to create download cache: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return Entry{}, err
+	}
+	req.Header.Set("User-Agent", "lazysetup")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Entry{}, fmt.Errorf("failed to download %s: status %d", url, resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(c.dir, tmpDir), "download-")
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to create download cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if err := os.MkdirAll(filepath.Join(c.dir, blobsDir), 0755); err != nil {
+		return Entry{}, fmt.Errorf("failed to create download cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.blobPath(sum)); err != nil {
+		return Entry{}, fmt.Errorf("failed to store download: %w", err)
+	}
+
+	now := c.now()
+	entry := Entry{URL: url, SHA256: sum, Size: size, FetchedAt: now, UsedAt: now}
+	c.saveEntry(entry)
+	return entry, nil
+}
+
+// reusable applies the reuse policy: versioned URLs always, others while younger than maxAge
+func (c *Cache) reusable(entry Entry) bool {
+	return !Mutable(entry.URL) || c.now().Sub(entry.FetchedAt) < c.maxAge
+}
+
+// Mutable reports whether url may download different files over time: it names no
+// version, or a moving target such as latest or a branch
+func Mutable(url string) bool {
+	lower := strings.ToLower(url)
+	for _, marker := range mutableMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return !versionedPattern.MatchString(url)
+}
+
+// List returns every cached download, sorted by URL
+func (c *Cache) List() ([]Entry, error) {
+	files, err := os.ReadDir(filepath.Join(c.dir, indexDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download cache: %w", err)
+	}
+	var entries []Entry
+	for _, file := range files {
+		entry, err := readEntry(filepath.Join(c.dir, indexDir, file.Name()))
+		if err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].URL < entries[j].URL })
+	return entries, nil
+}
+
+// Clean removes the downloads not used within olderThan, or all of them when olderThan
+// is zero, along with files no entry refers to
+// Returns how many downloads were removed and how many bytes were freed
+func (c *Cache) Clean(olderThan time.Duration) (removed int, freed int64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.List()
+	if err != nil {
+		return 0, 0, err
+	}
+	keep := make(map[string]bool)
+	for _, entry := range entries {
+		if olderThan > 0 && c.now().Sub(entry.UsedAt) < olderThan {
+			keep[entry.SHA256] = true
+			continue
+		}
+		if err := os.Remove(c.entryPath(entry.URL)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, freed, fmt.Errorf("failed to clean download cache: %w", err)
+		}
+		removed++
+	}
+
+	blobs, err := os.ReadDir(filepath.Join(c.dir, blobsDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return removed, freed, fmt.Errorf("failed to clean download cache: %w", err)
+	}
+	for _, blob := range blobs {
+		if keep[blob.Name()] {
+			continue
+		}
+		if info, err := blob.Info(); err == nil {
+			freed += info.Size()
+		}
+		os.Remove(filepath.Join(c.dir, blobsDir, blob.Name()))
+	}
+	os.RemoveAll(filepath.Join(c.dir, tmpDir))
+	return removed, freed, nil
+}
+
+// lookup returns the entry recorded for url
+func (c *Cache) lookup(url string) (Entry, bool) {
+	entry, err := readEntry(c.entryPath(url))
+	return entry, err == nil && entry.URL == url
+}
+
+// saveEntry records entry, replacing the file atomically
+// A failure only means the download is fetched again next time
+func (c *Cache) saveEntry(entry Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return
+	}
+	path := c.entryPath(entry.URL)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "entry-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// readEntry loads one index entry
+func readEntry(path string) (Entry, error) {
+	var entry Entry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	err = json.Unmarshal(data, &entry)
+	return entry, err
+}
+
+func (c *Cache) blobPath(sum string) string {
+	return filepath.Join(c.dir, blobsDir, sum)
+}
+
+func (c *Cache) entryPath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, indexDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// copyVerified copies src to dest and fails, removing dest, unless the copy has the
+// SHA-256 sum
+func copyVerified(src, dest, sum string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, hash), in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && hex.EncodeToString(hash.Sum(nil)) != sum {
+		err = fmt.Errorf("cached download %s does not match its checksum", filepath.Base(src))
+	}
+	if err != nil {
+		os.Remove(dest)
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fileServer serves body at every path and counts the requests it receives
+func fileServer(t *testing.T, body string) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+// TestFetch tests downloading through the cache and the reuse policy.
+// Priority: P1 - A stale or tampered download must never be installed from the cache.
+// Tests hits, misses, corrupt blobs, versioned and mutable URLs, and failed downloads.
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("second fetch is served from the cache", func(t *testing.T) {
+		server, hits := fileServer(t, "tarball")
+		c := New(t.TempDir(), DefaultMaxAge)
+		url := server.URL + "/v1.2.0/tool.tar.gz"
+		dest := filepath.Join(t.TempDir(), "tool.tar.gz")
+
+		if hit, err := c.Fetch(ctx, url, dest); err != nil || hit {
+			t.Fatalf("Expected a miss, got hit=%v err=%v", hit, err)
+		}
+		if hit, err := c.Fetch(ctx, url, dest); err != nil || !hit {
+			t.Fatalf("Expected a hit, got hit=%v err=%v", hit, err)
+		}
+		if *hits != 1 {
+			t.Errorf("Expected 1 request, got %d", *hits)
+		}
+		if data, _ := os.ReadFile(dest); string(data) != "tarball" {
+			t.Errorf("Expected downloaded content, got %q", data)
+		}
+	})
+
+	t.Run("tampered blob is downloaded again", func(t *testing.T) {
+		server, hits := fileServer(t, "tarball")
+		c := New(t.TempDir(), DefaultMaxAge)
+		url := server.URL + "/v1.2.0/tool.tar.gz"
+		dest := filepath.Join(t.TempDir(), "tool.tar.gz")
+		if _, err := c.Fetch(ctx, url, dest); err != nil {
+			t.Fatal(err)
+		}
+		entries, _ := c.List()
+		if err := os.WriteFile(c.blobPath(entries[0].SHA256), []byte("evil"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if hit, err := c.Fetch(ctx, url, dest); err != nil || hit {
+			t.Fatalf("Expected a miss, got hit=%v err=%v", hit, err)
+		}
+		if *hits != 2 {
+			t.Errorf("Expected 2 requests, got %d", *hits)
+		}
+		if data, _ := os.ReadFile(dest); string(data) != "tarball" {
+			t.Errorf("Expected the genuine content, got %q", data)
+		}
+	})
+
+	t.Run("mutable URLs expire and versioned ones do not", func(t *testing.T) {
+		server, hits := fileServer(t, "tarball")
+		c := New(t.TempDir(), time.Hour)
+		start := time.Now()
+		c.now = func() time.Time { return start }
+		dest := filepath.Join(t.TempDir(), "tool.tar.gz")
+		latest := server.URL + "/releases/latest/download/tool.tar.gz"
+		versioned := server.URL + "/releases/download/v1.2.0/tool.tar.gz"
+		for _, url := range []string{latest, versioned} {
+			if _, err := c.Fetch(ctx, url, dest); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		c.now = func() time.Time { return start.Add(2 * time.Hour) }
+		if hit, _ := c.Fetch(ctx, versioned, dest); !hit {
+			t.Error("Expected the versioned download to be reused")
+		}
+		if hit, _ := c.Fetch(ctx, latest, dest); hit {
+			t.Error("Expected the latest download to be fetched again")
+		}
+		if *hits != 3 {
+			t.Errorf("Expected 3 requests, got %d", *hits)
+		}
+	})
+
+	t.Run("failed download is not cached", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		c := New(t.TempDir(), DefaultMaxAge)
+		if _, err := c.Fetch(ctx, server.URL+"/v1.0/missing", filepath.Join(t.TempDir(), "x")); err == nil {
+			t.Error("Expected error for a 404")
+		}
+		if entries, _ := c.List(); len(entries) != 0 {
+			t.Errorf("Expected no entries, got %v", entries)
+		}
+	})
+}
+
+// TestMutable tests which URLs may download different files over time.
+// Priority: P2 - Decides whether a download is reused indefinitely or expires.
+// Tests latest and branch URLs, versioned URLs and URLs without a version.
+func TestMutable(t *testing.T) {
+	t.Run("classifies URLs", func(t *testing.T) {
+		for url, want := range map[string]bool{
+			"https://github.com/sharkdp/fd/releases/latest/download/fd.tar.gz":         true,
+			"https://raw.githubusercontent.com/junegunn/fzf/master/install":            true,
+			"https://git-scm.com/download/linux":                                       true,
+			"https://ftp.gnu.org/gnu/make/make-4.4.1.tar.gz":                           false,
+			"https://www.python.org/ftp/python/3.13.1/Python-3.13.1.tgz":               false,
+			"https://github.com/cli/cli/releases/download/v2.40.0/gh_2.40.0_linux.tgz": false,
+		} {
+			if got := Mutable(url); got != want {
+				t.Errorf("Mutable(%s) = %v, want %v", url, got, want)
+			}
+		}
+	})
+}
+
+// TestClean tests removing cached downloads.
+// Priority: P2 - lazysetup cache clean must free space without breaking recent entries.
+// Tests removing everything and removing only downloads unused for a while.
+func TestClean(t *testing.T) {
+	ctx := context.Background()
+	server, _ := fileServer(t, "tarball")
+
+	t.Run("removes everything", func(t *testing.T) {
+		c := New(t.TempDir(), DefaultMaxAge)
+		c.Fetch(ctx, server.URL+"/v1.0/a", filepath.Join(t.TempDir(), "a"))
+		removed, freed, err := c.Clean(0)
+		if err != nil || removed != 1 || freed != int64(len("tarball")) {
+			t.Errorf("Expected 1 removed and 7 bytes freed, got %d, %d and %v", removed, freed, err)
+		}
+		if entries, _ := c.List(); len(entries) != 0 {
+			t.Errorf("Expected empty cache, got %v", entries)
+		}
+	})
+
+	t.Run("keeps recently used downloads", func(t *testing.T) {
+		c := New(t.TempDir(), DefaultMaxAge)
+		start := time.Now()
+		c.now = func() time.Time { return start }
+		c.Fetch(ctx, server.URL+"/v1.0/old", filepath.Join(t.TempDir(), "old"))
+		c.now = func() time.Time { return start.Add(48 * time.Hour) }
+		c.Fetch(ctx, server.URL+"/v2.0/new", filepath.Join(t.TempDir(), "new"))
+
+		removed, freed, err := c.Clean(24 * time.Hour)
+		if err != nil || removed != 1 {
+			t.Fatalf("Expected 1 removed, got %d and %v", removed, err)
+		}
+		// Both URLs share one blob, which the recent entry still needs
+		if freed != 0 {
+			t.Errorf("Expected the shared blob to be kept, freed %d bytes", freed)
+		}
+		if hit, _ := c.Fetch(ctx, server.URL+"/v2.0/new", filepath.Join(t.TempDir(), "new")); !hit {
+			t.Error("Expected the recent download to stay cached")
+		}
+	})
+}
+
+// TestPrepare tests rewriting Curl recipes to run from the cache in their own work directory.
+// Priority: P1 - Parallel runs must not clobber each other's files in /tmp.
+// Tests cached downloads, piped installs and a disabled cache.
+func TestPrepare(t *testing.T) {
+	ctx := context.Background()
+	server, hits := fileServer(t, "tarball")
+
+	t.Run("fetches the download and isolates the recipe", func(t *testing.T) {
+		c := New(t.TempDir(), DefaultMaxAge)
+		recipe := "curl -fsSL " + server.URL + "/v1.0/tool.tar.gz -o /tmp/tool.tar.gz && cd /tmp && cat tool.tar.gz"
+		for i := 0; i < 2; i++ {
+			prepared, err := c.Prepare(ctx, recipe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(prepared.Command, "/tmp ") || strings.Contains(prepared.Command, "curl") {
+				t.Errorf("Expected no /tmp or curl in %q", prepared.Command)
+			}
+			if i == 1 && !strings.HasPrefix(prepared.Notice, "Using cached download") {
+				t.Errorf("Expected a cache hit notice, got %q", prepared.Notice)
+			}
+			prepared.Cleanup()
+		}
+		if *hits != 1 {
+			t.Errorf("Expected 1 request, got %d", *hits)
+		}
+	})
+
+	t.Run("piped installs only change /tmp", func(t *testing.T) {
+		c := New(t.TempDir(), DefaultMaxAge)
+		prepared, err := c.Prepare(ctx, "curl -fsSL https://starship.rs/install.sh | sh")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer prepared.Cleanup()
+		if prepared.Command != "curl -fsSL https://starship.rs/install.sh | sh" {
+			t.Errorf("Expected the recipe unchanged, got %q", prepared.Command)
+		}
+	})
+
+	t.Run("disabled cache still isolates the recipe", func(t *testing.T) {
+		var c *Cache
+		prepared, err := c.Prepare(ctx, "curl -fsSL https://example.com/jq -o /tmp/jq && chmod +x /tmp/jq")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer prepared.Cleanup()
+		if strings.Contains(prepared.Command, "/tmp/jq") || !strings.HasPrefix(prepared.Command, "curl -fsSL https://example.com/jq -o ") {
+			t.Errorf("Expected /tmp replaced by the work directory, got %q", prepared.Command)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/commands"
+	"github.com/youpele52/lazysetup/pkg/executor"
+)
+
+// resolveTimeout bounds expanding a recipe's URL, such as $(uname -m), in the shell
+const resolveTimeout = 30 * time.Second
+
+// tmpPattern matches /tmp as a whole path component in a recipe
+var tmpPattern = regexp.MustCompile(`/tmp\b`)
+
+// Prepared is a Curl recipe rewritten to run in its own work directory
+type Prepared struct {
+	Command string // Recipe to run instead of the original
+	Notice  string // Line describing where the download came from, empty when not cached
+	Cleanup func() // Removes the work directory; call once the command has finished
+}
+
+// Prepare rewrites a Curl recipe so concurrent runs never share files: everything the
+// recipe puts in /tmp goes to a fresh work directory instead
+// When the recipe downloads one file and c is not nil, the download is fetched through
+// the cache up front and the recipe only installs it. Piped installs run unchanged
+func (c *Cache) Prepare(ctx context.Context, cmd string) (Prepared, error) {
+	workdir, err := os.MkdirTemp("", "lazysetup-run-")
+	if err != nil {
+		return Prepared{}, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(workdir) }
+	isolate := func(s string) string { return tmpPattern.ReplaceAllLiteralString(s, workdir) }
+
+	download, ok := commands.ParseCurlDownload(cmd)
+	if !ok || c == nil {
+		return Prepared{Command: isolate(cmd), Cleanup: cleanup}, nil
+	}
+
+	url, err := resolveURL(ctx, download.URL)
+	if err != nil {
+		cleanup()
+		return Prepared{}, err
+	}
+	dest := filepath.Join(workdir, path.Base(download.Path))
+	hit, err := c.Fetch(ctx, url, dest)
+	if err != nil {
+		cleanup()
+		return Prepared{}, err
+	}
+
+	prepared := Prepared{
+		// test keeps a command first in the chain, so a sudo prefix applies to it alone
+		Command: fmt.Sprintf("test -s '%s' && %s", dest, isolate(download.Rest)),
+		Notice:  "Downloaded " + url + " into the cache",
+		Cleanup: cleanup,
+	}
+	if hit {
+		prepared.Notice = "Using cached download of " + url
+	}
+	return prepared, nil
+}
+
+// resolveURL expands a recipe's URL word the way the shell would when running the recipe
+func resolveURL(ctx context.Context, word string) (string, error) {
+	result := executor.ExecuteWithTimeout(ctx, "printf '%s' "+word, resolveTimeout)
+	url := strings.TrimSpace(result.Output)
+	if result.ExitCode != 0 || !strings.HasPrefix(url, "http") {
+		return "", fmt.Errorf("failed to resolve download URL %s", word)
+	}
+	return url, nil
+}
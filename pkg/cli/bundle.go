@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/youpele52/lazysetup/pkg/bundle"
+	"github.com/youpele52/lazysetup/pkg/snapshot"
+	"github.com/youpele52/lazysetup/pkg/tools"
+)
+
+const bundleUsage = "Usage: lazysetup bundle create [-method name] [-o path] [-lockfile file] [tool...] | install [-preflight] path [tool...]"
+
+// runBundle creates an offline bundle or installs from one
+func runBundle(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, bundleUsage)
+		return 2
+	}
+	switch args[0] {
+	case "create":
+		return runBundleCreate(args[1:], stdout, stderr)
+	case "install":
+		return runBundleInstall(args[1:], stdout, stderr)
+	}
+	fmt.Fprintln(stderr, bundleUsage)
+	return 2
+}
+
+// runBundleCreate downloads the selected tools into a bundle directory, or a tarball when
+// -o ends in .tar.gz or .tgz
+// Tools come from the arguments, or from the installed tools of a lockfile
+func runBundleCreate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bundle create", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	method := fs.String("method", "Curl", fmt.Sprintf("method to bundle installs for (%v)", bundle.Methods))
+	output := fs.String("o", bundle.DefaultPath, "bundle directory, or a .tar.gz to write")
+	lockfile := fs.String("lockfile", "", "bundle the tools this lockfile records as installed")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	selected := fs.Args()
+	if *lockfile != "" {
+		lock, err := snapshot.Load(*lockfile)
+		if err != nil {
+			fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+			return 1
+		}
+		for _, tool := range lock.InstalledTools() {
+			selected = append(selected, tool.Name)
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Fprintln(stderr, "lazysetup: name the tools to bundle or pass -lockfile")
+		return 2
+	}
+	if !slices.Contains(bundle.Methods, *method) {
+		fmt.Fprintf(stderr, "lazysetup: %s installs cannot be bundled (use one of %v)\n", *method, bundle.Methods)
+		return 2
+	}
+	for _, tool := range selected {
+		if !isKnownTool(tool) {
+			fmt.Fprintf(stderr, "lazysetup: unknown tool %q\n", tool)
+			return 2
+		}
+	}
+
+	cfg, err := userConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+
+	dir := *output
+	if bundle.IsArchive(*output) {
+		if dir, err = os.MkdirTemp("", "lazysetup-bundle-"); err != nil {
+			fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+			return 1
+		}
+		defer os.RemoveAll(dir)
+	}
+
+	manifest, results, err := bundle.Create(context.Background(), dir, bundle.CreateOptions{
+		Method:   *method,
+		Tools:    selected,
+		Timeouts: cfg.CommandTimeouts(),
+	})
+	printBundleResults(stdout, results)
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+	if dir != *output {
+		if err := bundle.Pack(dir, *output); err != nil {
+			fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+			return 1
+		}
+	}
+	fmt.Fprintf(stdout, "Wrote %d of %d tools to %s (%s/%s)\n", len(manifest.Tools), len(selected), *output, manifest.OS, manifest.Arch)
+	if len(manifest.Tools) < len(selected) {
+		return 1
+	}
+	return 0
+}
+
+// runBundleInstall installs the bundled tools, or the named ones, without the network
+// -preflight refreshes each method's package index first, for hosts that can reach a mirror
+func runBundleInstall(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bundle install", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	preflight := fs.Bool("preflight", false, "run each method's pre-flight command before its first install")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, bundleUsage)
+		return 2
+	}
+
+	cfg, err := userConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+
+	dir, cleanup, err := bundle.Open(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+	defer cleanup()
+
+	results, err := bundle.Install(context.Background(), dir, bundle.InstallOptions{
+		Tools:      args[1:],
+		Timeouts:   cfg.CommandTimeouts(),
+		Escalation: cfg.TerminalEscalation(),
+		Flights:    cfg.FlightCommands(),
+
+		RunPreflight: *preflight,
+		PreflightFailed: func(method string, err error) {
+			fmt.Fprintf(stdout, "  ! %s pre-flight failed: %v\n", method, err)
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+	printBundleResults(stdout, results)
+	for _, result := range results {
+		if result.Status == bundle.StatusFailed {
+			return 1
+		}
+	}
+	return 0
+}
+
+// printBundleResults lists what happened to each tool
+func printBundleResults(w io.Writer, results []bundle.Result) {
+	for _, result := range results {
+		switch result.Status {
+		case bundle.StatusBundled, bundle.StatusInstalled:
+			fmt.Fprintf(w, "  ✓ %s via %s (%s)\n", result.Tool, result.Method, result.Status)
+		case bundle.StatusSkipped:
+			fmt.Fprintf(w, "  - %s skipped: %s\n", result.Tool, result.Detail)
+		default:
+			fmt.Fprintf(w, "  ✗ %s: %s\n", result.Tool, result.Detail)
+		}
+	}
+}
+
+// isKnownTool reports whether tool is in the tool registry
+func isKnownTool(tool string) bool {
+	return slices.Contains(tools.Tools, tool)
+}
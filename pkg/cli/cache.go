@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/cache"
+)
+
+const cacheUsage = "Usage: lazysetup cache list | clean [-older-than duration]"
+
+// runCache lists or removes the cached Curl recipe downloads
+func runCache(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, cacheUsage)
+		return 2
+	}
+	switch args[0] {
+	case "list":
+		return runCacheList(args[1:], stdout, stderr)
+	case "clean":
+		return runCacheClean(args[1:], stdout, stderr)
+	}
+	fmt.Fprintln(stderr, cacheUsage)
+	return 2
+}
+
+// runCacheList prints every cached download with its size, age and checksum
+func runCacheList(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprintln(stderr, cacheUsage)
+		return 2
+	}
+	downloads, err := openCache()
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+	entries, err := downloads.List()
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(stdout, "No cached downloads in %s\n", downloads.Path())
+		return 0
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+		reuse := "versioned"
+		if cache.Mutable(entry.URL) {
+			reuse = "expires"
+		}
+		fmt.Fprintf(stdout, "%s  %9s  %8s  %-9s  %s\n", entry.SHA256[:12], formatBytes(entry.Size), formatAge(time.Since(entry.FetchedAt)), reuse, entry.URL)
+	}
+	fmt.Fprintf(stdout, "%d downloads, %s in %s\n", len(entries), formatBytes(total), downloads.Path())
+	return 0
+}
+
+// runCacheClean removes cached downloads, all of them or those unused for -older-than
+func runCacheClean(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("cache clean", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	olderThan := fs.Duration("older-than", 0, "only remove downloads not used for this long, such as 720h")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || *olderThan < 0 {
+		fmt.Fprintln(stderr, cacheUsage)
+		return 2
+	}
+
+	downloads, err := openCache()
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+	removed, freed, err := downloads.Clean(*olderThan)
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Removed %d downloads, freed %s\n", removed, formatBytes(freed))
+	return 0
+}
+
+// openCache opens the download cache, whether or not the config file enables it
+func openCache() (*cache.Cache, error) {
+	dir, err := cache.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(dir, cache.DefaultMaxAge), nil
+}
+
+// formatBytes returns a size like 1.5 MB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatAge returns a duration rounded to its largest unit, like 3d or 5m
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	return "now"
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/youpele52/lazysetup/pkg/version"
+)
+
+// Command is a lazysetup subcommand run from the shell instead of the TUI
+type Command struct {
+	Name    string
+	Usage   string
+	Summary string
+	Run     func(args []string, stdout, stderr io.Writer) int
+}
+
+// commandList returns all subcommands in the order they are listed in help output
+func commandList() []Command {
+	return []Command{
+		{Name: "export", Usage: "export [-o lockfile]", Summary: "Record the package manager and installed tool versions", Run: runExport},
+		{Name: "restore", Usage: "restore [-method name] [-exact] lockfile", Summary: "Install missing tools from a lockfile and report version drift", Run: runRestore},
+		{Name: "bundle", Usage: "bundle create|install ...", Summary: "Download tools into an offline bundle, or install from one", Run: runBundle},
+		{Name: "cache", Usage: "cache list | clean [-older-than d]", Summary: "List or remove cached release downloads", Run: runCache},
+		{Name: "config", Usage: "config path | get key | set key value", Summary: "Show or change settings in the config file", Run: runConfig},
+		{Name: "self", Usage: "self rollback", Summary: "Restore the lazysetup binary the last update replaced", Run: runSelf},
+		{Name: "version", Usage: "version", Summary: "Print the lazysetup version", Run: runVersion},
+	}
+}
+
+// Run dispatches a subcommand and returns the process exit code
+// args excludes the program name (os.Args[1:])
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		printUsage(stdout)
+		return 0
+	case "-v", "--version":
+		return runVersion(nil, stdout, stderr)
+	}
+
+	for _, cmd := range commandList() {
+		if cmd.Name == args[0] {
+			return cmd.Run(args[1:], stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "lazysetup: unknown command %q\n\n", args[0])
+	printUsage(stderr)
+	return 2
+}
+
+// printUsage lists the available subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: lazysetup [command]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run without a command to start the interactive UI.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commandList() {
+		fmt.Fprintf(w, "  %-34s %s\n", cmd.Usage, cmd.Summary)
+	}
+}
+
+// runVersion prints the current version
+func runVersion(args []string, stdout, stderr io.Writer) int {
+	fmt.Fprintln(stdout, version.Version)
+	return 0
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/youpele52/lazysetup/pkg/version"
+)
+
+// TestRun_Dispatch tests subcommand dispatch and exit codes.
+// Priority: P2 - Scripts rely on stable exit codes and `--version` output.
+// Tests version flags, help, and unknown commands.
+func TestRun_Dispatch(t *testing.T) {
+	t.Run("--version prints version", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if code := Run([]string{"--version"}, &stdout, &stderr); code != 0 {
+			t.Errorf("Expected exit code 0, got %d", code)
+		}
+		if strings.TrimSpace(stdout.String()) != version.Version {
+			t.Errorf("Expected %q, got %q", version.Version, stdout.String())
+		}
+	})
+
+	t.Run("help lists commands", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if code := Run([]string{"help"}, &stdout, &stderr); code != 0 {
+			t.Errorf("Expected exit code 0, got %d", code)
+		}
+		for _, cmd := range commandList() {
+			if !strings.Contains(stdout.String(), cmd.Name) {
+				t.Errorf("Expected help to mention %q", cmd.Name)
+			}
+		}
+	})
+
+	t.Run("unknown command fails with usage", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if code := Run([]string{"frobnicate"}, &stdout, &stderr); code != 2 {
+			t.Errorf("Expected exit code 2, got %d", code)
+		}
+		if !strings.Contains(stderr.String(), "unknown command") {
+			t.Errorf("Expected unknown command message, got %q", stderr.String())
+		}
+	})
+
+	t.Run("bundle rejects bad arguments before downloading", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"bundle"},
+			{"bundle", "create"},
+			{"bundle", "create", "frobnicate"},
+			{"bundle", "create", "-method", "Scoop", "git"},
+			{"bundle", "install"},
+			{"bundle", "install", "-preflight"},
+			{"bundle", "install", "-frobnicate", "tools.tar.gz"},
+		} {
+			var stdout, stderr bytes.Buffer
+			if code := Run(args, &stdout, &stderr); code != 2 {
+				t.Errorf("Expected exit code 2 for %v, got %d", args, code)
+			}
+		}
+	})
+
+	t.Run("cache list and clean an empty cache", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", t.TempDir())
+		var stdout, stderr bytes.Buffer
+		if code := Run([]string{"cache", "list"}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "No cached downloads") {
+			t.Errorf("Expected empty listing, got %d: %s%s", code, stdout.String(), stderr.String())
+		}
+		stdout.Reset()
+		if code := Run([]string{"cache", "clean", "-older-than", "720h"}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "Removed 0 downloads") {
+			t.Errorf("Expected nothing removed, got %d: %s%s", code, stdout.String(), stderr.String())
+		}
+		if code := Run([]string{"cache", "purge"}, &stdout, &stderr); code != 2 {
+			t.Errorf("Expected exit code 2 for unknown subcommand, got %d", code)
+		}
+	})
+
+	t.Run("restore requires a lockfile", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if code := Run([]string{"restore"}, &stdout, &stderr); code != 2 {
+			t.Errorf("Expected exit code 2, got %d", code)
+		}
+	})
+
+	t.Run("config set and get round trip", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		var stdout, stderr bytes.Buffer
+		if code := Run([]string{"config", "set", "parallelism", "6"}, &stdout, &stderr); code != 0 {
+			t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+		}
+		if code := Run([]string{"config", "get", "parallelism"}, &stdout, &stderr); code != 0 {
+			t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+		}
+		if strings.TrimSpace(stdout.String()) != "6" {
+			t.Errorf("Expected 6, got %q", stdout.String())
+		}
+		if code := Run([]string{"config", "set", "parallelism", "0x"}, &stdout, &stderr); code != 1 {
+			t.Errorf("Expected exit code 1 for an invalid value, got %d", code)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+)
+
+const configUsage = "Usage: lazysetup config path | get key | set key value"
+
+// runConfig prints the config file location or reads or changes one of its settings
+// Keys are dotted paths such as parallelism or timeouts.action; set validates the whole
+// file before writing it
+func runConfig(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, configUsage)
+		return 2
+	}
+
+	path, err := config.ConfigPath()
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+
+	switch {
+	case args[0] == "path" && len(args) == 1:
+		fmt.Fprintln(stdout, path)
+		return 0
+	case args[0] == "get" && len(args) == 2:
+		return configGet(path, args[1], stdout, stderr)
+	case args[0] == "set" && len(args) == 3:
+		if err := config.SetValue(path, args[1], args[2]); err != nil {
+			fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintln(stderr, configUsage)
+	return 2
+}
+
+// configGet prints a setting from the config file, or its default when the file does not
+// set it. Exits 1 for keys that are neither set nor have a default
+func configGet(path, key string, stdout, stderr io.Writer) int {
+	value, ok, err := config.GetValue(path, key)
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+	if !ok {
+		value, ok = config.DefaultValue(key)
+	}
+	if !ok {
+		fmt.Fprintf(stderr, "lazysetup: %s is not set\n", key)
+		return 1
+	}
+	fmt.Fprintln(stdout, value)
+	return 0
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/updater"
+)
+
+const selfUsage = "Usage: lazysetup self rollback"
+
+// runSelf manages the lazysetup installation itself
+// `self rollback` restores the binary the last update replaced
+func runSelf(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 || args[0] != "rollback" {
+		fmt.Fprintln(stderr, selfUsage)
+		return 2
+	}
+
+	path, err := updater.RollbackUpdate()
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, constants.UpdateRolledBack+"\n", path)
+	return 0
+}
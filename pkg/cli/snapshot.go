@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/snapshot"
+)
+
+// runExport captures the local environment into a lockfile
+func runExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("o", snapshot.DefaultLockfile, "lockfile to write")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := userConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+
+	lock := snapshot.Capture(context.Background(), cfg.CommandTimeouts().Check())
+	if err := snapshot.Save(*output, lock); err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+
+	installed := lock.InstalledTools()
+	fmt.Fprintf(stdout, "Package manager: %s\n", lock.PackageManager)
+	for _, tool := range installed {
+		fmt.Fprintf(stdout, "  %s %s %s\n", constants.CheckboxSelected, tool.Name, tool.Version)
+	}
+	fmt.Fprintf(stdout, "Wrote %d of %d tools to %s\n", len(installed), len(lock.Tools), *output)
+	return 0
+}
+
+// runRestore installs the tools recorded in a lockfile and reports version drift
+func runRestore(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	method := fs.String("method", "", "package manager to install with (default: lockfile's, if available here)")
+	exact := fs.Bool("exact", false, "pin every tool to the version recorded in the lockfile")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "Usage: lazysetup restore [-method name] [-exact] lockfile")
+		return 2
+	}
+
+	lock, err := snapshot.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+
+	cfg, err := userConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "lazysetup: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	if *method == "" {
+		*method = restoreMethod(ctx, lock)
+	}
+	if *method == "" {
+		fmt.Fprintln(stderr, "lazysetup: no supported package manager found, use -method")
+		return 1
+	}
+	fmt.Fprintf(stdout, "Restoring %d tools with %s\n", len(lock.InstalledTools()), *method)
+
+	opts := snapshot.RestoreOptions{
+		Method:     *method,
+		Exact:      *exact,
+		Timeouts:   cfg.CommandTimeouts(),
+		Downloads:  cfg.Cache.Open(),
+		Escalation: cfg.TerminalEscalation(),
+		Flights:    cfg.FlightCommands(),
+		PreflightFailed: func(method string, err error) {
+			fmt.Fprintf(stdout, "  ! %s pre-flight failed: %v\n", method, err)
+		},
+	}
+	failed := 0
+	for _, result := range snapshot.Restore(ctx, lock, opts) {
+		switch result.Status {
+		case snapshot.RestorePresent:
+			fmt.Fprintf(stdout, "  ✓ %s %s (present)\n", result.Tool, result.Got)
+		case snapshot.RestoreInstalled:
+			fmt.Fprintf(stdout, "  ✓ %s %s (installed)\n", result.Tool, result.Got)
+		case snapshot.RestoreDrift:
+			verb := "present"
+			if result.Installed {
+				verb = "installed"
+			}
+			fmt.Fprintf(stdout, "  ~ %s %s, lockfile wants %s (%s, version drift)\n", result.Tool, result.Got, result.Wanted, verb)
+		case snapshot.RestoreFailed:
+			failed++
+			fmt.Fprintf(stdout, "  ✗ %s: %s\n", result.Tool, result.Error)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(stderr, "%d tools failed to restore\n", failed)
+		return 1
+	}
+	return 0
+}
+
+// userConfig reads the user's config file
+func userConfig() (*config.UserConfig, error) {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadUserConfig(path)
+}
+
+// restoreMethod prefers the lockfile's package manager and falls back to the one detected here
+func restoreMethod(ctx context.Context, lock *snapshot.Lockfile) string {
+	if lock.PackageManager != "" && snapshot.IsMethodAvailable(ctx, lock.PackageManager) {
+		return lock.PackageManager
+	}
+	return snapshot.DetectPackageManager(ctx)
+}
@@ -2,7 +2,8 @@ package colors
 
 import "github.com/jesseduffield/gocui"
 
-const (
+// Panel colors of the active theme, set by Apply
+var (
 	// Background colors
 	BgDark = gocui.ColorDefault
 
@@ -26,13 +27,17 @@ const (
 	ActiveBorderColor = gocui.ColorGreen
 )
 
-// ANSI color codes for inline text coloring
-const (
-	ANSIGreen   = "\033[32m"
-	ANSIRed     = "\033[31m"
-	ANSIYellow  = "\033[33m"
-	ANSIMagenta = "\033[35m"
-	ANSICyan    = "\033[36m"
-	ANSIWhite   = "\033[37m"
-	ANSIReset   = "\033[0m"
+// ANSI escapes of the active theme for inline text coloring, named by role, set by Apply
+// gocui only interprets the basic SGR codes (30-37, 40-47, 1, 4, 7 and 0)
+var (
+	ANSISuccess  = "\033[32m"   // Succeeded tools and completed actions
+	ANSIFailure  = "\033[31m"   // Failed tools and errors
+	ANSIWarning  = "\033[33m"   // Retries, hints, notices and the paused queue
+	ANSIAccent   = "\033[35m"   // Item under the cursor, spinner
+	ANSIActive   = "\033[32m"   // Items of the active panel
+	ANSIInfo     = "\033[36m"   // Search query, informational messages
+	ANSIProgress = "\033[1;34m" // Running actions and their spinner
+	ANSIText     = "\033[37m"   // Plain labels
+	ANSIBold     = "\033[1m"    // Headings and emphasis, without changing the color
+	ANSIReset    = "\033[0m"
 )
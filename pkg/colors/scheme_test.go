@@ -10,7 +10,7 @@ import (
 // Tests that ANSI codes start with escape sequence and are non-empty.
 func TestANSIColorCodes_ValidEscapeSequences(t *testing.T) {
 	t.Run("ANSI codes start with escape sequence", func(t *testing.T) {
-		codes := []string{ANSIGreen, ANSIRed, ANSIYellow, ANSIMagenta, ANSIReset}
+		codes := []string{ANSISuccess, ANSIFailure, ANSIWarning, ANSIAccent, ANSIReset}
 		for _, code := range codes {
 			if !strings.HasPrefix(code, "\033[") {
 				t.Errorf("ANSI code '%s' should start with escape sequence", code)
@@ -19,7 +19,7 @@ func TestANSIColorCodes_ValidEscapeSequences(t *testing.T) {
 	})
 
 	t.Run("ANSI codes are non-empty", func(t *testing.T) {
-		if ANSIGreen == "" || ANSIRed == "" || ANSIYellow == "" || ANSIMagenta == "" || ANSIReset == "" {
+		if ANSISuccess == "" || ANSIFailure == "" || ANSIWarning == "" || ANSIAccent == "" || ANSIReset == "" {
 			t.Error("ANSI color codes should not be empty")
 		}
 	})
@@ -0,0 +1,195 @@
+package colors
+
+import (
+	"os"
+
+	"github.com/jesseduffield/gocui"
+)
+
+// Theme names accepted in the config file's theme setting
+const (
+	ThemeDark           = "dark"
+	ThemeLight          = "light"
+	ThemeHighContrast   = "high-contrast"
+	ThemeColorblindSafe = "colorblind-safe"
+)
+
+// ThemeNames lists the themes in the order they are documented
+var ThemeNames = []string{ThemeDark, ThemeLight, ThemeHighContrast, ThemeColorblindSafe}
+
+// Theme is a full set of panel colors and inline ANSI escapes
+type Theme struct {
+	TextPrimary       gocui.Attribute
+	TextSecondary     gocui.Attribute
+	HighlightBg       gocui.Attribute
+	HighlightFg       gocui.Attribute
+	AccentPrimary     gocui.Attribute
+	AccentText        gocui.Attribute
+	SuccessColor      gocui.Attribute
+	FailureColor      gocui.Attribute
+	ActiveBorderColor gocui.Attribute
+
+	ANSISuccess  string
+	ANSIFailure  string
+	ANSIWarning  string
+	ANSIAccent   string
+	ANSIActive   string
+	ANSIInfo     string
+	ANSIProgress string
+	ANSIText     string
+	ANSIBold     string
+	ANSIReset    string
+}
+
+// themes holds every built-in theme by name
+var themes = map[string]Theme{
+	// The original palette, for dark terminal backgrounds
+	ThemeDark: {
+		TextPrimary:       gocui.ColorWhite,
+		TextSecondary:     gocui.ColorCyan,
+		HighlightBg:       gocui.ColorMagenta,
+		HighlightFg:       gocui.ColorGreen,
+		AccentPrimary:     gocui.ColorMagenta,
+		AccentText:        gocui.ColorCyan,
+		SuccessColor:      gocui.ColorGreen,
+		FailureColor:      gocui.ColorRed,
+		ActiveBorderColor: gocui.ColorGreen,
+		ANSISuccess:       "\033[32m",
+		ANSIFailure:       "\033[31m",
+		ANSIWarning:       "\033[33m",
+		ANSIAccent:        "\033[35m",
+		ANSIActive:        "\033[32m",
+		ANSIInfo:          "\033[36m",
+		ANSIProgress:      "\033[1;34m",
+		ANSIText:          "\033[37m",
+		ANSIBold:          "\033[1m",
+		ANSIReset:         "\033[0m",
+	},
+	// Dark text for light backgrounds; yellow and white are avoided as they wash out
+	ThemeLight: {
+		TextPrimary:       gocui.ColorBlack,
+		TextSecondary:     gocui.ColorBlue,
+		HighlightBg:       gocui.ColorBlue,
+		HighlightFg:       gocui.ColorWhite,
+		AccentPrimary:     gocui.ColorBlue,
+		AccentText:        gocui.ColorBlue,
+		SuccessColor:      gocui.ColorGreen,
+		FailureColor:      gocui.ColorRed,
+		ActiveBorderColor: gocui.ColorBlue,
+		ANSISuccess:       "\033[32m",
+		ANSIFailure:       "\033[31m",
+		ANSIWarning:       "\033[35m",
+		ANSIAccent:        "\033[1;34m",
+		ANSIActive:        "\033[34m",
+		ANSIInfo:          "\033[34m",
+		ANSIProgress:      "\033[1;34m",
+		ANSIText:          "\033[30m",
+		ANSIBold:          "\033[1m",
+		ANSIReset:         "\033[0m",
+	},
+	// Bold text and reverse video for the cursor
+	ThemeHighContrast: {
+		TextPrimary:       gocui.ColorWhite | gocui.AttrBold,
+		TextSecondary:     gocui.ColorYellow | gocui.AttrBold,
+		HighlightBg:       gocui.ColorWhite,
+		HighlightFg:       gocui.ColorBlack,
+		AccentPrimary:     gocui.ColorYellow | gocui.AttrBold,
+		AccentText:        gocui.ColorYellow | gocui.AttrBold,
+		SuccessColor:      gocui.ColorGreen | gocui.AttrBold,
+		FailureColor:      gocui.ColorRed | gocui.AttrBold,
+		ActiveBorderColor: gocui.ColorYellow | gocui.AttrBold,
+		ANSISuccess:       "\033[1;32m",
+		ANSIFailure:       "\033[1;31m",
+		ANSIWarning:       "\033[1;33m",
+		ANSIAccent:        "\033[1;7m",
+		ANSIActive:        "\033[1;37m",
+		ANSIInfo:          "\033[1;36m",
+		ANSIProgress:      "\033[1;36m",
+		ANSIText:          "\033[1;37m",
+		ANSIBold:          "\033[1m",
+		ANSIReset:         "\033[0m",
+	},
+	// Cyan and magenta instead of green and red, which stay distinct with red-green
+	// color blindness
+	ThemeColorblindSafe: {
+		TextPrimary:       gocui.ColorWhite,
+		TextSecondary:     gocui.ColorCyan,
+		HighlightBg:       gocui.ColorBlue,
+		HighlightFg:       gocui.ColorWhite,
+		AccentPrimary:     gocui.ColorBlue,
+		AccentText:        gocui.ColorCyan,
+		SuccessColor:      gocui.ColorCyan,
+		FailureColor:      gocui.ColorMagenta | gocui.AttrBold,
+		ActiveBorderColor: gocui.ColorCyan,
+		ANSISuccess:       "\033[36m",
+		ANSIFailure:       "\033[1;35m",
+		ANSIWarning:       "\033[33m",
+		ANSIAccent:        "\033[1;37m",
+		ANSIActive:        "\033[36m",
+		ANSIInfo:          "\033[34m",
+		ANSIProgress:      "\033[1;34m",
+		ANSIText:          "\033[37m",
+		ANSIBold:          "\033[1m",
+		ANSIReset:         "\033[0m",
+	},
+}
+
+// noColor keeps the terminal's own colors; bold marks the active panel and reverse video
+// the selection, so neither depends on color
+var noColor = Theme{
+	TextPrimary:       gocui.ColorDefault,
+	TextSecondary:     gocui.ColorDefault,
+	HighlightBg:       gocui.ColorDefault,
+	HighlightFg:       gocui.ColorDefault | gocui.AttrReverse,
+	AccentPrimary:     gocui.ColorDefault,
+	AccentText:        gocui.ColorDefault,
+	SuccessColor:      gocui.ColorDefault,
+	FailureColor:      gocui.ColorDefault,
+	ActiveBorderColor: gocui.ColorDefault | gocui.AttrBold,
+}
+
+// Lookup returns the built-in theme called name
+func Lookup(name string) (Theme, bool) {
+	theme, ok := themes[name]
+	return theme, ok
+}
+
+// Setup applies the theme called name, or the dark theme for unknown names
+// When the NO_COLOR environment variable is set to any non-empty value no colors are used,
+// whatever the theme (https://no-color.org)
+func Setup(name string) {
+	if os.Getenv("NO_COLOR") != "" {
+		Apply(noColor)
+		return
+	}
+	theme, ok := Lookup(name)
+	if !ok {
+		theme = themes[ThemeDark]
+	}
+	Apply(theme)
+}
+
+// Apply makes theme the active theme
+// Must be called before the UI starts, the layout reads the colors without locking
+func Apply(theme Theme) {
+	TextPrimary = theme.TextPrimary
+	TextSecondary = theme.TextSecondary
+	HighlightBg = theme.HighlightBg
+	HighlightFg = theme.HighlightFg
+	AccentPrimary = theme.AccentPrimary
+	AccentText = theme.AccentText
+	SuccessColor = theme.SuccessColor
+	FailureColor = theme.FailureColor
+	ActiveBorderColor = theme.ActiveBorderColor
+
+	ANSISuccess = theme.ANSISuccess
+	ANSIFailure = theme.ANSIFailure
+	ANSIWarning = theme.ANSIWarning
+	ANSIAccent = theme.ANSIAccent
+	ANSIActive = theme.ANSIActive
+	ANSIInfo = theme.ANSIInfo
+	ANSIProgress = theme.ANSIProgress
+	ANSIText = theme.ANSIText
+	ANSIBold = theme.ANSIBold
+	ANSIReset = theme.ANSIReset
+}
@@ -0,0 +1,86 @@
+package colors
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// sgrSupported reports whether every parameter of an SGR escape is one gocui interprets
+func sgrSupported(code string) bool {
+	if !strings.HasPrefix(code, "\033[") || !strings.HasSuffix(code, "m") {
+		return false
+	}
+	for _, param := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(code, "\033["), "m"), ";") {
+		p, err := strconv.Atoi(param)
+		if err != nil {
+			return false
+		}
+		if !(p == 0 || p == 1 || p == 4 || p == 7 || (p >= 30 && p <= 37) || (p >= 40 && p <= 47)) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestThemes tests the built-in themes.
+// Priority: P2 - An unsupported escape shows up as garbage in the panels.
+// Tests that every theme sets every escape gocui can render and tells success from failure.
+func TestThemes(t *testing.T) {
+	for _, name := range ThemeNames {
+		theme, ok := Lookup(name)
+		if !ok {
+			t.Errorf("Theme %q is listed but not defined", name)
+			continue
+		}
+
+		t.Run(name+" uses supported escapes", func(t *testing.T) {
+			codes := []string{theme.ANSISuccess, theme.ANSIFailure, theme.ANSIWarning, theme.ANSIAccent,
+				theme.ANSIActive, theme.ANSIInfo, theme.ANSIProgress, theme.ANSIText, theme.ANSIBold, theme.ANSIReset}
+			for _, code := range codes {
+				if !sgrSupported(code) {
+					t.Errorf("Unsupported escape %q", code)
+				}
+			}
+		})
+
+		t.Run(name+" tells success from failure", func(t *testing.T) {
+			if theme.ANSISuccess == theme.ANSIFailure || theme.SuccessColor == theme.FailureColor {
+				t.Error("Expected different success and failure colors")
+			}
+		})
+	}
+}
+
+// TestSetup tests selecting the theme at startup.
+// Priority: P2 - NO_COLOR must turn off every color whatever the theme.
+// Tests named themes, unknown names and NO_COLOR.
+func TestSetup(t *testing.T) {
+	defer Apply(themes[ThemeDark])
+
+	t.Run("applies the named theme", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		Setup(ThemeColorblindSafe)
+		if ANSISuccess != themes[ThemeColorblindSafe].ANSISuccess {
+			t.Errorf("Expected colorblind-safe success color, got %q", ANSISuccess)
+		}
+	})
+
+	t.Run("unknown theme falls back to dark", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		Setup("neon")
+		if ANSIFailure != themes[ThemeDark].ANSIFailure {
+			t.Errorf("Expected dark failure color, got %q", ANSIFailure)
+		}
+	})
+
+	t.Run("NO_COLOR removes all escapes", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		Setup(ThemeLight)
+		for _, code := range []string{ANSISuccess, ANSIFailure, ANSIWarning, ANSIAccent, ANSIActive, ANSIInfo, ANSIProgress, ANSIText, ANSIBold, ANSIReset} {
+			if code != "" {
+				t.Errorf("Expected no escapes, got %q", code)
+			}
+		}
+	})
+}
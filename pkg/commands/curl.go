@@ -0,0 +1,24 @@
+package commands
+
+import "regexp"
+
+// curlDownloadPattern splits a Curl recipe that downloads one file to /tmp and then
+// installs it: the URL, the download path and the commands run on the download
+var curlDownloadPattern = regexp.MustCompile(`^curl -fsSL (.+?) -o (/tmp/[^\s&|;]+) && (.+)$`)
+
+// CurlDownload is a Curl recipe split into its download and the commands that install it
+type CurlDownload struct {
+	URL  string // Shell word naming the URL; may contain $(...) to expand, such as $(uname -m)
+	Path string // Where the recipe downloads to, under /tmp
+	Rest string // Commands run after the download
+}
+
+// ParseCurlDownload splits a Curl recipe of the form `curl -fsSL URL -o /tmp/file && ...`
+// ok is false for piped installs (`curl URL | sh`) and other shapes
+func ParseCurlDownload(cmd string) (CurlDownload, bool) {
+	m := curlDownloadPattern.FindStringSubmatch(cmd)
+	if m == nil {
+		return CurlDownload{}, false
+	}
+	return CurlDownload{URL: m[1], Path: m[2], Rest: m[3]}, true
+}
@@ -0,0 +1,38 @@
+package commands
+
+import "github.com/youpele52/lazysetup/pkg/constants"
+
+// PreflightCommands refresh a package manager's index
+// They run once per install or update batch, before the first tool command of the method,
+// so fresh machines can find packages and tool commands need not refresh the index themselves
+var PreflightCommands = map[string]string{
+	"Homebrew": "brew update",
+	"APT":      "apt-get update",
+	"YUM":      "yum makecache",
+	"DNF":      "dnf makecache",
+	"Pacman":   "pacman -Sy --noconfirm",
+	"Scoop":    "scoop update",
+	"Nix":      "nix-channel --update",
+}
+
+// PostflightCommands clear the download cache of a package manager
+// They run once after an install, update or uninstall batch for each method that ran a tool.
+// Removing unused dependencies or old versions changes the whole system, so it is left to
+// methods.<method>.postflight, e.g. "apt-get autoremove -y && apt-get clean"
+var PostflightCommands = map[string]string{
+	"APT":    "apt-get clean",
+	"YUM":    "yum clean packages",
+	"DNF":    "dnf clean packages",
+	"Pacman": "pacman -Sc --noconfirm",
+}
+
+// HasPreflight reports whether an action's batches run pre-flight commands
+// Only installs and updates need a fresh package index
+func HasPreflight(action string) bool {
+	return action == constants.ToolActionInstall || action == constants.ToolActionUpdate
+}
+
+// HasPostflight reports whether an action's batches run post-flight commands
+func HasPostflight(action string) bool {
+	return HasPreflight(action) || action == constants.ToolActionUninstall
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/youpele52/lazysetup/pkg/constants"
+)
+
+// VersionConstraint is a parsed version pin such as "1.9.x", "20" or "1.9.3"
+// A trailing x or * makes it a prefix match; otherwise the version is used as given
+type VersionConstraint struct {
+	Raw      string   // Constraint as written by the user
+	Parts    []string // Numeric components without the wildcard, e.g. ["1", "9"]
+	Wildcard bool     // Whether the constraint ended in .x or .*
+}
+
+var constraintPattern = regexp.MustCompile(`^=?v?(\d+(?:\.\d+)*)(\.[xX*])?$`)
+
+// ParseVersionConstraint parses a version pin, accepting an optional leading "=" or "v"
+func ParseVersionConstraint(constraint string) (VersionConstraint, error) {
+	raw := strings.TrimSpace(constraint)
+	match := constraintPattern.FindStringSubmatch(raw)
+	if match == nil {
+		return VersionConstraint{}, fmt.Errorf("invalid version constraint %q (use e.g. 1.9.x, 20 or 1.9.3)", constraint)
+	}
+	return VersionConstraint{
+		Raw:      raw,
+		Parts:    strings.Split(match[1], "."),
+		Wildcard: match[2] != "",
+	}, nil
+}
+
+// Version returns the numeric part of the constraint, e.g. "1.9" for "1.9.x"
+func (c VersionConstraint) Version() string {
+	return strings.Join(c.Parts, ".")
+}
+
+// IsPrefix reports whether the constraint matches a family of versions rather than one release
+// Constraints with a wildcard or fewer than three components ("20", "1.9") are prefixes
+func (c VersionConstraint) IsPrefix() bool {
+	return c.Wildcard || len(c.Parts) < 3
+}
+
+// Matches reports whether an installed version satisfies the constraint
+func (c VersionConstraint) Matches(version string) bool {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	pinned := c.Version()
+	if version == pinned {
+		return true
+	}
+	return c.IsPrefix() && strings.HasPrefix(version, pinned+".")
+}
+
+// GeneratePinnedInstallCommand creates an install command that honors a version constraint
+// Each method expresses the pin in its own syntax (brew formula@1.9, apt pkg=1.9.*, a tagged
+// release URL for Curl); an error is returned when the method cannot honor the pin
+func GeneratePinnedInstallCommand(method, tool, constraint string) (string, error) {
+	pin, err := ParseVersionConstraint(constraint)
+	if err != nil {
+		return "", err
+	}
+	pkgName := GetPackageName(tool, method)
+	version := pin.Version()
+
+	switch method {
+	case "Homebrew":
+		// Homebrew only ships versioned formulae per major or major.minor line
+		if len(pin.Parts) > 2 {
+			return "", fmt.Errorf("Homebrew cannot pin %s to %s: only versioned formulae such as %s@%s exist",
+				tool, pin.Raw, pkgName, strings.Join(pin.Parts[:2], "."))
+		}
+		return fmt.Sprintf("brew install %s@%s", pkgName, version), nil
+	case "APT":
+		return fmt.Sprintf("apt-get install -y '%s=%s'", pkgName, distroVersionPattern(pin, ".*", "*")), nil
+	case "YUM":
+		return fmt.Sprintf("yum install -y '%s-%s'", pkgName, distroVersionPattern(pin, ".*", "")), nil
+	case "DNF":
+		return fmt.Sprintf("dnf install -y '%s-%s'", pkgName, distroVersionPattern(pin, ".*", "")), nil
+	case "Scoop":
+		if pin.IsPrefix() {
+			return "", fmt.Errorf("Scoop cannot pin %s to %s: an exact version is required", tool, pin.Raw)
+		}
+		return fmt.Sprintf("scoop install %s@%s", pkgName, version), nil
+	case "Chocolatey":
+		if pin.IsPrefix() {
+			return "", fmt.Errorf("Chocolatey cannot pin %s to %s: an exact version is required", tool, pin.Raw)
+		}
+		return fmt.Sprintf("choco install %s --version %s -y", pkgName, version), nil
+	case "Curl":
+		recipe, ok := curlReleases[tool]
+		if !ok {
+			return "", fmt.Errorf("the Curl recipe for %s only installs the latest release and cannot be pinned", tool)
+		}
+		if pin.IsPrefix() {
+			return "", fmt.Errorf("Curl cannot pin %s to %s: the release tag needs an exact version", tool, pin.Raw)
+		}
+		return strings.ReplaceAll(recipe, "{version}", version), nil
+	case "Pacman", "Nix":
+		return "", fmt.Errorf("%s cannot install a specific version of %s", method, tool)
+	default:
+		return "", fmt.Errorf("%s: %s", constants.UnknownMethodError, method)
+	}
+}
+
+// distroVersionPattern turns a constraint into a distro package version glob
+// Prefix constraints match the whole release line; exact ones allow a packaging revision suffix
+func distroVersionPattern(pin VersionConstraint, prefixSuffix, exactSuffix string) string {
+	if pin.IsPrefix() {
+		return pin.Version() + prefixSuffix
+	}
+	return pin.Version() + exactSuffix
+}
+
+// GetPinnedInstallCommand returns the install command for a tool, honoring the pin when one is set
+// Without a pin this is the same as GetInstallCommand
+func GetPinnedInstallCommand(method, tool, constraint string) (string, error) {
+	if strings.TrimSpace(constraint) == "" {
+		return GetInstallCommand(method, tool), nil
+	}
+	return GeneratePinnedInstallCommand(method, tool, constraint)
+}
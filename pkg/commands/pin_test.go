@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseVersionConstraint tests parsing of version pins.
+// Priority: P1 - A misparsed pin installs the wrong version.
+// Tests wildcard, prefix, exact and invalid constraints.
+func TestParseVersionConstraint(t *testing.T) {
+	t.Run("wildcard constraint is a prefix", func(t *testing.T) {
+		pin, err := ParseVersionConstraint("1.9.x")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if pin.Version() != "1.9" || !pin.Wildcard || !pin.IsPrefix() {
+			t.Errorf("Expected prefix 1.9 with wildcard, got %+v", pin)
+		}
+	})
+
+	t.Run("major only constraint is a prefix", func(t *testing.T) {
+		pin, err := ParseVersionConstraint("20")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if pin.Wildcard || !pin.IsPrefix() {
+			t.Errorf("Expected 20 to be a prefix without wildcard, got %+v", pin)
+		}
+	})
+
+	t.Run("full version with v prefix is exact", func(t *testing.T) {
+		pin, err := ParseVersionConstraint("v1.9.3")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if pin.Version() != "1.9.3" || pin.IsPrefix() {
+			t.Errorf("Expected exact 1.9.3, got %+v", pin)
+		}
+	})
+
+	t.Run("invalid constraints are rejected", func(t *testing.T) {
+		for _, raw := range []string{"", "latest", "^1.9", "1.x.3", ">=2"} {
+			if _, err := ParseVersionConstraint(raw); err == nil {
+				t.Errorf("Expected error for %q", raw)
+			}
+		}
+	})
+}
+
+// TestVersionConstraint_Matches tests matching installed versions against pins.
+// Priority: P1 - Drift detection relies on this.
+// Tests that prefixes match whole release lines without matching neighbouring versions.
+func TestVersionConstraint_Matches(t *testing.T) {
+	pin, _ := ParseVersionConstraint("1.9.x")
+
+	t.Run("matches versions in the release line", func(t *testing.T) {
+		for _, version := range []string{"1.9", "1.9.0", "1.9.8", "v1.9.2"} {
+			if !pin.Matches(version) {
+				t.Errorf("Expected 1.9.x to match %s", version)
+			}
+		}
+	})
+
+	t.Run("does not match other release lines", func(t *testing.T) {
+		for _, version := range []string{"1.10.0", "1.91.0", "2.9.0", ""} {
+			if pin.Matches(version) {
+				t.Errorf("Expected 1.9.x not to match %s", version)
+			}
+		}
+	})
+
+	t.Run("exact pin only matches itself", func(t *testing.T) {
+		exact, _ := ParseVersionConstraint("1.9.3")
+		if !exact.Matches("1.9.3") || exact.Matches("1.9.30") {
+			t.Error("Expected exact pin 1.9.3 to match only 1.9.3")
+		}
+	})
+}
+
+// TestGeneratePinnedInstallCommand tests per-method translation of version pins.
+// Priority: P1 - Pins must either be honored or fail loudly.
+// Tests each method's pin syntax and the errors for pins a method cannot honor.
+func TestGeneratePinnedInstallCommand(t *testing.T) {
+	t.Run("methods translate pins into their own syntax", func(t *testing.T) {
+		cases := []struct {
+			method, tool, pin, want string
+		}{
+			{"Homebrew", "terraform", "1.9.x", "brew install terraform@1.9"},
+			{"Homebrew", "node", "20", "brew install node@20"},
+			{"APT", "node", "20", "apt-get install -y 'nodejs=20.*'"},
+			{"APT", "jq", "1.7.1", "apt-get install -y 'jq=1.7.1*'"},
+			{"DNF", "git", "2.43.x", "dnf install -y 'git-2.43.*'"},
+			{"Chocolatey", "git", "2.43.0", "choco install git --version 2.43.0 -y"},
+		}
+		for _, c := range cases {
+			got, err := GeneratePinnedInstallCommand(c.method, c.tool, c.pin)
+			if err != nil {
+				t.Errorf("%s/%s@%s: unexpected error %v", c.method, c.tool, c.pin, err)
+				continue
+			}
+			if got != c.want {
+				t.Errorf("%s/%s@%s: expected %q, got %q", c.method, c.tool, c.pin, c.want, got)
+			}
+		}
+	})
+
+	t.Run("Curl downloads the tagged release", func(t *testing.T) {
+		got, err := GeneratePinnedInstallCommand("Curl", "terraform", "1.9.3")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !strings.Contains(got, "/terraform/1.9.3/terraform_1.9.3_linux_") || strings.Contains(got, "{version}") {
+			t.Errorf("Expected a tagged release URL, got %q", got)
+		}
+	})
+
+	t.Run("pins a method cannot honor fail loudly", func(t *testing.T) {
+		cases := []struct {
+			method, tool, pin string
+		}{
+			{"Homebrew", "terraform", "1.9.3"},
+			{"Curl", "terraform", "1.9.x"},
+			{"Curl", "node", "20"},
+			{"Curl", "terraform", "1.9"},
+			{"Scoop", "node", "20"},
+			{"Scoop", "terraform", "1.9"},
+			{"Chocolatey", "node", "20"},
+			{"Chocolatey", "terraform", "1.9"},
+			{"Curl", "git", "2.43.0"},
+			{"Scoop", "git", "2.x"},
+			{"Pacman", "git", "2.43.0"},
+			{"Nix", "git", "2.43.0"},
+			{"Unknown", "git", "2.43.0"},
+			{"APT", "git", "latest"},
+		}
+		for _, c := range cases {
+			if got, err := GeneratePinnedInstallCommand(c.method, c.tool, c.pin); err == nil {
+				t.Errorf("%s/%s@%s: expected error, got %q", c.method, c.tool, c.pin, got)
+			}
+		}
+	})
+
+	t.Run("no pin falls back to the regular install command", func(t *testing.T) {
+		got, err := GetPinnedInstallCommand("APT", "git", "")
+		if err != nil || got != GetInstallCommand("APT", "git") {
+			t.Errorf("Expected the unpinned install command, got %q (err %v)", got, err)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package commands
+
+// debArch maps uname -m to the Debian architecture names many release assets use
+const debArch = "$(uname -m | sed 's/x86_64/amd64/;s/aarch64/arm64/')"
+
+// curlReleases are the Curl recipes that install a tagged release, used when a tool is pinned
+// {version} is replaced by the pinned version; tools missing here only install latest
+var curlReleases = map[string]string{
+	"lazygit":    "curl -fsSL https://github.com/jesseduffield/lazygit/releases/download/v{version}/lazygit_{version}_Linux_$(uname -m).tar.gz -o /tmp/lazygit.tar.gz && cd /tmp && tar -xzf lazygit.tar.gz lazygit && sudo cp lazygit /usr/local/bin/",
+	"lazydocker": "curl -fsSL https://github.com/jesseduffield/lazydocker/releases/download/v{version}/lazydocker_{version}_Linux_$(uname -m).tar.gz -o /tmp/lazydocker.tar.gz && cd /tmp && tar -xzf lazydocker.tar.gz lazydocker && sudo cp lazydocker /usr/local/bin/",
+	"nvim":       "curl -fsSL https://github.com/neovim/neovim/releases/download/v{version}/nvim-linux64.tar.gz -o /tmp/nvim.tar.gz && cd /tmp && tar -xzf nvim.tar.gz && sudo cp -r nvim-linux64/* /usr/local/",
+	"tmux":       "curl -fsSL https://github.com/tmux/tmux/releases/download/{version}/tmux-{version}.tar.gz -o /tmp/tmux.tar.gz && cd /tmp && tar -xzf tmux.tar.gz && cd tmux-* && ./configure && make && sudo make install",
+	"ripgrep":    "curl -fsSL https://github.com/BurntSushi/ripgrep/releases/download/{version}/ripgrep-{version}-$(uname -m)-unknown-linux-musl.tar.gz -o /tmp/rg.tar.gz && cd /tmp && tar -xzf rg.tar.gz && sudo cp ripgrep-*/rg /usr/local/bin/",
+	"fd":         "curl -fsSL https://github.com/sharkdp/fd/releases/download/v{version}/fd-v{version}-$(uname -m)-unknown-linux-gnu.tar.gz -o /tmp/fd.tar.gz && cd /tmp && tar -xzf fd.tar.gz && sudo cp fd-*/fd /usr/local/bin/",
+	"bat":        "curl -fsSL https://github.com/sharkdp/bat/releases/download/v{version}/bat-v{version}-$(uname -m)-unknown-linux-gnu.tar.gz -o /tmp/bat.tar.gz && cd /tmp && tar -xzf bat.tar.gz && sudo cp bat-*/bat /usr/local/bin/",
+	"jq":         "curl -fsSL https://github.com/jqlang/jq/releases/download/jq-{version}/jq-linux64 -o /tmp/jq && chmod +x /tmp/jq && sudo mv /tmp/jq /usr/local/bin/",
+	"node":       "curl -fsSL https://nodejs.org/dist/v{version}/node-v{version}-linux-x64.tar.xz -o /tmp/node.tar.xz && cd /tmp && tar -xf node.tar.xz && sudo cp -r node-*/* /usr/local/",
+	"gh":         "curl -fsSL https://github.com/cli/cli/releases/download/v{version}/gh_{version}_linux_" + debArch + ".tar.gz -o /tmp/gh.tar.gz && cd /tmp && tar -xzf gh.tar.gz && sudo cp gh_*/bin/gh /usr/local/bin/",
+	"eza":        "curl -fsSL https://github.com/eza-community/eza/releases/download/v{version}/eza_$(uname -m)-unknown-linux-gnu.tar.gz -o /tmp/eza.tar.gz && cd /tmp && tar -xzf eza.tar.gz && sudo cp eza /usr/local/bin/",
+	"starship":   "curl -fsSL https://starship.rs/install.sh | sh -s -- -y --version v{version}",
+	"python3":    "curl -fsSL https://www.python.org/ftp/python/{version}/Python-{version}.tgz -o /tmp/python.tgz && cd /tmp && tar -xzf python.tgz && cd Python-* && ./configure --enable-optimizations && make && sudo make install",
+	"delta":      "curl -fsSL https://github.com/dandavison/delta/releases/download/{version}/delta-{version}-$(uname -m)-unknown-linux-gnu.tar.gz -o /tmp/delta.tar.gz && cd /tmp && tar -xzf delta.tar.gz && sudo cp delta-*/delta /usr/local/bin/",
+	"btop":       "curl -fsSL https://github.com/aristocratos/btop/releases/download/v{version}/btop-$(uname -m)-linux-musl.tbz -o /tmp/btop.tbz && cd /tmp && tar -xjf btop.tbz && sudo cp btop/bin/btop /usr/local/bin/",
+	"lazysql":    "curl -fsSL https://github.com/jorgerojas26/lazysql/releases/download/v{version}/lazysql_Linux_$(uname -m).tar.gz -o /tmp/lazysql.tar.gz && cd /tmp && tar -xzf lazysql.tar.gz && sudo cp lazysql /usr/local/bin/",
+	"tree":       "curl -fsSL http://mama.indstate.edu/users/ice/tree/src/tree-{version}.tgz -o /tmp/tree.tgz && cd /tmp && tar -xzf tree.tgz && cd tree-* && make && sudo make install",
+	"make":       "curl -fsSL https://ftp.gnu.org/gnu/make/make-{version}.tar.gz -o /tmp/make.tar.gz && cd /tmp && tar -xzf make.tar.gz && cd make-* && ./configure && make && sudo make install",
+	"just":       "curl -fsSL https://github.com/casey/just/releases/download/{version}/just-{version}-$(uname -m)-unknown-linux-musl.tar.gz -o /tmp/just.tar.gz && cd /tmp && tar -xzf just.tar.gz && sudo cp just /usr/local/bin/",
+	"wget":       "curl -fsSL https://ftp.gnu.org/gnu/wget/wget-{version}.tar.gz -o /tmp/wget.tar.gz && cd /tmp && tar -xzf wget.tar.gz && cd wget-* && ./configure && make && sudo make install",
+	"bun":        `curl -fsSL https://bun.sh/install | bash -s "bun-v{version}"`,
+	"uv":         "curl -LsSf https://astral.sh/uv/{version}/install.sh | sh",
+	"rsync":      "curl -fsSL https://github.com/RsyncProject/rsync/archive/refs/tags/v{version}.tar.gz -o /tmp/rsync.tar.gz && cd /tmp && tar -xzf rsync.tar.gz && cd rsync-* && ./configure && make && sudo make install",
+	"kubectl":    `curl -fsSL "https://dl.k8s.io/release/v{version}/bin/linux/` + debArch + `/kubectl" -o /tmp/kubectl && chmod +x /tmp/kubectl && sudo mv /tmp/kubectl /usr/local/bin/`,
+	"k9s":        "curl -fsSL https://github.com/derailed/k9s/releases/download/v{version}/k9s_Linux_" + debArch + ".tar.gz -o /tmp/k9s.tar.gz && cd /tmp && tar -xzf k9s.tar.gz && sudo cp k9s /usr/local/bin/",
+	"terraform":  "curl -fsSL https://releases.hashicorp.com/terraform/{version}/terraform_{version}_linux_" + debArch + ".zip -o /tmp/terraform.zip && cd /tmp && unzip -o terraform.zip && sudo mv terraform /usr/local/bin/",
+	"helm":       "curl -fsSL https://get.helm.sh/helm-v{version}-linux-" + debArch + ".tar.gz -o /tmp/helm.tar.gz && cd /tmp && tar -xzf helm.tar.gz && sudo cp linux-*/helm /usr/local/bin/",
+	"pnpm":       "curl -fsSL https://get.pnpm.io/install.sh | env PNPM_VERSION={version} sh -",
+}
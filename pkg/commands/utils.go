@@ -1,6 +1,9 @@
 package commands
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type LifecycleCommandsType map[string]map[string]string
 type CheckCommandsType map[string]string
@@ -107,6 +110,11 @@ var PackageNameMappings = map[string]map[string]string{
 	},
 }
 
+// ShellQuote quotes s as a single sh word
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 // GetPackageName returns the package name for a tool on a specific package manager
 // Returns the tool name itself if no mapping exists
 func GetPackageName(tool, method string) string {
@@ -155,7 +163,7 @@ func GenerateUpdateCommand(method, tool string) string {
 	case "Homebrew":
 		return fmt.Sprintf("brew upgrade %s", pkgName)
 	case "APT":
-		return fmt.Sprintf("apt-get update && apt-get upgrade -y %s", pkgName)
+		return fmt.Sprintf("apt-get upgrade -y %s", pkgName)
 	case "YUM":
 		return fmt.Sprintf("yum update -y %s", pkgName)
 	case "Scoop":
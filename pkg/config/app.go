@@ -0,0 +1,305 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/cache"
+	"github.com/youpele52/lazysetup/pkg/colors"
+	"github.com/youpele52/lazysetup/pkg/keymap"
+	"github.com/youpele52/lazysetup/pkg/privilege"
+	"github.com/youpele52/lazysetup/pkg/repos"
+	"github.com/youpele52/lazysetup/pkg/updater"
+)
+
+// Application defaults, used when the config file does not set a value
+const (
+	DefaultParallelism = 4 // Tools run at once
+	DefaultAction      = "check"
+	DefaultTheme       = colors.ThemeDark
+	MaxParallelism     = 32
+	DefaultLogKeep     = 50 // Action logs kept in the log directory
+)
+
+// ActionNames lists the accepted default_action values, in the order of Actions
+var ActionNames = []string{"check", "install", "update", "uninstall"}
+
+// Themes lists the accepted theme values
+var Themes = colors.ThemeNames
+
+// Update check defaults, used when the config file does not set a value
+const (
+	DefaultUpdateChannel  = updater.ChannelStable
+	DefaultUpdateInterval = 24 * time.Hour // Time between checks against the releases API
+)
+
+// UpdatesConfig controls the startup check for a newer lazysetup release
+type UpdatesConfig struct {
+	Check    *bool         `yaml:"check,omitempty"`    // Whether to check for updates at startup, default true
+	Endpoint string        `yaml:"endpoint,omitempty"` // Repository API URL serving GitHub's releases API
+	Channel  string        `yaml:"channel,omitempty"`  // Release channel, one of updater.Channels
+	Interval time.Duration `yaml:"interval,omitempty"` // Minimum time between checks
+}
+
+// CheckEnabled reports whether lazysetup checks for updates at startup
+func (u UpdatesConfig) CheckEnabled() bool {
+	return u.Check == nil || *u.Check
+}
+
+// GetEndpoint returns the repository API URL updates are looked up at
+func (u UpdatesConfig) GetEndpoint() string {
+	if u.Endpoint != "" {
+		return u.Endpoint
+	}
+	return updater.DefaultEndpoint
+}
+
+// GetChannel returns the release channel
+func (u UpdatesConfig) GetChannel() string {
+	if u.Channel != "" {
+		return u.Channel
+	}
+	return DefaultUpdateChannel
+}
+
+// GetInterval returns the minimum time between update checks
+func (u UpdatesConfig) GetInterval() time.Duration {
+	if u.Interval > 0 {
+		return u.Interval
+	}
+	return DefaultUpdateInterval
+}
+
+// Source returns where to look for updates
+func (u UpdatesConfig) Source() updater.Source {
+	return updater.Source{Endpoint: u.GetEndpoint(), Channel: u.GetChannel()}
+}
+
+// validate checks the updates section of the config file
+func (u UpdatesConfig) validate() error {
+	if u.Endpoint != "" {
+		parsed, err := url.Parse(u.Endpoint)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("updates.endpoint: %q is not an http(s) URL", u.Endpoint)
+		}
+	}
+	if u.Channel != "" && !contains(updater.Channels, u.Channel) {
+		return fmt.Errorf("updates.channel: unknown channel %q (use one of %v)", u.Channel, updater.Channels)
+	}
+	if u.Interval < 0 {
+		return fmt.Errorf("updates.interval: must not be negative")
+	}
+	return nil
+}
+
+// LogsConfig controls the log of each action's output
+type LogsConfig struct {
+	Enabled *bool `yaml:"enabled,omitempty"` // Whether action logs are written, default true
+	Keep    int   `yaml:"keep,omitempty"`    // How many logs are kept, the oldest are removed first
+}
+
+// LogsEnabled reports whether action logs are written
+func (l LogsConfig) LogsEnabled() bool {
+	return l.Enabled == nil || *l.Enabled
+}
+
+// GetKeep returns how many action logs are kept
+func (l LogsConfig) GetKeep() int {
+	if l.Keep > 0 {
+		return l.Keep
+	}
+	return DefaultLogKeep
+}
+
+// CacheConfig controls the shared cache of Curl recipe downloads
+type CacheConfig struct {
+	Enabled *bool         `yaml:"enabled,omitempty"` // Whether downloads are cached, default true
+	MaxAge  time.Duration `yaml:"max_age,omitempty"` // How long downloads from unversioned URLs are reused
+}
+
+// CacheEnabled reports whether Curl recipe downloads are cached
+func (c CacheConfig) CacheEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// GetMaxAge returns how long a download from a URL that can change is reused
+func (c CacheConfig) GetMaxAge() time.Duration {
+	if c.MaxAge > 0 {
+		return c.MaxAge
+	}
+	return cache.DefaultMaxAge
+}
+
+// Open returns the download cache, or nil when caching is disabled or the cache
+// directory cannot be located; Curl recipes then download on every run
+func (c CacheConfig) Open() *cache.Cache {
+	if !c.CacheEnabled() {
+		return nil
+	}
+	dir, err := cache.Dir()
+	if err != nil {
+		return nil
+	}
+	return cache.New(dir, c.GetMaxAge())
+}
+
+// ReposConfig controls adding vendor repositories for APT, DNF and YUM tools the
+// distribution's own repositories do not have, such as terraform or gh
+type ReposConfig struct {
+	Enabled *bool `yaml:"enabled,omitempty"` // Whether vendor repositories are added, default true
+}
+
+// ReposEnabled reports whether vendor repositories are added when a tool needs one
+func (r ReposConfig) ReposEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+// Open returns the records of the vendor repositories lazysetup added, or nil when adding
+// them is disabled or the state directory cannot be located; tools then install only
+// from the repositories already configured
+// An unreadable records file is not fatal, it starts an empty store
+func (r ReposConfig) Open() *repos.Store {
+	if !r.ReposEnabled() {
+		return nil
+	}
+	path, err := repos.Path()
+	if err != nil {
+		return nil
+	}
+	store, _ := repos.Load(path)
+	return store
+}
+
+// PrivilegeConfig controls how commands that need root are escalated
+// Per-method policies are set with methods.<method>.privilege
+type PrivilegeConfig struct {
+	Tool string `yaml:"tool,omitempty"` // Escalation tool, one of privilege.Tools, default auto
+}
+
+// GetTool returns the configured escalation tool
+func (p PrivilegeConfig) GetTool() string {
+	if p.Tool != "" {
+		return p.Tool
+	}
+	return privilege.ToolAuto
+}
+
+// Escalation returns how each method's commands are run as root on this machine
+func (c *UserConfig) Escalation() privilege.Escalation {
+	policies := make(map[string]string)
+	for method, methodConfig := range c.Methods {
+		if methodConfig.Privilege != "" {
+			policies[method] = methodConfig.Privilege
+		}
+	}
+	return privilege.New(c.Privilege.GetTool(), policies)
+}
+
+// TerminalEscalation is Escalation for commands run from a shell, where sudo and doas may
+// ask for the password on the terminal
+func (c *UserConfig) TerminalEscalation() privilege.Escalation {
+	escalation := c.Escalation()
+	escalation.Interactive = true
+	return escalation
+}
+
+// GetDefaultMethod returns the package manager selected at startup
+func (c *UserConfig) GetDefaultMethod() string {
+	if c.DefaultMethod != "" {
+		return c.DefaultMethod
+	}
+	return InstallMethods[0]
+}
+
+// GetDefaultAction returns the action selected at startup, one of ActionNames
+func (c *UserConfig) GetDefaultAction() string {
+	if c.DefaultAction != "" {
+		return c.DefaultAction
+	}
+	return DefaultAction
+}
+
+// GetParallelism returns how many tools run at once
+func (c *UserConfig) GetParallelism() int {
+	if c.Parallelism > 0 {
+		return c.Parallelism
+	}
+	return DefaultParallelism
+}
+
+// GetTheme returns the color theme
+func (c *UserConfig) GetTheme() string {
+	if c.Theme != "" {
+		return c.Theme
+	}
+	return DefaultTheme
+}
+
+// GetLogDir returns where action logs are written: log_dir, with a leading ~ expanded,
+// or $XDG_STATE_HOME/lazysetup/logs (~/.local/state when XDG_STATE_HOME is unset)
+// It is empty when logs.enabled is false
+func (c *UserConfig) GetLogDir() (string, error) {
+	if !c.Logs.LogsEnabled() {
+		return "", nil
+	}
+	if c.LogDir != "" {
+		return expandHome(c.LogDir)
+	}
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate state directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "lazysetup", "logs"), nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %s: %w", path, err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// validateApp checks the application settings of the config file
+func (c *UserConfig) validateApp() error {
+	if c.DefaultMethod != "" && !IsInstallMethod(c.DefaultMethod) {
+		return fmt.Errorf("default_method: unknown method %q", c.DefaultMethod)
+	}
+	if c.DefaultAction != "" && !contains(ActionNames, c.DefaultAction) {
+		return fmt.Errorf("default_action: unknown action %q (use one of %v)", c.DefaultAction, ActionNames)
+	}
+	if c.Parallelism < 0 || c.Parallelism > MaxParallelism {
+		return fmt.Errorf("parallelism: must be between 1 and %d, or 0 for the default of %d", MaxParallelism, DefaultParallelism)
+	}
+	if c.Theme != "" && !contains(Themes, c.Theme) {
+		return fmt.Errorf("theme: unknown theme %q (use one of %v)", c.Theme, Themes)
+	}
+	if _, err := keymap.New(c.Keybindings); err != nil {
+		return fmt.Errorf("keybindings.%w", err)
+	}
+	if err := c.Updates.validate(); err != nil {
+		return err
+	}
+	if c.Logs.Keep < 0 {
+		return fmt.Errorf("logs.keep: must not be negative")
+	}
+	if c.Cache.MaxAge < 0 {
+		return fmt.Errorf("cache.max_age: must not be negative")
+	}
+	if c.Privilege.Tool != "" && !contains(privilege.Tools, c.Privilege.Tool) {
+		return fmt.Errorf("privilege.tool: unknown tool %q (use one of %v)", c.Privilege.Tool, privilege.Tools)
+	}
+	return nil
+}
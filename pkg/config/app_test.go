@@ -0,0 +1,151 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/cache"
+	"github.com/youpele52/lazysetup/pkg/privilege"
+	"github.com/youpele52/lazysetup/pkg/updater"
+)
+
+// TestLoadUserConfig_AppSettings tests the application settings of the config file.
+// Priority: P1 - A bad default method or parallelism must be caught before the UI starts.
+// Tests defaults, valid settings, out-of-range values and unknown keys.
+func TestLoadUserConfig_AppSettings(t *testing.T) {
+	dir := t.TempDir()
+	load := func(t *testing.T, content string) (*UserConfig, error) {
+		t.Helper()
+		path := filepath.Join(dir, "config.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return LoadUserConfig(path)
+	}
+
+	t.Run("unset settings use defaults", func(t *testing.T) {
+		cfg := &UserConfig{}
+		if cfg.GetDefaultMethod() != InstallMethods[0] || cfg.GetDefaultAction() != DefaultAction {
+			t.Errorf("Expected default method and action, got %s and %s", cfg.GetDefaultMethod(), cfg.GetDefaultAction())
+		}
+		if cfg.GetParallelism() != DefaultParallelism || cfg.GetTheme() != DefaultTheme {
+			t.Errorf("Expected default parallelism and theme, got %d and %s", cfg.GetParallelism(), cfg.GetTheme())
+		}
+		if !cfg.Updates.CheckEnabled() {
+			t.Error("Expected update check to be enabled by default")
+		}
+		if !cfg.Cache.CacheEnabled() || cfg.Cache.GetMaxAge() != cache.DefaultMaxAge {
+			t.Errorf("Expected download cache enabled with default max age, got %s", cfg.Cache.GetMaxAge())
+		}
+		if !cfg.Repos.ReposEnabled() {
+			t.Error("Expected vendor repositories to be enabled by default")
+		}
+	})
+
+	t.Run("reads application settings", func(t *testing.T) {
+		cfg, err := load(t, "default_method: APT\ndefault_action: install\nparallelism: 8\ntheme: colorblind-safe\nupdates:\n  check: false\nlog_dir: /tmp/lazysetup-logs\n")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.GetDefaultMethod() != "APT" || cfg.GetDefaultAction() != "install" || cfg.GetParallelism() != 8 {
+			t.Errorf("Expected APT, install and 8, got %s, %s and %d", cfg.GetDefaultMethod(), cfg.GetDefaultAction(), cfg.GetParallelism())
+		}
+		if cfg.GetTheme() != "colorblind-safe" {
+			t.Errorf("Expected colorblind-safe theme, got %s", cfg.GetTheme())
+		}
+		if cfg.Updates.CheckEnabled() {
+			t.Error("Expected update check to be disabled")
+		}
+		if dir, _ := cfg.GetLogDir(); dir != "/tmp/lazysetup-logs" {
+			t.Errorf("Expected configured log dir, got %s", dir)
+		}
+	})
+
+	t.Run("log dir defaults under XDG_STATE_HOME", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "/tmp/state")
+		cfg := &UserConfig{}
+		if dir, _ := cfg.GetLogDir(); dir != "/tmp/state/lazysetup/logs" {
+			t.Errorf("Expected /tmp/state/lazysetup/logs, got %s", dir)
+		}
+	})
+
+	t.Run("logs can be turned off and limited", func(t *testing.T) {
+		cfg, err := load(t, "log_dir: /tmp/lazysetup-logs\nlogs:\n  enabled: false\n  keep: 10\n")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if dir, err := cfg.GetLogDir(); dir != "" || err != nil {
+			t.Errorf("Expected no log dir with logs disabled, got %q (%v)", dir, err)
+		}
+		if cfg.Logs.GetKeep() != 10 || (&UserConfig{}).Logs.GetKeep() != DefaultLogKeep {
+			t.Errorf("Expected 10 logs kept, and %d by default, got %d", DefaultLogKeep, cfg.Logs.GetKeep())
+		}
+	})
+
+	t.Run("reads the update source", func(t *testing.T) {
+		cfg, err := load(t, "updates:\n  endpoint: https://ghe.example.com/api/v3/repos/acme/lazysetup\n  channel: prerelease\n  interval: 6h\n")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		source := cfg.Updates.Source()
+		if source.Endpoint != "https://ghe.example.com/api/v3/repos/acme/lazysetup" || source.Channel != "prerelease" {
+			t.Errorf("Expected configured endpoint and channel, got %+v", source)
+		}
+		if cfg.Updates.GetInterval() != 6*time.Hour {
+			t.Errorf("Expected 6h interval, got %s", cfg.Updates.GetInterval())
+		}
+
+		defaults := UpdatesConfig{}
+		if defaults.GetEndpoint() != updater.DefaultEndpoint || defaults.GetChannel() != DefaultUpdateChannel || defaults.GetInterval() != DefaultUpdateInterval {
+			t.Errorf("Expected default update source, got %s, %s and %s", defaults.GetEndpoint(), defaults.GetChannel(), defaults.GetInterval())
+		}
+	})
+
+	t.Run("invalid values are rejected", func(t *testing.T) {
+		for _, content := range []string{
+			"default_method: Snap\n",
+			"default_action: reinstall\n",
+			"parallelism: 100\n",
+			"parallelism: -1\n",
+			"theme: neon\n",
+			"keybindings:\n  search: \"\"\n",
+			"keybindings:\n  launch: l\n",
+			"keybindings:\n  clear: g\n",
+			"updates:\n  endpoint: ftp://mirror.example.com\n",
+			"updates:\n  channel: nightly\n",
+			"updates:\n  interval: -1h\n",
+			"cache:\n  max_age: -1h\n",
+			"logs:\n  keep: -1\n",
+			"privilege:\n  tool: su\n",
+			"methods:\n  APT:\n    privilege: sometimes\n",
+		} {
+			if _, err := load(t, content); err == nil {
+				t.Errorf("Expected error for %q", content)
+			}
+		}
+	})
+
+	t.Run("reads the privilege settings", func(t *testing.T) {
+		cfg, err := load(t, "privilege:\n  tool: none\nmethods:\n  Curl:\n    privilege: never\n")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		escalation := cfg.Escalation()
+		if escalation.Tool != "" || escalation.Policy("Curl") != privilege.PolicyNever || escalation.Policy("APT") != privilege.PolicyAlways {
+			t.Errorf("Expected no tool, Curl never and APT always, got %+v", escalation)
+		}
+		if (&UserConfig{}).Privilege.GetTool() != privilege.ToolAuto {
+			t.Error("Expected the escalation tool to be detected by default")
+		}
+	})
+
+	t.Run("unknown keys are rejected", func(t *testing.T) {
+		_, err := load(t, "paralelism: 2\n")
+		if err == nil || !strings.Contains(err.Error(), "paralelism") {
+			t.Errorf("Expected error naming the unknown key, got %v", err)
+		}
+	})
+}
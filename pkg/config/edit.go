@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GetValue returns the value of a dotted key (e.g. "timeouts.action") in the config file
+// at path, as YAML; ok is false when the file does not set the key
+func GetValue(path, key string) (value string, ok bool, err error) {
+	doc, err := readDocument(path)
+	if err != nil {
+		return "", false, err
+	}
+	node := lookup(doc.Content[0], strings.Split(key, "."))
+	if node == nil {
+		return "", false, nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node.Value, true, nil
+	}
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	return strings.TrimRight(string(data), "\n"), true, nil
+}
+
+// SetValue sets a dotted key in the config file at path, creating the file if needed
+// value is parsed as YAML, so "[APT, Curl]" sets a list and "8" a number
+// The result must pass the schema and Validate before it is written; comments are kept
+func SetValue(path, key, value string) error {
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	var parsed yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	newValue := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if len(parsed.Content) > 0 {
+		newValue = parsed.Content[0]
+	}
+	set(doc.Content[0], strings.Split(key, "."), newValue)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	data := buf.Bytes()
+	cfg := &UserConfig{}
+	if err := decodeStrict(data, cfg); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// DefaultValue returns the value lazysetup uses for a top-level or timeouts key the
+// config file does not set; ok is false for keys without a single default
+func DefaultValue(key string) (string, bool) {
+	cfg := &UserConfig{}
+	switch key {
+	case "default_method":
+		return cfg.GetDefaultMethod(), true
+	case "default_action":
+		return cfg.GetDefaultAction(), true
+	case "parallelism":
+		return strconv.Itoa(cfg.GetParallelism()), true
+	case "theme":
+		return cfg.GetTheme(), true
+	case "fallback":
+		return "[" + strings.Join(DefaultFallbackMethods, ", ") + "]", true
+	case "fallback_policy":
+		return cfg.GetFallbackPolicy(), true
+	case "updates.check":
+		return strconv.FormatBool(cfg.Updates.CheckEnabled()), true
+	case "updates.endpoint":
+		return cfg.Updates.GetEndpoint(), true
+	case "updates.channel":
+		return cfg.Updates.GetChannel(), true
+	case "updates.interval":
+		return cfg.Updates.GetInterval().String(), true
+	case "logs.enabled":
+		return strconv.FormatBool(cfg.Logs.LogsEnabled()), true
+	case "logs.keep":
+		return strconv.Itoa(cfg.Logs.GetKeep()), true
+	case "cache.enabled":
+		return strconv.FormatBool(cfg.Cache.CacheEnabled()), true
+	case "cache.max_age":
+		return cfg.Cache.GetMaxAge().String(), true
+	case "repos.enabled":
+		return strconv.FormatBool(cfg.Repos.ReposEnabled()), true
+	case "privilege.tool":
+		return cfg.Privilege.GetTool(), true
+	case "timeouts.action":
+		return DefaultActionTimeout.String(), true
+	case "timeouts.check":
+		return DefaultCheckTimeout.String(), true
+	case "timeouts.manager_check":
+		return DefaultManagerCheckTimeout.String(), true
+	case "log_dir":
+		dir, err := cfg.GetLogDir()
+		return dir, err == nil
+	}
+	return "", false
+}
+
+// readDocument parses the config file at path into a YAML document whose root is a mapping
+// A missing or empty file yields an empty mapping
+func readDocument(path string) (*yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config %s: top level must be a mapping", path)
+	}
+	return doc, nil
+}
+
+// lookup follows keys through nested mappings, returning nil when a key is missing
+func lookup(node *yaml.Node, keys []string) *yaml.Node {
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// set stores value under keys, creating or replacing intermediate mappings as needed
+func set(node *yaml.Node, keys []string, value *yaml.Node) {
+	for i, key := range keys {
+		last := i == len(keys)-1
+		var child *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				if last {
+					node.Content[j+1] = value
+					return
+				}
+				child = node.Content[j+1]
+				if child.Kind != yaml.MappingNode {
+					*child = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				}
+				break
+			}
+		}
+		if child == nil {
+			keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+			if last {
+				node.Content = append(node.Content, keyNode, value)
+				return
+			}
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, keyNode, child)
+		}
+		node = child
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSetValue tests changing one setting of the config file.
+// Priority: P1 - `lazysetup config set` must never leave an invalid or mangled file behind.
+// Tests creating files, nested keys, keeping other settings and rejecting bad values.
+func TestSetValue(t *testing.T) {
+	t.Run("creates the file and nested keys", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "lazysetup", "config.yaml")
+		if err := SetValue(path, "timeouts.action", "20m"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := SetValue(path, "fallback", "[APT, Curl]"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		cfg, err := LoadUserConfig(path)
+		if err != nil {
+			t.Fatalf("Expected valid config, got %v", err)
+		}
+		if cfg.Timeouts.Action.String() != "20m0s" {
+			t.Errorf("Expected action timeout 20m, got %s", cfg.Timeouts.Action)
+		}
+		if strings.Join(cfg.Fallback, ",") != "APT,Curl" {
+			t.Errorf("Expected fallback [APT, Curl], got %v", cfg.Fallback)
+		}
+	})
+
+	t.Run("keeps other settings and comments", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "# my setup\ndefault_method: APT\nparallelism: 2\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := SetValue(path, "parallelism", "6"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		data, _ := os.ReadFile(path)
+		if !strings.Contains(string(data), "# my setup") || !strings.Contains(string(data), "default_method: APT") {
+			t.Errorf("Expected comment and other settings kept, got %q", data)
+		}
+		if value, ok, _ := GetValue(path, "parallelism"); !ok || value != "6" {
+			t.Errorf("Expected parallelism 6, got %q", value)
+		}
+	})
+
+	t.Run("rejects invalid values and unknown keys without writing", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("parallelism: 2\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, kv := range [][2]string{{"parallelism", "100"}, {"default_method", "Snap"}, {"paralelism", "3"}} {
+			if err := SetValue(path, kv[0], kv[1]); err == nil {
+				t.Errorf("Expected error setting %s to %s", kv[0], kv[1])
+			}
+		}
+		if data, _ := os.ReadFile(path); string(data) != "parallelism: 2\n" {
+			t.Errorf("Expected file unchanged, got %q", data)
+		}
+	})
+}
+
+// TestGetValue tests reading one setting of the config file.
+// Priority: P2 - `lazysetup config get` reports what the file says, or the default.
+// Tests set, unset and nested keys.
+func TestGetValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("tools:\n  lazygit:\n    method: Curl\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("reads nested keys", func(t *testing.T) {
+		if value, ok, err := GetValue(path, "tools.lazygit.method"); err != nil || !ok || value != "Curl" {
+			t.Errorf("Expected Curl, got %q (ok=%v, err=%v)", value, ok, err)
+		}
+	})
+
+	t.Run("unset keys report not ok and fall back to defaults", func(t *testing.T) {
+		if _, ok, _ := GetValue(path, "parallelism"); ok {
+			t.Error("Expected parallelism to be unset")
+		}
+		if value, ok := DefaultValue("parallelism"); !ok || value != "4" {
+			t.Errorf("Expected default parallelism 4, got %q", value)
+		}
+		if _, ok := DefaultValue("tools.lazygit.method"); ok {
+			t.Error("Expected no default for per-tool keys")
+		}
+	})
+}
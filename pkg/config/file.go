@@ -0,0 +1,254 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/commands"
+	"github.com/youpele52/lazysetup/pkg/diagnostics"
+	"github.com/youpele52/lazysetup/pkg/privilege"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFallbackMethods is the fallback chain used when a tool's method has no command for it
+var DefaultFallbackMethods = []string{"APT", "Homebrew", "Curl"}
+
+// Fallback policies decide when a tool moves on to the next method in the fallback chain
+const (
+	FallbackOff        = "off"         // Never fall back, the tool's method is the only one tried
+	FallbackMissing    = "missing"     // Fall back only when the method has no command for the tool
+	FallbackNotFound   = "not-found"   // Also fall back when the package manager cannot find the package
+	FallbackAnyFailure = "any-failure" // Fall back whenever the install fails
+)
+
+// FallbackPolicies lists the accepted fallback_policy values
+var FallbackPolicies = []string{FallbackOff, FallbackMissing, FallbackNotFound, FallbackAnyFailure}
+
+// DefaultFallbackPolicy is used when the config file does not set fallback_policy
+const DefaultFallbackPolicy = FallbackNotFound
+
+// UserConfig is the user's config file, by default $XDG_CONFIG_HOME/lazysetup/config.yaml
+// Unknown keys are rejected so typos do not silently fall back to defaults
+//
+// Example:
+//
+//	default_method: APT
+//	default_action: install
+//	parallelism: 4
+//	fallback: [APT, Homebrew, Curl]
+//	fallback_policy: not-found
+//	ai:
+//	  enabled: true
+//	  provider: local
+//	timeouts:
+//	  action: 20m
+//	methods:
+//	  Homebrew:
+//	    timeout: 30m
+//	  Curl:
+//	    privilege: never
+//	privilege:
+//	  tool: doas
+//	tools:
+//	  lazygit:
+//	    method: Curl
+//	  terraform:
+//	    version: 1.9.x
+//	  python3:
+//	    timeout: 1h
+type UserConfig struct {
+	Fallback       []string              `yaml:"fallback,omitempty"`        // Methods tried in order when a tool's method has no command
+	FallbackPolicy string                `yaml:"fallback_policy,omitempty"` // When to move on to the next fallback method
+	Tools          map[string]ToolConfig `yaml:"tools,omitempty"`           // Per-tool settings keyed by tool name
+	AI             AIConfig              `yaml:"ai,omitempty"`              // AI-assisted error resolution, off unless enabled
+	Retry          RetryConfig           `yaml:"retry,omitempty"`           // Retry policy for install, update and uninstall
+
+	Methods  map[string]MethodConfig `yaml:"methods,omitempty"`  // Per-method settings keyed by method name
+	Timeouts TimeoutsConfig          `yaml:"timeouts,omitempty"` // Default timeouts per kind of command
+
+	// Application settings
+	DefaultMethod string            `yaml:"default_method,omitempty"` // Package manager selected at startup
+	DefaultAction string            `yaml:"default_action,omitempty"` // Action selected at startup, one of ActionNames
+	Parallelism   int               `yaml:"parallelism,omitempty"`    // Tools run at once
+	Theme         string            `yaml:"theme,omitempty"`          // Color theme, one of Themes
+	Keybindings   map[string]string `yaml:"keybindings,omitempty"`    // Keys per remappable action (keymap.Actions)
+	Updates       UpdatesConfig     `yaml:"updates,omitempty"`        // Startup update check: source, channel and caching
+	LogDir        string            `yaml:"log_dir,omitempty"`        // Where action logs are written
+	Logs          LogsConfig        `yaml:"logs,omitempty"`           // Whether action logs are written and how many are kept
+	Cache         CacheConfig       `yaml:"cache,omitempty"`          // Shared cache of Curl recipe downloads
+	Privilege     PrivilegeConfig   `yaml:"privilege,omitempty"`      // How commands that need root are escalated
+
+	Repos ReposConfig `yaml:"repos,omitempty"` // Vendor repositories added for tools the distributions lack
+}
+
+// RetryConfig overrides parts of the default retry policy; zero values keep the defaults
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts,omitempty"` // Total attempts including the first
+	BaseDelay   time.Duration `yaml:"base_delay,omitempty"`   // Wait before the first retry, e.g. 2s
+	MaxDelay    time.Duration `yaml:"max_delay,omitempty"`    // Upper bound for a single wait
+	Categories  []string      `yaml:"categories,omitempty"`   // Failure categories to retry, e.g. [network failure]
+}
+
+// AI providers accepted in the ai.provider setting
+const (
+	AIProviderLocal      = "local" // OpenAI-compatible server on this machine (Ollama, llama.cpp, LM Studio)
+	AIProviderOpenAI     = "openai"
+	AIProviderOpenRouter = "openrouter"
+	AIProviderAnthropic  = "anthropic"
+)
+
+// AIProviders lists the accepted ai.provider values
+var AIProviders = []string{AIProviderLocal, AIProviderOpenAI, AIProviderOpenRouter, AIProviderAnthropic}
+
+// AIConfig configures AI-assisted error resolution
+// Nothing is sent to any provider unless Enabled is true and the user asks for a suggestion
+type AIConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Provider  string `yaml:"provider,omitempty"`    // One of AIProviders, defaults to local
+	Endpoint  string `yaml:"endpoint,omitempty"`    // Overrides the provider's default API base URL
+	Model     string `yaml:"model,omitempty"`       // Overrides the provider's default model
+	APIKeyEnv string `yaml:"api_key_env,omitempty"` // Environment variable holding the API key
+}
+
+// ToolConfig holds the per-tool settings of the config file
+type ToolConfig struct {
+	Method  string        `yaml:"method,omitempty"`  // Package manager override for this tool
+	Version string        `yaml:"version,omitempty"` // Version pin, e.g. "1.9.x"
+	Timeout time.Duration `yaml:"timeout,omitempty"` // Install, update and uninstall timeout for this tool
+}
+
+// ConfigPath returns the config file location, honoring $XDG_CONFIG_HOME
+// Falls back to ~/.config when XDG_CONFIG_HOME is unset
+func ConfigPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate config directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "lazysetup", "config.yaml"), nil
+}
+
+// LoadUserConfig reads and validates the config file at path
+// A missing file is not an error and yields an empty config
+func LoadUserConfig(path string) (*UserConfig, error) {
+	cfg := &UserConfig{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := decodeStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// decodeStrict decodes YAML into cfg, rejecting keys the schema does not define
+// An empty document leaves cfg unchanged
+func decodeStrict(data []byte, cfg *UserConfig) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Validate checks the config's values: known methods, policies, providers and categories,
+// and durations and counts in range
+func (c *UserConfig) Validate() error {
+	if err := c.validateApp(); err != nil {
+		return err
+	}
+	for _, method := range c.Fallback {
+		if !IsInstallMethod(method) {
+			return fmt.Errorf("fallback: unknown method %q", method)
+		}
+	}
+	if c.FallbackPolicy != "" && !contains(FallbackPolicies, c.FallbackPolicy) {
+		return fmt.Errorf("fallback_policy: unknown policy %q (use one of %v)", c.FallbackPolicy, FallbackPolicies)
+	}
+	if c.Retry.MaxAttempts < 0 || c.Retry.BaseDelay < 0 || c.Retry.MaxDelay < 0 {
+		return fmt.Errorf("retry: values must not be negative")
+	}
+	for _, category := range c.Retry.Categories {
+		if _, ok := diagnostics.ParseCategory(category); !ok {
+			return fmt.Errorf("retry.categories: unknown category %q", category)
+		}
+	}
+	if c.AI.Provider != "" && !contains(AIProviders, c.AI.Provider) {
+		return fmt.Errorf("ai.provider: unknown provider %q (use one of %v)", c.AI.Provider, AIProviders)
+	}
+	for tool, toolConfig := range c.Tools {
+		if toolConfig.Method != "" && !IsInstallMethod(toolConfig.Method) {
+			return fmt.Errorf("tools.%s.method: unknown method %q", tool, toolConfig.Method)
+		}
+		if toolConfig.Timeout < 0 {
+			return fmt.Errorf("tools.%s.timeout: must not be negative", tool)
+		}
+		if toolConfig.Version != "" {
+			if _, err := commands.ParseVersionConstraint(toolConfig.Version); err != nil {
+				return fmt.Errorf("tools.%s.version: %w", tool, err)
+			}
+		}
+	}
+	for method, methodConfig := range c.Methods {
+		if !IsInstallMethod(method) {
+			return fmt.Errorf("methods: unknown method %q", method)
+		}
+		if methodConfig.Timeout < 0 {
+			return fmt.Errorf("methods.%s.timeout: must not be negative", method)
+		}
+		if methodConfig.Privilege != "" && !contains(privilege.Policies, methodConfig.Privilege) {
+			return fmt.Errorf("methods.%s.privilege: unknown policy %q (use one of %v)", method, methodConfig.Privilege, privilege.Policies)
+		}
+	}
+	if c.Timeouts.Action < 0 || c.Timeouts.Check < 0 || c.Timeouts.ManagerCheck < 0 {
+		return fmt.Errorf("timeouts: values must not be negative")
+	}
+	return nil
+}
+
+// FallbackMethods returns the configured fallback chain, or DefaultFallbackMethods when unset
+func (c *UserConfig) FallbackMethods() []string {
+	if len(c.Fallback) > 0 {
+		return c.Fallback
+	}
+	return DefaultFallbackMethods
+}
+
+// GetFallbackPolicy returns the configured fallback policy, or DefaultFallbackPolicy when unset
+func (c *UserConfig) GetFallbackPolicy() string {
+	if c.FallbackPolicy != "" {
+		return c.FallbackPolicy
+	}
+	return DefaultFallbackPolicy
+}
+
+// IsInstallMethod reports whether method is one of InstallMethods
+func IsInstallMethod(method string) bool {
+	return contains(InstallMethods, method)
+}
+
+func contains(values []string, value string) bool {
+	for _, known := range values {
+		if known == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLoadUserConfig tests reading the user config file.
+// Priority: P1 - A misread config installs tools with the wrong method or version.
+// Tests missing files, per-tool settings, the fallback chain and validation errors.
+func TestLoadUserConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("missing file yields empty config", func(t *testing.T) {
+		cfg, err := LoadUserConfig(filepath.Join(dir, "missing.yaml"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(cfg.Tools) != 0 {
+			t.Errorf("Expected no tool settings, got %v", cfg.Tools)
+		}
+		if strings.Join(cfg.FallbackMethods(), ",") != "APT,Homebrew,Curl" {
+			t.Errorf("Expected default fallback chain, got %v", cfg.FallbackMethods())
+		}
+	})
+
+	t.Run("reads per-tool methods, versions and fallback", func(t *testing.T) {
+		path := filepath.Join(dir, "config.yaml")
+		content := "fallback: [Homebrew, Curl]\ntools:\n  lazygit:\n    method: Curl\n  terraform:\n    version: 1.9.x\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := LoadUserConfig(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Tools["lazygit"].Method != "Curl" {
+			t.Errorf("Expected lazygit method Curl, got %q", cfg.Tools["lazygit"].Method)
+		}
+		if cfg.Tools["terraform"].Version != "1.9.x" {
+			t.Errorf("Expected terraform version 1.9.x, got %q", cfg.Tools["terraform"].Version)
+		}
+		if strings.Join(cfg.FallbackMethods(), ",") != "Homebrew,Curl" {
+			t.Errorf("Expected configured fallback chain, got %v", cfg.FallbackMethods())
+		}
+	})
+
+	t.Run("unknown methods are rejected", func(t *testing.T) {
+		path := filepath.Join(dir, "bad.yaml")
+		if err := os.WriteFile(path, []byte("tools:\n  git:\n    method: Snap\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadUserConfig(path); err == nil || !strings.Contains(err.Error(), "tools.git.method") {
+			t.Errorf("Expected error naming tools.git.method, got %v", err)
+		}
+	})
+
+	t.Run("invalid version pins are rejected", func(t *testing.T) {
+		path := filepath.Join(dir, "pin.yaml")
+		if err := os.WriteFile(path, []byte("tools:\n  terraform:\n    version: newest\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadUserConfig(path); err == nil || !strings.Contains(err.Error(), "tools.terraform.version") {
+			t.Errorf("Expected error naming tools.terraform.version, got %v", err)
+		}
+	})
+
+	t.Run("fallback policy defaults to not-found and is validated", func(t *testing.T) {
+		cfg := &UserConfig{}
+		if cfg.GetFallbackPolicy() != FallbackNotFound {
+			t.Errorf("Expected default policy %s, got %s", FallbackNotFound, cfg.GetFallbackPolicy())
+		}
+
+		path := filepath.Join(dir, "policy.yaml")
+		if err := os.WriteFile(path, []byte("fallback_policy: sometimes\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadUserConfig(path); err == nil || !strings.Contains(err.Error(), "fallback_policy") {
+			t.Errorf("Expected error naming fallback_policy, got %v", err)
+		}
+	})
+
+	t.Run("retry settings are parsed and validated", func(t *testing.T) {
+		path := filepath.Join(dir, "retry.yaml")
+		if err := os.WriteFile(path, []byte("retry:\n  max_attempts: 4\n  base_delay: 2s\n  categories: [network failure]\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadUserConfig(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Retry.MaxAttempts != 4 || cfg.Retry.BaseDelay != 2*time.Second {
+			t.Errorf("Expected 4 attempts and 2s base delay, got %+v", cfg.Retry)
+		}
+
+		bad := filepath.Join(dir, "retry-bad.yaml")
+		if err := os.WriteFile(bad, []byte("retry:\n  categories: [gremlins]\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadUserConfig(bad); err == nil || !strings.Contains(err.Error(), "retry.categories") {
+			t.Errorf("Expected error naming retry.categories, got %v", err)
+		}
+	})
+}
+
+// TestConfigPath tests the config file location.
+// Priority: P2 - Config must live where XDG-aware users expect it.
+// Tests that XDG_CONFIG_HOME is honored.
+func TestConfigPath(t *testing.T) {
+	t.Run("honors XDG_CONFIG_HOME", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+		path, err := ConfigPath()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if path != "/tmp/xdg/lazysetup/config.yaml" {
+			t.Errorf("Expected /tmp/xdg/lazysetup/config.yaml, got %s", path)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package config
+
+import "github.com/youpele52/lazysetup/pkg/commands"
+
+// Flights decides the commands each method runs before and after a batch
+// The zero value runs the built-in commands.PreflightCommands and PostflightCommands
+type Flights struct {
+	Preflight  map[string]string // Per-method pre-flight commands from the config file
+	Postflight map[string]string // Per-method post-flight commands from the config file
+}
+
+// FlightCommands collects the pre-flight and post-flight commands set in the config file
+func (c *UserConfig) FlightCommands() Flights {
+	flights := Flights{
+		Preflight:  make(map[string]string),
+		Postflight: make(map[string]string),
+	}
+	for method, methodConfig := range c.Methods {
+		if methodConfig.Preflight != nil {
+			flights.Preflight[method] = *methodConfig.Preflight
+		}
+		if methodConfig.Postflight != nil {
+			flights.Postflight[method] = *methodConfig.Postflight
+		}
+	}
+	return flights
+}
+
+// PreflightCommand returns the command a method runs before an action's tool commands,
+// or "" when there is none
+func (f Flights) PreflightCommand(method, action string) string {
+	if !commands.HasPreflight(action) {
+		return ""
+	}
+	if cmd, ok := f.Preflight[method]; ok {
+		return cmd
+	}
+	return commands.PreflightCommands[method]
+}
+
+// PostflightCommand returns the command a method runs after an action's tool commands,
+// or "" when there is none
+func (f Flights) PostflightCommand(method, action string) string {
+	if !commands.HasPostflight(action) {
+		return ""
+	}
+	if cmd, ok := f.Postflight[method]; ok {
+		return cmd
+	}
+	return commands.PostflightCommands[method]
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/youpele52/lazysetup/pkg/constants"
+)
+
+// TestFlights tests which commands a method runs before and after a batch.
+// Priority: P1 - Fresh machines cannot find APT packages without an index refresh.
+// Tests the built-in commands, per-action scope and overrides from the config file.
+func TestFlights(t *testing.T) {
+	t.Run("built-in commands", func(t *testing.T) {
+		var flights Flights
+		if got := flights.PreflightCommand("APT", constants.ToolActionInstall); got != "apt-get update" {
+			t.Errorf("Expected apt-get update, got %q", got)
+		}
+		if got := flights.PostflightCommand("APT", constants.ToolActionUninstall); got != "apt-get clean" {
+			t.Errorf("Expected apt-get clean, got %q", got)
+		}
+		if got := flights.PostflightCommand("Homebrew", constants.ToolActionInstall); got != "" {
+			t.Errorf("Expected no post-flight for Homebrew, got %q", got)
+		}
+		if got := flights.PreflightCommand("Curl", constants.ToolActionInstall); got != "" {
+			t.Errorf("Expected no pre-flight for Curl, got %q", got)
+		}
+	})
+
+	t.Run("scoped to actions", func(t *testing.T) {
+		var flights Flights
+		if got := flights.PreflightCommand("APT", constants.ToolActionUninstall); got != "" {
+			t.Errorf("Expected no pre-flight before uninstalls, got %q", got)
+		}
+		if got := flights.PostflightCommand("APT", constants.ToolActionCheck); got != "" {
+			t.Errorf("Expected no post-flight after checks, got %q", got)
+		}
+	})
+
+	t.Run("config file overrides and skips", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "methods:\n  APT:\n    preflight: apt-get update -q\n    postflight: \"\"\n  YUM:\n    postflight: yum autoremove -y\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadUserConfig(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		flights := cfg.FlightCommands()
+		if got := flights.PreflightCommand("APT", constants.ToolActionUpdate); got != "apt-get update -q" {
+			t.Errorf("Expected the configured pre-flight, got %q", got)
+		}
+		if got := flights.PostflightCommand("APT", constants.ToolActionInstall); got != "" {
+			t.Errorf("Expected the post-flight to be skipped, got %q", got)
+		}
+		if got := flights.PostflightCommand("YUM", constants.ToolActionUninstall); got != "yum autoremove -y" {
+			t.Errorf("Expected the opted-in autoremove, got %q", got)
+		}
+		if got := flights.PostflightCommand("DNF", constants.ToolActionInstall); got != "dnf clean packages" {
+			t.Errorf("Expected the built-in DNF post-flight, got %q", got)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/tools"
+)
+
+// Built-in timeouts, used when neither the config file nor the tool registry sets one
+const (
+	DefaultActionTimeout       = 15 * time.Minute // Install, update and uninstall
+	DefaultCheckTimeout        = 30 * time.Second // A tool's version check
+	DefaultManagerCheckTimeout = 10 * time.Second // Whether a package manager is installed
+)
+
+// TimeoutsConfig is the timeouts section of the config file; zero values keep the defaults
+type TimeoutsConfig struct {
+	Action       time.Duration `yaml:"action,omitempty"`        // Install, update and uninstall of tools without their own timeout
+	Check        time.Duration `yaml:"check,omitempty"`         // A tool's version check
+	ManagerCheck time.Duration `yaml:"manager_check,omitempty"` // Whether a package manager is installed
+}
+
+// MethodConfig holds the per-method settings of the config file
+type MethodConfig struct {
+	Timeout    time.Duration `yaml:"timeout,omitempty"`    // Install, update and uninstall timeout with this method
+	Privilege  string        `yaml:"privilege,omitempty"`  // Escalation policy, one of privilege.Policies
+	Preflight  *string       `yaml:"preflight,omitempty"`  // Command run before a batch's tool commands, "" skips it
+	Postflight *string       `yaml:"postflight,omitempty"` // Command run after a batch, "" skips it
+}
+
+// Timeouts decides how long each command may run
+// The zero value applies the tool registry's defaults and the built-in ones
+type Timeouts struct {
+	Defaults TimeoutsConfig
+	Tools    map[string]time.Duration // Per-tool action timeouts from the config file
+	Methods  map[string]time.Duration // Per-method action timeouts from the config file
+}
+
+// CommandTimeouts collects the timeouts set anywhere in the config file
+func (c *UserConfig) CommandTimeouts() Timeouts {
+	timeouts := Timeouts{
+		Defaults: c.Timeouts,
+		Tools:    make(map[string]time.Duration),
+		Methods:  make(map[string]time.Duration),
+	}
+	for tool, toolConfig := range c.Tools {
+		if toolConfig.Timeout > 0 {
+			timeouts.Tools[tool] = toolConfig.Timeout
+		}
+	}
+	for method, methodConfig := range c.Methods {
+		if methodConfig.Timeout > 0 {
+			timeouts.Methods[method] = methodConfig.Timeout
+		}
+	}
+	return timeouts
+}
+
+// Action returns the timeout to install, update or uninstall a tool with a method
+// The user's settings win over the built-in ones, the most specific first: the tool's config,
+// the method's config, timeouts.action, then the tool registry's default for the tool and
+// DefaultActionTimeout
+func (t Timeouts) Action(method, tool string) time.Duration {
+	if timeout, ok := t.Tools[tool]; ok {
+		return timeout
+	}
+	if timeout, ok := t.Methods[method]; ok {
+		return timeout
+	}
+	if t.Defaults.Action > 0 {
+		return t.Defaults.Action
+	}
+	if timeout, ok := tools.DefaultTimeouts[tool]; ok {
+		return timeout
+	}
+	return DefaultActionTimeout
+}
+
+// Check returns the timeout of a tool's version check
+func (t Timeouts) Check() time.Duration {
+	if t.Defaults.Check > 0 {
+		return t.Defaults.Check
+	}
+	return DefaultCheckTimeout
+}
+
+// ManagerCheck returns the timeout of checking whether a package manager is installed
+func (t Timeouts) ManagerCheck() time.Duration {
+	if t.Defaults.ManagerCheck > 0 {
+		return t.Defaults.ManagerCheck
+	}
+	return DefaultManagerCheckTimeout
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTimeouts_Action tests which timeout an install, update or uninstall gets.
+// Priority: P1 - Source builds killed at 15 minutes fail, while stuck small installs block a batch.
+// Tests the precedence of tool config, method config, registry defaults and global defaults.
+func TestTimeouts_Action(t *testing.T) {
+	t.Run("built-in and registry defaults", func(t *testing.T) {
+		var timeouts Timeouts
+		if got := timeouts.Action("APT", "git"); got != DefaultActionTimeout {
+			t.Errorf("Expected %v for git, got %v", DefaultActionTimeout, got)
+		}
+		if got := timeouts.Action("APT", "python3"); got != 45*time.Minute {
+			t.Errorf("Expected registry default 45m for python3, got %v", got)
+		}
+		if got := timeouts.Action("APT", "jq"); got != 5*time.Minute {
+			t.Errorf("Expected registry default 5m for jq, got %v", got)
+		}
+		for _, tool := range []string{"wget", "rsync", "tree"} {
+			if got := timeouts.Action("Curl", tool); got < 30*time.Minute {
+				t.Errorf("Expected a source build timeout for %s, got %v", tool, got)
+			}
+		}
+	})
+
+	t.Run("most specific setting wins", func(t *testing.T) {
+		cfg := &UserConfig{
+			Timeouts: TimeoutsConfig{Action: 20 * time.Minute},
+			Methods:  map[string]MethodConfig{"Homebrew": {Timeout: 30 * time.Minute}},
+			Tools:    map[string]ToolConfig{"python3": {Timeout: time.Hour}},
+		}
+		timeouts := cfg.CommandTimeouts()
+
+		cases := []struct {
+			method, tool string
+			want         time.Duration
+		}{
+			{"Homebrew", "python3", time.Hour},    // tool config
+			{"Homebrew", "jq", 30 * time.Minute},  // method config over registry
+			{"APT", "jq", 20 * time.Minute},       // global config over registry
+			{"APT", "git", 20 * time.Minute},      // global config
+			{"APT", "python3", time.Hour},         // tool config over registry
+			{"Homebrew", "git", 30 * time.Minute}, // method config over global
+		}
+		for _, c := range cases {
+			if got := timeouts.Action(c.method, c.tool); got != c.want {
+				t.Errorf("Expected %v for %s via %s, got %v", c.want, c.tool, c.method, got)
+			}
+		}
+	})
+
+	t.Run("check timeouts", func(t *testing.T) {
+		var timeouts Timeouts
+		if timeouts.Check() != DefaultCheckTimeout || timeouts.ManagerCheck() != DefaultManagerCheckTimeout {
+			t.Error("Expected built-in check timeouts")
+		}
+		timeouts.Defaults.Check = time.Minute
+		if timeouts.Check() != time.Minute {
+			t.Errorf("Expected configured check timeout, got %v", timeouts.Check())
+		}
+	})
+}
+
+// TestLoadUserConfig_Timeouts tests reading timeouts from the config file.
+// Priority: P2 - Timeouts must parse as durations and reject nonsense.
+// Tests duration parsing and validation of methods and negative values.
+func TestLoadUserConfig_Timeouts(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("durations are parsed", func(t *testing.T) {
+		path := write("ok.yaml", "timeouts:\n  check: 1m\nmethods:\n  Nix:\n    timeout: 2h\ntools:\n  python3:\n    timeout: 90m\n")
+		cfg, err := LoadUserConfig(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		timeouts := cfg.CommandTimeouts()
+		if timeouts.Check() != time.Minute || timeouts.Action("Nix", "git") != 2*time.Hour || timeouts.Action("APT", "python3") != 90*time.Minute {
+			t.Errorf("Unexpected timeouts %+v", timeouts)
+		}
+	})
+
+	t.Run("unknown methods and negative values are rejected", func(t *testing.T) {
+		if _, err := LoadUserConfig(write("method.yaml", "methods:\n  Snap:\n    timeout: 1m\n")); err == nil || !strings.Contains(err.Error(), "methods") {
+			t.Errorf("Expected error naming methods, got %v", err)
+		}
+		if _, err := LoadUserConfig(write("negative.yaml", "tools:\n  git:\n    timeout: -1m\n")); err == nil || !strings.Contains(err.Error(), "tools.git.timeout") {
+			t.Errorf("Expected error naming tools.git.timeout, got %v", err)
+		}
+	})
+}
@@ -10,8 +10,10 @@ const (
 	NoToolsSelectedError    = "Please select at least one tool for installation"
 	NoToolsSelected         = "No tools selected"
 
-	InstallationTimedOut  = "Installation timed out after 15 minutes"
+	InstallationTimedOut  = "Installation timed out"
+	TimedOutAfter         = "%s after %s"
 	InstallationCancelled = "Installation was cancelled"
+	ProcessesStillRunning = "%s, but processes it started are still running"
 	UpdateTimedOut        = "Update timed out"
 	UpdateCancelled       = "Update was cancelled"
 	UninstallTimedOut     = "Uninstall timed out"
@@ -29,11 +31,34 @@ const (
 	ToolActionInstall   = "install"
 	ToolActionUpdate    = "update"
 	ToolActionUninstall = "uninstall"
+	ToolActionRollback  = "roll back"
+
+	FallbackNotice = "--- %s could not install it, falling back to %s ---"
+
+	RepoAdded        = "--- Added the %s repository, signing key %s ---"
+	RepoAddFailed    = "Could not add the %s repository: %s"
+	RepoRemoved      = "--- Removed the %s repository, no tool installed from it is left ---"
+	RepoRemoveFailed = "--- Could not remove the %s repository: %s ---"
 
 	ErrorHtopCurlNotSupported = "htop cannot be installed via Curl. Please use Homebrew or APT instead."
 
 	ErrorTerminalTooSmall = "Terminal window too small. Please resize to at least %dx%d rows."
 
+	RollbackConfirmTitle   = "Roll Back Install Batch?"
+	RollbackConfirmMessage = "Some tools failed to install.\n\nThese tools were newly installed by this batch:\n  %s\n\nPress Enter to roll them back or Esc to keep them."
+
+	QuitConfirmTitle    = "Quit While Actions Run?"
+	QuitConfirmMessage  = "Still running:\n  %s\n\nQuitting cancels them and waits up to %s for their commands to stop, so a package manager is not left half-configured.\n\nPress Enter (or Ctrl+C again) to cancel and quit, or Esc to keep them running."
+	QuitConfirmUnnamed  = "a background action"
+	ShutdownWaiting     = "Stopping running actions (up to %s)...\n"
+	ShutdownTimedOut    = "Gave up waiting after %s; these may still be running: %s\n"
+	SummaryTitle        = "lazysetup %s results:\n"
+	SummarySucceeded    = "  ✓ %s (%ds)\n"
+	SummaryFailed       = "  ✗ %s: %s\n"
+	SummaryNotStarted   = "  - %s: not started\n"
+	SummaryStillRunning = "  … %s: still running\n"
+	SummaryTotals       = "%d succeeded, %d failed, %d not finished\n"
+
 	SudoConfirmTitle   = "Sudo Password Required"
 	SudoConfirmMessage = "Enter your sudo password:\n\nPassword: %s\n\nPress Enter to confirm or Esc to cancel."
 
@@ -46,4 +71,8 @@ const (
 
 	CheckboxSelected   = "☑"
 	CheckboxUnselected = "☐"
+
+	// Marks the tool under the cursor so it does not depend on the highlight color alone
+	CursorMarker = "▸"
+	CursorBlank  = " "
 )
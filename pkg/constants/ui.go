@@ -13,10 +13,19 @@ const (
 	PanelAction         = "panel_action"
 	PanelStatusView     = "Status"
 	PopupConfirm        = "popup_confirm"
+	PopupRollback       = "popup_rollback"
+	PopupReleaseNotes   = "popup_release_notes"
+	PopupQuit           = "popup_quit"
+	PanelAISuggestions  = "panel_ai_suggestions"
 
 	TitlePackageManager = "Package Manager"
 	TitleInstalling     = "Installing"
 	TitleTools          = "Tools"
+	TitleTransactional  = " (rollback on failure)"
+	ToolMethodSuffix    = " [%s]" // Shown after a tool that overrides the selected package manager
+	TitleAISuggestions  = "AI Suggestions (a: close)"
+	AIProviderPrefix    = "Asked "
+	AIRequesting        = "Requesting suggestions..."
 	TitleAction         = "Action"
 	TitleStatus         = "Status"
 	TitleSelection      = "Details"
@@ -24,6 +33,11 @@ const (
 	TextInstalling = "installing"
 	TextTools      = "tools"
 
+	InFlightHeader = "▸ x: cancel selected tool | p: pause queue"
+	QueuePaused    = "⏸ Queue paused - queued tools will not start until p is pressed again"
+	ETALine        = "ETA ~%s (from past runs)"
+	ProbablyHung   = "⚠ probably hung, usually takes %s"
+
 	ResultsSummaryTitle = "Installation Summary"
 	ResultsSeparator    = "===================="
 	ResultsSuccess      = "✓ %s - Success (%ds)\n"
@@ -39,6 +53,15 @@ const (
 	UpdateFailed       = "Update failed: %s"
 	UpdateCheckFailed  = "Failed to check for updates: %s"
 	UpdateNotAvailable = "You're running the latest version (v%s)"
+	UpdateInstalled    = "✓ Updated to v%s. Restart lazysetup to use it; `lazysetup self rollback` restores the previous version"
+	UpdateRolledBack   = "Restored the previous version at %s"
+
+	// Release notes popup, shown before an update is installed
+	ReleaseNotesTitle   = "What's New: v%s → v%s"
+	ReleaseNotesHint    = "Enter: install update | Esc: not now | ↑/↓: scroll"
+	ReleaseNotesEmpty   = "No release notes."
+	ReleaseNotesLink    = "Full notes: %s"
+	ReleaseNotesVersion = "v%s"
 
 	// Nix package manager disclaimer
 	NixDisclaimer = "⚠️  NIX NOTICE: Requires nixpkgs channel configured.\n    Some tools may not be available via Nix.\n    Setup guide: https://nixos.org/manual/nix/stable/installation/installing-binary\n    If errors occur, try another package manager."
@@ -0,0 +1,197 @@
+package diagnostics
+
+import "strings"
+
+// Category names a class of command failure
+type Category string
+
+const (
+	CategoryNotFound   Category = "package not found"
+	CategoryLockHeld   Category = "package manager locked"
+	CategoryNetwork    Category = "network failure"
+	CategorySudoAuth   Category = "sudo authentication failed"
+	CategoryPermission Category = "permission denied"
+	CategoryToolchain  Category = "missing build toolchain"
+	CategoryDiskFull   Category = "disk full"
+	CategoryTimeout    Category = "timed out"
+	CategoryCancelled  Category = "cancelled"
+	CategoryUnknown    Category = "unknown"
+)
+
+// Diagnosis is the classified cause of a failure with a remediation hint
+// Retryable reports whether running the same command again can succeed
+type Diagnosis struct {
+	Category  Category
+	Hint      string
+	Retryable bool
+}
+
+// diagnoses holds the hint and retry advice for each category
+var diagnoses = map[Category]Diagnosis{
+	CategoryNotFound: {
+		Hint: "This package manager has no such package; pick another method for the tool with 'm'",
+	},
+	CategoryLockHeld: {
+		Hint:      "Another package manager process holds the lock (e.g. unattended-upgrades); wait for it to finish",
+		Retryable: true,
+	},
+	CategoryNetwork: {
+		Hint:      "Check your internet connection, proxy and DNS settings",
+		Retryable: true,
+	},
+	CategorySudoAuth: {
+		Hint: "Re-run and enter the correct sudo password; your user must be allowed to use sudo",
+	},
+	CategoryPermission: {
+		Hint: "Run with sudo, or choose a method that installs into your home directory (Homebrew, Curl)",
+	},
+	CategoryToolchain: {
+		Hint: "Install build tools first: build-essential (Debian/Ubuntu), \"Development Tools\" (Fedora/RHEL) or Xcode Command Line Tools (macOS)",
+	},
+	CategoryDiskFull: {
+		Hint: "Free up disk space (e.g. apt-get clean, brew cleanup) and try again",
+	},
+	CategoryTimeout: {
+		Hint: "The command ran past its timeout; if it legitimately takes longer, raise the tool's or method's timeout in the config file",
+	},
+	CategoryCancelled: {},
+	// Unclassified failures include source builds and real package errors, too slow or too
+	// permanent to repeat by default; retry.categories can opt in
+	CategoryUnknown: {},
+}
+
+// rule maps lowercased output fragments to a category
+// Rules are checked in order, so more specific causes come before generic ones
+type rule struct {
+	category  Category
+	fragments []string
+}
+
+var rules = []rule{
+	{CategorySudoAuth, []string{
+		"sorry, try again",
+		"incorrect password attempt",
+		"sudo: a password is required",
+		"is not in the sudoers file",
+		"sudo: no tty present",
+	}},
+	{CategoryDiskFull, []string{
+		"no space left on device",
+		"not enough free disk space",
+		"disk quota exceeded",
+	}},
+	{CategoryLockHeld, []string{
+		"could not get lock",
+		"unable to acquire the dpkg frontend lock",
+		"unable to lock the administration directory",
+		"unable to lock database",
+		"another app is currently holding the yum lock",
+		"waiting for process with pid",
+		"has already locked",
+		"is locked by another process",
+	}},
+	{CategoryNotFound, []string{
+		"unable to locate package",
+		"has no installation candidate",
+		"no available formula",
+		"no formulae or casks found",
+		"no cask with this name",
+		"no match for argument",
+		"target not found",
+		"couldn't find manifest",
+		"the package was not found",
+		"does not provide attribute",
+		"404 not found",
+		"error: 404",
+	}},
+	{CategoryNetwork, []string{
+		"could not resolve host",
+		"temporary failure in name resolution",
+		"name or service not known",
+		"network is unreachable",
+		"connection timed out",
+		"connection refused",
+		"connection reset",
+		"failed to fetch",
+		"failed to connect",
+		"tls handshake",
+		"ssl_connect",
+		"curl: (6)",
+		"curl: (7)",
+		"curl: (28)",
+		"curl: (35)",
+		"curl: (56)",
+	}},
+	{CategoryToolchain, []string{
+		"no acceptable c compiler",
+		"c compiler cannot create executables",
+		"make: not found",
+		"make: command not found",
+		"gcc: not found",
+		"gcc: command not found",
+		"cc: not found",
+		"cc: command not found",
+		"autoreconf: not found",
+		"pkg-config: not found",
+	}},
+	{CategoryPermission, []string{
+		"permission denied",
+		"operation not permitted",
+		"are you root?",
+		"must be run as root",
+		"eacces",
+		"cannot execute", // The shell found the command but could not run it, exit code 126
+	}},
+}
+
+// categories lists every category in a stable order
+var categories = []Category{
+	CategoryNotFound, CategoryLockHeld, CategoryNetwork, CategorySudoAuth, CategoryPermission,
+	CategoryToolchain, CategoryDiskFull, CategoryTimeout, CategoryCancelled, CategoryUnknown,
+}
+
+// RetryableCategories returns the categories whose failures may go away on a retry
+func RetryableCategories() []Category {
+	var retryable []Category
+	for _, category := range categories {
+		if diagnoses[category].Retryable {
+			retryable = append(retryable, category)
+		}
+	}
+	return retryable
+}
+
+// ParseCategory returns the category with the given name
+func ParseCategory(name string) (Category, bool) {
+	for _, category := range categories {
+		if string(category) == name {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+// Lookup returns the diagnosis for a category
+func Lookup(category Category) Diagnosis {
+	diagnosis := diagnoses[category]
+	diagnosis.Category = category
+	return diagnosis
+}
+
+// ClassifyOutput classifies a failure from its command output alone
+func ClassifyOutput(output string) Diagnosis {
+	lower := strings.ToLower(output)
+	for _, r := range rules {
+		for _, fragment := range r.fragments {
+			if strings.Contains(lower, fragment) {
+				return Lookup(r.category)
+			}
+		}
+	}
+	return Lookup(CategoryUnknown)
+}
+
+// IsPackageNotFound reports whether output says the package manager has no such package
+func IsPackageNotFound(output string) bool {
+	return ClassifyOutput(output).Category == CategoryNotFound
+}
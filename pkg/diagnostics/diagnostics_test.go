@@ -0,0 +1,66 @@
+package diagnostics
+
+import "testing"
+
+// TestClassifyOutput tests classification of common failure output.
+// Priority: P1 - Wrong categories give misleading hints and wrong retry decisions.
+// Tests each category against real package manager and shell messages.
+func TestClassifyOutput(t *testing.T) {
+	cases := []struct {
+		output string
+		want   Category
+	}{
+		{"E: Unable to locate package lazygit", CategoryNotFound},
+		{"Warning: No available formula with the name \"lazysql\".", CategoryNotFound},
+		{"error: target not found: k9s", CategoryNotFound},
+		{"No match for argument: lazygit\nError: Unable to find a match: lazygit", CategoryNotFound},
+		{"E: Could not get lock /var/lib/dpkg/lock-frontend. It is held by process 1234 (unattended-upgr)", CategoryLockHeld},
+		{"curl: (6) Could not resolve host: github.com", CategoryNetwork},
+		{"Err:1 http://archive.ubuntu.com jammy InRelease\n  Temporary failure in name resolution", CategoryNetwork},
+		{"cp: cannot create regular file '/usr/local/bin/lazygit': Permission denied", CategoryPermission},
+		{"sh: 1: /tmp/lazygit-install.sh: cannot execute", CategoryPermission},
+		{"configure: error: no acceptable C compiler found in $PATH", CategoryToolchain},
+		{"sh: 1: make: not found", CategoryToolchain},
+		{"tar: lazygit: Wrote only 4096 of 10240 bytes\ntar: No space left on device", CategoryDiskFull},
+		{"Sorry, try again.\nsudo: 3 incorrect password attempts", CategorySudoAuth},
+		{"something unexpected happened", CategoryUnknown},
+		{"0 upgraded, 0 newly installed, 0 to remove.\nNo packages marked for update", CategoryUnknown},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.want), func(t *testing.T) {
+			if got := ClassifyOutput(c.output).Category; got != c.want {
+				t.Errorf("Expected %q for %q, got %q", c.want, c.output, got)
+			}
+		})
+	}
+}
+
+// TestDiagnosis_Retryable tests which categories allow a retry.
+// Priority: P1 - Retrying permanent failures wastes time, not retrying transient ones loses installs.
+// Tests transient and permanent categories and that every known category has a hint.
+func TestDiagnosis_Retryable(t *testing.T) {
+	t.Run("transient failures are retryable", func(t *testing.T) {
+		for _, category := range []Category{CategoryNetwork, CategoryLockHeld} {
+			if !Lookup(category).Retryable {
+				t.Errorf("Expected %q to be retryable", category)
+			}
+		}
+	})
+
+	t.Run("permanent failures are not retryable", func(t *testing.T) {
+		for _, category := range []Category{CategoryNotFound, CategorySudoAuth, CategoryPermission, CategoryToolchain, CategoryDiskFull, CategoryTimeout, CategoryCancelled, CategoryUnknown} {
+			if Lookup(category).Retryable {
+				t.Errorf("Expected %q not to be retryable", category)
+			}
+		}
+	})
+
+	t.Run("failure categories carry a hint", func(t *testing.T) {
+		for _, r := range rules {
+			if Lookup(r.category).Hint == "" {
+				t.Errorf("Expected a hint for %q", r.category)
+			}
+		}
+	})
+}
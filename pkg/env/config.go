@@ -1,15 +0,0 @@
-package env
-
-import (
-	"os"
-)
-
-type Config struct {
-	Debug bool
-}
-
-func LoadConfig() *Config {
-	return &Config{
-		Debug: os.Getenv("DEBUG") == "true",
-	}
-}
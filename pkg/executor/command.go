@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/youpele52/lazysetup/pkg/constants"
 )
 
 // CommandResult contains the outcome of a command execution
@@ -17,8 +21,20 @@ type CommandResult struct {
 	Duration  int64  // Execution time in milliseconds
 	TimedOut  bool   // Whether command timed out
 	Cancelled bool   // Whether command was cancelled
+
+	// Orphaned reports processes the command started that outlived it: members of its
+	// process group that survived SIGKILL (such as root processes under sudo), or processes
+	// that left the group while still holding its output open
+	Orphaned bool
 }
 
+// GracePeriod is how long a cancelled or timed-out command's process group has to exit
+// after SIGTERM before it is sent SIGKILL
+var GracePeriod = 5 * time.Second
+
+// reapTimeout bounds waiting for a killed process group to disappear
+const reapTimeout = time.Second
+
 // Execute runs a shell command with timeout and cancellation support
 // Uses sh -c to properly handle shell operators like &&, |, >
 // Default timeout is 10 minutes for installations
@@ -28,8 +44,34 @@ func Execute(ctx context.Context, command string) *CommandResult {
 
 // ExecuteWithTimeout runs a shell command with specified timeout and cancellation support
 // Uses sh -c to properly handle shell operators like &&, |, >
+// On Unix the shell runs in its own process group; on timeout or cancellation the whole group
+// gets SIGTERM, then SIGKILL after GracePeriod, so children such as make or apt-get stop too
 // Returns CommandResult with output, error, exit code, duration, and status flags
 func ExecuteWithTimeout(ctx context.Context, command string, timeout time.Duration) *CommandResult {
+	return run(ctx, command, "", timeout)
+}
+
+// ExecuteWithInput runs a shell command like ExecuteWithTimeout, feeding input to its stdin
+// Used to hand a password to an escalation tool without it appearing in the process arguments
+func ExecuteWithInput(ctx context.Context, command, input string, timeout time.Duration) *CommandResult {
+	return run(ctx, command, input, timeout)
+}
+
+// IsSuccess checks if command executed successfully (exit code 0, no error)
+func (r *CommandResult) IsSuccess() bool {
+	return r.Error == nil && r.ExitCode == 0
+}
+
+// ExecuteWithSudo runs a command with sudo using the provided password
+// The password is fed to sudo -S on stdin (sudo expects a newline after it)
+// Stops like ExecuteWithTimeout; sudo passes SIGTERM on to the command it runs
+func ExecuteWithSudo(ctx context.Context, command string, password string, timeout time.Duration) *CommandResult {
+	return run(ctx, "sudo -S -p '' "+command, password+"\n", timeout)
+}
+
+// run executes command with sh -c in a new process group, feeding input to its stdin, and
+// stops the group when ctx is done or timeout passes
+func run(ctx context.Context, command, input string, timeout time.Duration) *CommandResult {
 	startTime := time.Now()
 	result := &CommandResult{}
 
@@ -38,25 +80,50 @@ func ExecuteWithTimeout(ctx context.Context, command string, timeout time.Durati
 	defer cancel()
 
 	// Use sh -c to properly handle shell operators
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd := exec.Command("sh", "-c", command)
+	setProcessGroup(cmd)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
 
-	// Capture both stdout and stderr
+	// Capture both stdout and stderr through pipes this function owns, so output held open
+	// by processes that outlive the command can be detected and abandoned
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	stdoutWriter, stderrWriter := captureOutput(ctx, &stdout, &stderr)
+	outputs, err := startOutputs(cmd, stdoutWriter, stderrWriter)
+	if err == nil {
+		err = cmd.Start()
+		outputs.started()
+	}
+	if err != nil {
+		outputs.close()
+		result.Error = err
+		result.ExitCode = -1
+		return result
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
 
-	// Run the command
-	err := cmd.Run()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = stopGroup(cmd.Process, done)
+		result.Orphaned = groupRemains(cmd.Process)
+	}
+	if !outputs.wait(GracePeriod) {
+		// The command is gone but something it started still holds its output open
+		result.Orphaned = true
+	}
 
 	// Calculate duration
 	result.Duration = time.Since(startTime).Milliseconds()
+	result.Output = stdout.String() + stderr.String()
 
 	// Check for timeout
 	if ctx.Err() == context.DeadlineExceeded {
 		result.TimedOut = true
 		result.Error = fmt.Errorf("command timed out after %v", timeout)
 		result.ExitCode = -1
-		result.Output = stdout.String() + stderr.String()
 		return result
 	}
 
@@ -65,13 +132,9 @@ func ExecuteWithTimeout(ctx context.Context, command string, timeout time.Durati
 		result.Cancelled = true
 		result.Error = fmt.Errorf("command was cancelled")
 		result.ExitCode = -1
-		result.Output = stdout.String() + stderr.String()
 		return result
 	}
 
-	// Combine output
-	result.Output = stdout.String() + stderr.String()
-
 	// Handle execution errors
 	if err != nil {
 		result.Error = err
@@ -87,85 +150,123 @@ func ExecuteWithTimeout(ctx context.Context, command string, timeout time.Durati
 	return result
 }
 
-// IsSuccess checks if command executed successfully (exit code 0, no error)
-func (r *CommandResult) IsSuccess() bool {
-	return r.Error == nil && r.ExitCode == 0
+// outputPipes copies a command's stdout and stderr from pipes into writers
+type outputPipes struct {
+	readers []*os.File      // Read ends, closed to abandon output still held open
+	writers []*os.File      // Write ends, passed to the command and closed here once it starts
+	copied  []chan struct{} // Closed when the matching reader reaches EOF or is closed
 }
 
-// ExecuteWithSudo runs a command with sudo using the provided password
-// The password is piped to sudo via stdin
-func ExecuteWithSudo(ctx context.Context, command string, password string, timeout time.Duration) *CommandResult {
-	startTime := time.Now()
-	result := &CommandResult{}
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	// Wrap command with sudo -S to read password from stdin
-	// Use printf to send password with newline (sudo expects newline after password)
-	escapedPassword := strings.ReplaceAll(password, "'", "'\\''")
-	sudoCommand := fmt.Sprintf("printf '%%s\\n' '%s' | sudo -S -p '' %s", escapedPassword, command)
-	cmd := exec.CommandContext(ctx, "sh", "-c", sudoCommand)
-
-	// Capture both stdout and stderr
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	// Run the command
-	err := cmd.Run()
-
-	// Calculate duration
-	result.Duration = time.Since(startTime).Milliseconds()
-
-	// Check for timeout
-	if ctx.Err() == context.DeadlineExceeded {
-		result.TimedOut = true
-		result.Error = fmt.Errorf("command timed out after %v", timeout)
-		result.ExitCode = -1
-		result.Output = stdout.String() + stderr.String()
-		return result
+// startOutputs sets cmd's stdout and stderr to pipes copied into stdout and stderr
+func startOutputs(cmd *exec.Cmd, stdout, stderr io.Writer) (*outputPipes, error) {
+	p := &outputPipes{}
+	for _, w := range []io.Writer{stdout, stderr} {
+		r, pw, err := os.Pipe()
+		if err != nil {
+			return p, err
+		}
+		copied := make(chan struct{})
+		go func(w io.Writer) {
+			io.Copy(w, r)
+			close(copied)
+		}(w)
+		p.readers = append(p.readers, r)
+		p.writers = append(p.writers, pw)
+		p.copied = append(p.copied, copied)
 	}
+	cmd.Stdout, cmd.Stderr = p.writers[0], p.writers[1]
+	return p, nil
+}
 
-	// Check for cancellation
-	if ctx.Err() == context.Canceled {
-		result.Cancelled = true
-		result.Error = fmt.Errorf("command was cancelled")
-		result.ExitCode = -1
-		result.Output = stdout.String() + stderr.String()
-		return result
+// started closes this process's copies of the write ends, leaving the command the only writer
+func (p *outputPipes) started() {
+	for _, w := range p.writers {
+		w.Close()
 	}
+}
 
-	// Combine output (filter out password prompt from stderr)
-	output := stdout.String() + stderr.String()
-	result.Output = output
-
-	// Handle execution errors
-	if err != nil {
-		result.Error = err
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			result.ExitCode = exitErr.ExitCode()
-		} else {
-			result.ExitCode = -1
+// wait waits up to timeout for every process holding the write ends to close them, then
+// abandons the rest; it reports whether all output was copied
+func (p *outputPipes) wait(timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	complete := true
+	for _, copied := range p.copied {
+		select {
+		case <-copied:
+		case <-deadline.C:
+			complete = false
+			p.close()
 		}
-	} else {
-		result.ExitCode = 0
+		<-copied
 	}
+	return complete
+}
 
-	return result
+// close stops copying output
+func (p *outputPipes) close() {
+	for _, f := range append(p.readers, p.writers...) {
+		f.Close()
+	}
 }
 
 // GetErrorMessage returns a human-readable error message
+// Timeouts and cancellations mention processes the command started that are still running
 func (r *CommandResult) GetErrorMessage() string {
-	if r.TimedOut {
-		return "Installation timed out"
+	msg := ""
+	switch {
+	case r.TimedOut:
+		msg = constants.InstallationTimedOut
+	case r.Cancelled:
+		msg = constants.InstallationCancelled
+	case r.Error != nil:
+		return r.Error.Error()
+	default:
+		return ""
 	}
-	if r.Cancelled {
-		return "Installation was cancelled"
+	if r.Orphaned {
+		return fmt.Sprintf(constants.ProcessesStillRunning, msg)
 	}
-	if r.Error != nil {
-		return r.Error.Error()
+	return msg
+}
+
+type outputHookKey struct{}
+
+// WithOutputHook returns a context under which commands report each output line to hook
+// as it is written, so callers can show live progress; the full output is still returned
+// hook may be called from two goroutines at once (stdout and stderr)
+func WithOutputHook(ctx context.Context, hook func(line string)) context.Context {
+	return context.WithValue(ctx, outputHookKey{}, hook)
+}
+
+// captureOutput returns the writers for a command's stdout and stderr, teeing each
+// into a lineWriter when ctx carries an output hook
+func captureOutput(ctx context.Context, stdout, stderr *bytes.Buffer) (io.Writer, io.Writer) {
+	hook, ok := ctx.Value(outputHookKey{}).(func(string))
+	if !ok || hook == nil {
+		return stdout, stderr
+	}
+	return io.MultiWriter(stdout, &lineWriter{hook: hook}), io.MultiWriter(stderr, &lineWriter{hook: hook})
+}
+
+// lineWriter passes each complete, non-blank line written to it to hook
+// Carriage returns end a line too, so progress bars report their latest state
+type lineWriter struct {
+	hook    func(string)
+	pending []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexAny(w.pending, "\r\n")
+		if i < 0 {
+			break
+		}
+		if line := strings.TrimSpace(string(w.pending[:i])); line != "" {
+			w.hook(line)
+		}
+		w.pending = w.pending[i+1:]
 	}
-	return ""
+	return len(p), nil
 }
@@ -2,6 +2,8 @@ package executor
 
 import (
 	"context"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -244,6 +246,25 @@ func TestExecuteWithSudo_PasswordHandling(t *testing.T) {
 	})
 }
 
+// TestExecuteWithInput tests feeding input to a command's stdin.
+// Priority: P1 - Escalation tools read the password from stdin.
+// Tests that the input arrives and that commands without input see an empty stdin.
+func TestExecuteWithInput(t *testing.T) {
+	t.Run("feeds input to stdin", func(t *testing.T) {
+		result := ExecuteWithInput(context.Background(), "read line && echo got:$line", "secret\n", 2*time.Second)
+		if !result.IsSuccess() || strings.TrimSpace(result.Output) != "got:secret" {
+			t.Errorf("Expected got:secret, got %q (%v)", result.Output, result.Error)
+		}
+	})
+
+	t.Run("empty input reads nothing", func(t *testing.T) {
+		result := ExecuteWithInput(context.Background(), "read line", "", 2*time.Second)
+		if result.IsSuccess() {
+			t.Error("Expected read to fail on an empty stdin")
+		}
+	})
+}
+
 // TestCommandResult_GetErrorMessage tests error message generation from CommandResult.
 // Priority: P2 - User-facing error messages must be clear and accurate.
 // Tests that timeout, cancellation, and success states return appropriate messages.
@@ -286,3 +307,35 @@ func TestCommandResult_Duration(t *testing.T) {
 		}
 	})
 }
+
+// TestWithOutputHook tests live output reporting.
+// Priority: P2 - The progress dashboard shows each tool's latest output line.
+// Tests that lines from stdout and stderr reach the hook and the full output is still captured.
+func TestWithOutputHook(t *testing.T) {
+	t.Run("reports each line and keeps the output", func(t *testing.T) {
+		var mu sync.Mutex
+		var lines []string
+		ctx := WithOutputHook(context.Background(), func(line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			lines = append(lines, line)
+		})
+
+		result := ExecuteWithTimeout(ctx, "echo one; echo two >&2; printf '10%%\\r20%%\\n'", 5*time.Second)
+
+		mu.Lock()
+		defer mu.Unlock()
+		want := map[string]bool{"one": true, "two": true, "10%": true, "20%": true}
+		if len(lines) != len(want) {
+			t.Fatalf("Expected %d lines, got %q", len(want), lines)
+		}
+		for _, line := range lines {
+			if !want[line] {
+				t.Errorf("Unexpected line %q", line)
+			}
+		}
+		if !strings.Contains(result.Output, "one") || !strings.Contains(result.Output, "two") {
+			t.Errorf("Expected full output to be captured, got %q", result.Output)
+		}
+	})
+}
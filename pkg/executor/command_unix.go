@@ -0,0 +1,96 @@
+//go:build unix
+
+package executor
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup starts the shell in its own process group, so stopping it reaches every
+// process it started
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// stopGroup sends SIGTERM to the shell's process group and SIGKILL once GracePeriod passes
+// with any of it still running; done delivers the shell's Wait result
+func stopGroup(shell *os.Process, done <-chan error) error {
+	pgid := shell.Pid
+	syscall.Kill(-pgid, syscall.SIGTERM)
+	grace := time.NewTimer(GracePeriod)
+	defer grace.Stop()
+
+	var err error
+	exited := false
+	select {
+	case err = <-done:
+		exited = true
+		// The shell is gone; give the rest of the group the remainder of the grace period
+		waitGroupExit(pgid, grace.C)
+	case <-grace.C:
+	}
+	syscall.Kill(-pgid, syscall.SIGKILL)
+	if !exited {
+		err = <-done
+	}
+	return err
+}
+
+// groupRemains reports whether any process of the shell's group is still alive once the
+// killed group has had reapTimeout to disappear
+func groupRemains(shell *os.Process) bool {
+	pgid := shell.Pid
+	timeout := time.NewTimer(reapTimeout)
+	defer timeout.Stop()
+	return waitGroupExit(pgid, timeout.C)
+}
+
+// waitGroupExit polls until the process group pgid is empty or stop fires, and reports
+// whether it still has members
+func waitGroupExit(pgid int, stop <-chan time.Time) bool {
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	for groupAlive(pgid) {
+		select {
+		case <-stop:
+			return groupAlive(pgid)
+		case <-ticker.C:
+		}
+	}
+	return false
+}
+
+// groupAlive reports whether the process group pgid has members
+// EPERM means members exist that this user may not signal, such as root processes under sudo
+// Where /proc is available, killed processes waiting to be reaped (zombies) do not count
+func groupAlive(pgid int) bool {
+	if err := syscall.Kill(-pgid, 0); err != nil && err != syscall.EPERM {
+		return false
+	}
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return true
+	}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		stat, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		// Fields after the parenthesized command name: state, ppid, pgrp, ...
+		fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+		if len(fields) > 2 && fields[2] == strconv.Itoa(pgid) && fields[0] != "Z" {
+			return true
+		}
+	}
+	return false
+}
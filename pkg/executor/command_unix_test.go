@@ -0,0 +1,74 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestExecuteWithTimeout_ProcessGroup tests that stopping a command stops everything it started.
+// Priority: P1 - Esc-Esc must not leave make, apt-get or curl running in the background.
+// Tests child processes, processes ignoring SIGTERM and processes leaving the process group.
+func TestExecuteWithTimeout_ProcessGroup(t *testing.T) {
+	defer func(grace time.Duration) { GracePeriod = grace }(GracePeriod)
+	GracePeriod = 300 * time.Millisecond
+
+	// cancelAfter runs command and cancels it after delay, returning the result
+	cancelAfter := func(command string, delay time.Duration) *CommandResult {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(delay, cancel)
+		return ExecuteWithTimeout(ctx, command, 30*time.Second)
+	}
+	// firstInt parses the first line of output, a pid printed by the command
+	firstInt := func(t *testing.T, output string) int {
+		t.Helper()
+		n, err := strconv.Atoi(strings.TrimSpace(strings.SplitN(output, "\n", 2)[0]))
+		if err != nil {
+			t.Fatalf("Expected a pid in output, got %q", output)
+		}
+		return n
+	}
+
+	t.Run("cancellation stops child processes", func(t *testing.T) {
+		result := cancelAfter("echo $$; sleep 30 & sleep 30 & wait", 200*time.Millisecond)
+		if !result.Cancelled || result.Orphaned {
+			t.Errorf("Expected a cancelled command without orphans, got %+v", result)
+		}
+		if pgid := firstInt(t, result.Output); groupAlive(pgid) {
+			t.Errorf("Expected process group %d to be gone", pgid)
+		}
+	})
+
+	t.Run("processes ignoring SIGTERM are killed after the grace period", func(t *testing.T) {
+		start := time.Now()
+		result := ExecuteWithTimeout(context.Background(), "trap '' TERM; echo $$; sleep 30", 200*time.Millisecond)
+		elapsed := time.Since(start)
+		if !result.TimedOut || result.Orphaned {
+			t.Errorf("Expected a timed out command without orphans, got %+v", result)
+		}
+		if elapsed < 500*time.Millisecond || elapsed > 3*time.Second {
+			t.Errorf("Expected SIGKILL after the grace period, took %v", elapsed)
+		}
+		if pgid := firstInt(t, result.Output); groupAlive(pgid) {
+			t.Errorf("Expected process group %d to be gone", pgid)
+		}
+	})
+
+	t.Run("processes leaving the group are reported", func(t *testing.T) {
+		if _, err := exec.LookPath("setsid"); err != nil {
+			t.Skip("setsid not available")
+		}
+		result := cancelAfter("setsid sleep 30 & echo $!; sleep 30", 200*time.Millisecond)
+		pid := firstInt(t, result.Output)
+		defer syscall.Kill(pid, syscall.SIGKILL)
+		if !result.Cancelled || !result.Orphaned {
+			t.Errorf("Expected a cancelled command with orphans, got %+v", result)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package executor
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup does nothing on Windows, where processes have no Unix process groups
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// stopGroup kills the shell; processes it started are not reached, as Windows has no
+// process groups to signal. done delivers the shell's Wait result
+func stopGroup(shell *os.Process, done <-chan error) error {
+	shell.Kill()
+	return <-done
+}
+
+// groupRemains reports false, since the shell's children cannot be tracked on Windows;
+// output they hold open is still detected by the caller
+func groupRemains(shell *os.Process) bool {
+	return false
+}
@@ -9,12 +9,31 @@ import (
 	"github.com/youpele52/lazysetup/pkg/tools"
 )
 
+// MultiPanelConfirm confirms the active panel's selection (Enter key)
+// Selects the method or action, or runs the action in the Tools panel
+func MultiPanelConfirm(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if state.GetCurrentPage() != models.PageMultiPanel {
+			return nil
+		}
+		switch state.GetActivePanel() {
+		case models.PanelPackageManager:
+			return MultiPanelSelectMethod(state)(g, v)
+		case models.PanelAction:
+			return MultiPanelSelectAction(state)(g, v)
+		case models.PanelTools:
+			return MultiPanelExecuteAction(state)(g, v)
+		}
+		return nil
+	}
+}
+
 // MultiPanelSelectMethod selects installation method in the Package Manager panel
 func MultiPanelSelectMethod(state *models.State) func(*gocui.Gui, *gocui.View) error {
 	return func(g *gocui.Gui, v *gocui.View) error {
 		if state.GetCurrentPage() == models.PageMultiPanel && state.GetActivePanel() == models.PanelPackageManager {
 			state.SelectedMethod = state.InstallMethods[state.PackageManagerScroll.Cursor]
-			state.CheckStatus, state.Error = checkInstallation(state.SelectedMethod)
+			state.CheckStatus, state.Error = checkInstallation(state.SelectedMethod, state.GetTimeouts().ManagerCheck())
 
 			if state.Error == "" {
 				state.Tools = tools.Tools
@@ -73,18 +92,16 @@ func MultiPanelExecuteAction(state *models.State) func(*gocui.Gui, *gocui.View)
 			}
 		}
 
-		// Only show sudo popup for package managers that need it (APT, YUM)
-		// Curl and Homebrew don't require sudo
-		needsSudo := method == "APT" || method == "YUM"
-
-		if needsSudo {
+		// Only show sudo popup when a method the tools may run with is escalated with sudo
+		// Not needed as root, with doas or polkit, or for methods whose policy is never
+		if batchNeedsSudo(state, getToolAction(state.SelectedAction)) {
 			// Show sudo confirmation popup
 			state.SetPendingAction(state.SelectedAction)
 			state.SetShowSudoConfirm(true)
 			return nil
 		}
 
-		// For Homebrew, Scoop, Chocolatey - execute directly without sudo
+		// No password needed - execute directly
 		switch state.SelectedAction {
 		case models.ActionInstall:
 			return executeInstallAction(state)
@@ -109,7 +126,7 @@ func executeInstallAction(state *models.State) error {
 	state.ActionCompletionTime = 0
 	state.LastRenderedResultCount = 0
 
-	go runToolAction(state, constants.ToolActionInstall)
+	goAction(state, func() { runToolAction(state, constants.ToolActionInstall) })
 	return nil
 }
 
@@ -124,7 +141,7 @@ func executeUpdateAction(state *models.State) error {
 	state.ActionCompletionTime = 0
 	state.LastRenderedResultCount = 0
 
-	go runToolAction(state, constants.ToolActionUpdate)
+	goAction(state, func() { runToolAction(state, constants.ToolActionUpdate) })
 	return nil
 }
 
@@ -139,7 +156,7 @@ func executeUninstallAction(state *models.State) error {
 	state.ActionCompletionTime = 0
 	state.LastRenderedResultCount = 0
 
-	go runToolAction(state, constants.ToolActionUninstall)
+	goAction(state, func() { runToolAction(state, constants.ToolActionUninstall) })
 	return nil
 }
 
@@ -153,7 +170,7 @@ func executeCheckAction(state *models.State) error {
 	state.ActionCompletionTime = 0
 	state.LastRenderedResultCount = 0
 
-	go runToolAction(state, constants.ToolActionCheck)
+	goAction(state, func() { runToolAction(state, constants.ToolActionCheck) })
 	return nil
 }
 
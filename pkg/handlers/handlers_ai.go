@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/youpele52/lazysetup/pkg/ai"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// aiRequestTimeout bounds each provider request; local models can be slow to answer
+const aiRequestTimeout = 90 * time.Second
+
+// ToggleAISuggestions opens the AI suggestions view for the failed tools of the last action ('a' key)
+// Nothing is sent unless the user enabled AI in the config file; otherwise the view explains how to opt in
+// Pressing 'a' again closes the view
+func ToggleAISuggestions(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if state.GetShowSudoConfirm() || state.GetShowRollbackConfirm() || state.GetIsSearchMode() {
+			return nil
+		}
+		if state.GetCurrentPage() != models.PageMultiPanel {
+			return nil
+		}
+		if state.GetShowAIPanel() {
+			state.SetShowAIPanel(false)
+			return nil
+		}
+		if !state.GetInstallationDone() || len(failedResults(state)) == 0 || state.GetAIPending() {
+			return nil
+		}
+
+		state.ClearAISuggestions()
+		state.SetShowAIPanel(true)
+
+		provider, err := ai.New(state.GetAIConfig())
+		if err != nil {
+			state.SetAIMessage(err.Error())
+			return nil
+		}
+		go requestAISuggestions(state, provider)
+		return nil
+	}
+}
+
+// failedResults returns the failed results of the last action, leaving out rollbacks
+func failedResults(state *models.State) []models.InstallResult {
+	var failed []models.InstallResult
+	for _, result := range state.GetInstallResults() {
+		if !result.Success && !result.RolledBack {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// requestAISuggestions asks the provider for a fix for every failed tool, one at a time
+func requestAISuggestions(state *models.State, provider ai.Provider) {
+	state.SetAIPending(true)
+	defer state.SetAIPending(false)
+	state.SetAIMessage(constants.AIProviderPrefix + provider.Name())
+
+	action := getToolAction(state.GetSelectedAction())
+	for _, result := range failedResults(state) {
+		if !state.GetShowAIPanel() {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), aiRequestTimeout)
+		text, err := provider.Suggest(ctx, ai.Request{
+			Tool:     result.Tool,
+			Action:   action,
+			Method:   result.Method,
+			Command:  result.Command,
+			Output:   result.Output + "\n" + result.Error,
+			Category: result.Category,
+			OS:       runtime.GOOS,
+			Arch:     runtime.GOARCH,
+		})
+		cancel()
+
+		suggestion := models.AISuggestion{Tool: result.Tool, Text: text}
+		if err != nil {
+			suggestion = models.AISuggestion{Tool: result.Tool, Error: err.Error()}
+		}
+		state.AddAISuggestion(suggestion)
+	}
+}
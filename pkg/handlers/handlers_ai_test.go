@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/youpele52/lazysetup/pkg/ai"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// TestToggleAISuggestions_RequiresOptIn tests that nothing is requested without opt-in.
+// Priority: P0 - Failure output must never leave the machine unless the user enabled AI.
+// Tests that the view explains how to opt in and starts no request.
+func TestToggleAISuggestions_RequiresOptIn(t *testing.T) {
+	t.Run("disabled config shows opt-in message", func(t *testing.T) {
+		state := models.NewState()
+		state.SetInstallationDone(true)
+		state.AddInstallResult(models.InstallResult{Tool: "lazygit", Success: false})
+
+		if err := ToggleAISuggestions(state)(nil, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !state.GetShowAIPanel() {
+			t.Error("Expected the AI view to open")
+		}
+		if state.GetAIMessage() != ai.ErrDisabled.Error() {
+			t.Errorf("Expected opt-in message, got %q", state.GetAIMessage())
+		}
+		if state.GetAIPending() || len(state.GetAISuggestions()) != 0 {
+			t.Error("Expected no request to be made")
+		}
+	})
+
+	t.Run("no failures does nothing", func(t *testing.T) {
+		state := models.NewState()
+		state.SetInstallationDone(true)
+		state.AddInstallResult(models.InstallResult{Tool: "git", Success: true})
+
+		ToggleAISuggestions(state)(nil, nil)
+		if state.GetShowAIPanel() {
+			t.Error("Expected the AI view to stay closed")
+		}
+	})
+}
+
+// TestRequestAISuggestions tests requesting fixes for failed tools.
+// Priority: P2 - Each failed tool gets one suggestion or error.
+// Tests request contents, skipped rollbacks and provider errors.
+func TestRequestAISuggestions(t *testing.T) {
+	t.Run("asks once per failed tool", func(t *testing.T) {
+		state := models.NewState()
+		state.SetShowAIPanel(true)
+		state.AddInstallResult(models.InstallResult{Tool: "git", Success: true})
+		state.AddInstallResult(models.InstallResult{Tool: "lazygit", Success: false, Method: "APT", Command: "apt-get install -y lazygit", Output: "E: Unable to locate package lazygit"})
+		state.AddInstallResult(models.InstallResult{Tool: "jq", Success: false, RolledBack: true})
+
+		mock := &ai.Mock{Suggestion: "Use Curl"}
+		requestAISuggestions(state, mock)
+
+		requests := mock.Requests()
+		if len(requests) != 1 || requests[0].Tool != "lazygit" || requests[0].Command != "apt-get install -y lazygit" {
+			t.Fatalf("Expected one request for lazygit, got %+v", requests)
+		}
+		suggestions := state.GetAISuggestions()
+		if len(suggestions) != 1 || suggestions[0].Text != "Use Curl" {
+			t.Errorf("Expected suggestion for lazygit, got %+v", suggestions)
+		}
+		if state.GetAIPending() {
+			t.Error("Expected pending flag to be cleared")
+		}
+	})
+
+	t.Run("provider errors are recorded per tool", func(t *testing.T) {
+		state := models.NewState()
+		state.SetShowAIPanel(true)
+		state.AddInstallResult(models.InstallResult{Tool: "lazygit", Success: false})
+
+		requestAISuggestions(state, &ai.Mock{Err: errors.New("connection refused")})
+
+		suggestions := state.GetAISuggestions()
+		if len(suggestions) != 1 || suggestions[0].Error != "connection refused" {
+			t.Errorf("Expected recorded error, got %+v", suggestions)
+		}
+	})
+}
@@ -1,48 +1,78 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/youpele52/lazysetup/pkg/cache"
 	"github.com/youpele52/lazysetup/pkg/commands"
 	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/diagnostics"
 	"github.com/youpele52/lazysetup/pkg/executor"
 	"github.com/youpele52/lazysetup/pkg/models"
 )
 
+// queuePollInterval is how often a paused queue checks whether it may resume
+const queuePollInterval = 100 * time.Millisecond
+
 // runToolAction executes the specified action on all selected tools concurrently
+// Each tool runs with its own method override or the selected method, falling back along
+// the fallback chain when that method has no command for the tool; installs also fall back
+// when the package manager cannot find the package, per the fallback policy
+// Each method's pre-flight command (such as an index refresh) runs once before its first tool
+// command, and its post-flight command (cleanup) once after every tool finished
+// At most the configured parallelism of tools run at once; the rest wait in selection order
+// and do not start while the queue is paused. Each running tool has its own cancellation context
+// Successful durations are added to the duration history, which also gives each tool's ETA,
+// and the combined output is written to the log directory
+// In transactional mode, installs remember which tools were absent beforehand so a
+// partially failed batch can offer to roll them back
 func runToolAction(state *models.State, action string) {
-	spinnerTicker := time.NewTicker(100 * time.Millisecond)
-	defer spinnerTicker.Stop()
+	stopSpinner := startSpinner(state)
+	state.SetLastAction(action)
 
-	spinnerDone := make(chan bool)
-
-	go func() {
-		for {
-			select {
-			case <-spinnerDone:
-				return
-			case <-spinnerTicker.C:
-				if !state.GetInstallationDone() {
-					state.IncrementSpinnerFrame()
-				}
-			}
-		}
-	}()
+	transactional := action == constants.ToolActionInstall && state.GetTransactionalMode()
+	state.ClearNewlyInstalled()
+	state.SetInFlightCursor(0)
+	availability := newMethodAvailability(state.GetTimeouts().ManagerCheck())
+	flights := newBatchFlights(state, action)
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	resultsChan := make(chan models.InstallResult, len(state.Tools))
+	slots := make(chan struct{}, state.GetParallelism())
 
+	var queue []string
 	for _, tool := range state.Tools {
 		if state.SelectedTools[tool] {
-			if state.GetAbortInstallation() {
+			queue = append(queue, tool)
+		}
+	}
+	state.InitToolProgress(queue)
+	durations := state.GetDurationHistory()
+	for _, tool := range queue {
+		if expected, ok := durations.Expected(action, state.GetPreferredMethod(tool), tool); ok {
+			state.SetToolExpected(tool, expected)
+		}
+	}
+
+	go func() {
+		for _, tool := range queue {
+			if !waitForQueueSlot(state, slots) {
 				break
 			}
 
 			wg.Add(1)
 			go func(toolName string) {
 				defer wg.Done()
+				defer func() { <-slots }()
 
 				if state.GetAbortInstallation() {
 					return
@@ -50,22 +80,44 @@ func runToolAction(state *models.State, action string) {
 
 				startTime := time.Now().Unix()
 				state.SetToolStartTime(toolName, startTime)
+				state.StartToolContext(toolName)
+				defer state.FinishToolContext(toolName)
+				state.MarkToolRunning(toolName, startTime)
+
+				var method string
+				if action != constants.ToolActionCheck && action != constants.ToolActionInstall {
+					method = resolveToolMethod(state, action, toolName, availability.isAvailable)
+					if expected, ok := durations.Expected(action, method, toolName); ok {
+						state.SetToolExpected(toolName, expected)
+					}
+				}
 
 				var status, errMsg, output string
+				var retries int
 				params := ToolActionParams{
 					State:  state,
-					Method: state.SelectedMethod,
+					Method: method,
 					Tool:   toolName,
 				}
 				switch action {
 				case constants.ToolActionCheck:
 					status, errMsg, output = checkToolWithOutput(params)
 				case constants.ToolActionInstall:
-					status, errMsg, output = installToolWithRetry(state, state.SelectedMethod, toolName)
+					alreadyPresent := transactional && isToolPresent(params)
+					status, errMsg, output, method, retries = installWithFallback(state, toolName, availability.isAvailable, flights)
+					if transactional && !alreadyPresent && status == constants.StatusSuccess {
+						state.AddNewlyInstalled(toolName)
+					}
 				case constants.ToolActionUpdate:
-					status, errMsg, output = updateToolWithOutput(params)
+					flights.preflight(method)
+					status, errMsg, output, retries = withRetry(state, toolName, func() (string, string, string) {
+						return updateToolWithOutput(params)
+					})
 				case constants.ToolActionUninstall:
-					status, errMsg, output = uninstallToolWithOutput(params)
+					flights.preflight(method)
+					status, errMsg, output, retries = withRetry(state, toolName, func() (string, string, string) {
+						return uninstallToolWithOutput(params)
+					})
 				}
 
 				mu.Lock()
@@ -78,14 +130,24 @@ func runToolAction(state *models.State, action string) {
 					Success:  status == constants.StatusSuccess,
 					Error:    errMsg,
 					Duration: duration,
-					Retries:  0,
+					Retries:  retries,
+					Method:   method,
 				}
+				if !result.Success && action != constants.ToolActionCheck {
+					diagnosis := diagnoseFailure(errMsg, output)
+					result.Category = string(diagnosis.Category)
+					result.Hint = diagnosis.Hint
+					result.Command = actionCommand(state, action, method, toolName)
+					result.Output = output
+				}
+				if result.Success && action != constants.ToolActionCheck {
+					durations.Add(action, method, toolName, duration)
+				}
+				state.MarkToolDone(toolName, result.Success, time.Now().Unix())
 				resultsChan <- result
 			}(tool)
 		}
-	}
 
-	go func() {
 		wg.Wait()
 		close(resultsChan)
 	}()
@@ -94,31 +156,205 @@ func runToolAction(state *models.State, action string) {
 		state.AddInstallResult(result)
 		state.IncrementInstallingIndex()
 	}
+	flights.postflight()
 
 	state.SetInstallationDone(true)
-	spinnerDone <- true
+	state.SetQueuePaused(false)
+	stopSpinner()
+
+	// Duration history only feeds estimates, so a failed save is not worth interrupting for
+	_ = durations.Save()
+	// Logs are a convenience for later inspection, the output is still shown in the Status panel
+	_ = writeActionLog(state.GetLogDir(), action, state.GetInstallOutput(), time.Now(), state.GetLogKeep())
 
 	// Set completion time for auto-clear timeout (40 seconds)
 	state.ActionCompletionTime = time.Now().Unix()
 
+	// Keep the sudo password while a rollback is on offer, it is needed to uninstall
+	if transactional && offerRollback(state) {
+		return
+	}
+
 	// Clear sudo password after action completes
 	state.ClearSudoPassword()
 }
 
+// waitForQueueSlot blocks until a queued tool may start: the queue is not paused and fewer
+// than the configured parallelism of tools are running. Returns false when the action is aborted instead
+func waitForQueueSlot(state *models.State, slots chan struct{}) bool {
+	ctx := state.GetCancelContext()
+	for state.GetQueuePaused() {
+		if state.GetAbortInstallation() || ctx.Err() != nil {
+			return false
+		}
+		time.Sleep(queuePollInterval)
+	}
+	select {
+	case slots <- struct{}{}:
+		return !state.GetAbortInstallation()
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// actionLogName matches the names writeActionLog gives action logs
+var actionLogName = regexp.MustCompile(`^[a-z]+-\d{8}-\d{6}\.log$`)
+
+// writeActionLog saves an action's combined output as <dir>/<action>-<timestamp>.log and
+// removes the oldest action logs beyond keep (keep <= 0 keeps them all)
+// Nothing is written when dir is empty
+func writeActionLog(dir, action, output string, now time.Time, keep int) error {
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.log", action, now.Format("20060102-150405"))
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(output), 0644); err != nil {
+		return fmt.Errorf("failed to write action log: %w", err)
+	}
+	if keep > 0 {
+		return pruneActionLogs(dir, keep)
+	}
+	return nil
+}
+
+// pruneActionLogs removes all but the keep newest action logs in dir
+// Other files in the directory are left alone
+func pruneActionLogs(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list action logs: %w", err)
+	}
+	type logFile struct {
+		name    string
+		modTime time.Time
+	}
+	var logs []logFile
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !actionLogName.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, logFile{entry.Name(), info.ModTime()})
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if !logs[i].modTime.Equal(logs[j].modTime) {
+			return logs[i].modTime.After(logs[j].modTime)
+		}
+		return logs[i].name > logs[j].name
+	})
+	for i := keep; i < len(logs); i++ {
+		os.Remove(filepath.Join(dir, logs[i].name))
+	}
+	return nil
+}
+
+// toolContext returns the tool's cancellation context, reporting each line of command
+// output to the tool's progress dashboard row as it is written
+func toolContext(state *models.State, tool string) context.Context {
+	return executor.WithOutputHook(state.GetToolContext(tool), func(line string) {
+		state.SetToolLastLine(tool, line)
+	})
+}
+
+// prepareCommand readies a method's command to run: a Curl recipe gets its own work directory,
+// with its download fetched through the shared cache within timeout; other commands run as is
+// errMsg is set when that fails, naming a timeout or cancellation like a failed command would
+func prepareCommand(ctx context.Context, state *models.State, method, cmd string, timeout time.Duration) (cache.Prepared, string) {
+	if method != "Curl" {
+		return cache.Prepared{Command: cmd, Cleanup: func() {}}, ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	prepared, err := state.GetDownloads().Prepare(ctx, cmd)
+	switch {
+	case err == nil:
+		return prepared, ""
+	case ctx.Err() == context.DeadlineExceeded:
+		return prepared, timedOutMessage(timeout)
+	case ctx.Err() == context.Canceled:
+		return prepared, constants.InstallationCancelled
+	}
+	return prepared, err.Error()
+}
+
+// withNotice puts a line about where a Curl recipe's download came from before its output
+func withNotice(notice, output string) string {
+	if notice == "" {
+		return output
+	}
+	return notice + "\n" + output
+}
+
+// diagnoseFailure classifies a failed action from its error message and command output
+func diagnoseFailure(errMsg, output string) diagnostics.Diagnosis {
+	switch {
+	case strings.HasPrefix(errMsg, constants.InstallationTimedOut):
+		return diagnostics.Lookup(diagnostics.CategoryTimeout)
+	case strings.HasPrefix(errMsg, constants.InstallationCancelled):
+		return diagnostics.Lookup(diagnostics.CategoryCancelled)
+	}
+	return diagnostics.ClassifyOutput(output + "\n" + errMsg)
+}
+
+// stoppedMessage is msg for a timed-out or cancelled command, warning when processes it
+// started survived being stopped, so an abort never claims to have stopped more than it did
+func stoppedMessage(msg string, result *executor.CommandResult) string {
+	if result.Orphaned {
+		return fmt.Sprintf(constants.ProcessesStillRunning, msg)
+	}
+	return msg
+}
+
+// timedOutMessage reports a command that ran past its timeout, naming the limit so it can
+// be raised in the config file
+func timedOutMessage(timeout time.Duration) string {
+	return fmt.Sprintf(constants.TimedOutAfter, constants.InstallationTimedOut, timeout)
+}
+
+// startSpinner advances the spinner frame every 100ms until the returned stop function is called
+func startSpinner(state *models.State) func() {
+	spinnerTicker := time.NewTicker(100 * time.Millisecond)
+	spinnerDone := make(chan bool)
+
+	go func() {
+		for {
+			select {
+			case <-spinnerDone:
+				return
+			case <-spinnerTicker.C:
+				if !state.GetInstallationDone() {
+					state.IncrementSpinnerFrame()
+				}
+			}
+		}
+	}()
+
+	return func() {
+		spinnerTicker.Stop()
+		spinnerDone <- true
+	}
+}
+
 func checkToolWithOutput(params ToolActionParams) (string, string, string) {
 	cmd := commands.GetToolCheckCommand(params.Tool)
 	if cmd == "" {
 		return constants.StatusFailed, "No check command found for " + params.Tool, ""
 	}
 
-	ctx := params.State.GetCancelContext()
-	result := executor.ExecuteWithTimeout(ctx, cmd, 30*time.Second)
+	ctx := toolContext(params.State, params.Tool)
+	result := executor.ExecuteWithTimeout(ctx, cmd, params.State.GetTimeouts().Check())
 
 	if result.TimedOut {
-		return constants.StatusFailed, "Check timed out", result.Output
+		return constants.StatusFailed, stoppedMessage("Check timed out", result), result.Output
 	}
 	if result.Cancelled {
-		return constants.StatusFailed, "Check cancelled", result.Output
+		return constants.StatusFailed, stoppedMessage("Check cancelled", result), result.Output
 	}
 	if result.ExitCode != 0 {
 		return constants.StatusFailed, params.Tool + " not installed", result.Output
@@ -134,24 +370,26 @@ func updateToolWithOutput(params ToolActionParams) (string, string, string) {
 	if cmd == "" {
 		return constants.StatusFailed, "No update command found for " + params.Tool, ""
 	}
+	cmd = repoCommand(params.State, params.Method, params.Tool, cmd)
 
-	ctx := params.State.GetCancelContext()
-	var result *executor.CommandResult
+	ctx := toolContext(params.State, params.Tool)
 
-	// Use sudo password only for APT and Curl methods
-	password := params.State.GetSudoPassword()
-	needsSudo := params.Method == "APT" || params.Method == "Curl" || params.Method == "YUM"
-	if password != "" && needsSudo {
-		result = executor.ExecuteWithSudo(ctx, cmd, password, 15*time.Minute)
-	} else {
-		result = executor.ExecuteWithTimeout(ctx, cmd, 15*time.Minute)
+	timeout := params.State.GetTimeouts().Action(params.Method, params.Tool)
+	prepared, errMsg := prepareCommand(ctx, params.State, params.Method, cmd, timeout)
+	if errMsg != "" {
+		return constants.StatusFailed, errMsg, ""
 	}
+	defer prepared.Cleanup()
+	cmd, notice := prepared.Command, prepared.Notice
+
+	result := runPrivileged(ctx, params.State, params.Method, cmd, timeout)
+	result.Output = withNotice(notice, result.Output)
 
 	if result.TimedOut {
-		return constants.StatusFailed, constants.InstallationTimedOut, result.Output
+		return constants.StatusFailed, stoppedMessage(timedOutMessage(timeout), result), result.Output
 	}
 	if result.Cancelled {
-		return constants.StatusFailed, constants.InstallationCancelled, result.Output
+		return constants.StatusFailed, stoppedMessage(constants.InstallationCancelled, result), result.Output
 	}
 	if result.ExitCode != 0 {
 		errMsg := result.GetErrorMessage()
@@ -169,29 +407,32 @@ func updateToolWithOutput(params ToolActionParams) (string, string, string) {
 }
 
 // uninstallToolWithOutput executes uninstall command for a tool
+// A vendor repository lazysetup added is removed with the last tool installed from it
 func uninstallToolWithOutput(params ToolActionParams) (string, string, string) {
 	cmd := commands.GetUninstallCommand(params.Method, params.Tool)
 	if cmd == "" {
 		return constants.StatusFailed, "No uninstall command found for " + params.Tool, ""
 	}
+	cmd = repoCommand(params.State, params.Method, params.Tool, cmd)
 
-	ctx := params.State.GetCancelContext()
-	var result *executor.CommandResult
+	ctx := toolContext(params.State, params.Tool)
 
-	// Use sudo password only for APT and Curl methods
-	password := params.State.GetSudoPassword()
-	needsSudo := params.Method == "APT" || params.Method == "Curl" || params.Method == "YUM"
-	if password != "" && needsSudo {
-		result = executor.ExecuteWithSudo(ctx, cmd, password, 15*time.Minute)
-	} else {
-		result = executor.ExecuteWithTimeout(ctx, cmd, 15*time.Minute)
+	timeout := params.State.GetTimeouts().Action(params.Method, params.Tool)
+	prepared, errMsg := prepareCommand(ctx, params.State, params.Method, cmd, timeout)
+	if errMsg != "" {
+		return constants.StatusFailed, errMsg, ""
 	}
+	defer prepared.Cleanup()
+	cmd, notice := prepared.Command, prepared.Notice
+
+	result := runPrivileged(ctx, params.State, params.Method, cmd, timeout)
+	result.Output = withNotice(notice, result.Output)
 
 	if result.TimedOut {
-		return constants.StatusFailed, constants.InstallationTimedOut, result.Output
+		return constants.StatusFailed, stoppedMessage(timedOutMessage(timeout), result), result.Output
 	}
 	if result.Cancelled {
-		return constants.StatusFailed, constants.InstallationCancelled, result.Output
+		return constants.StatusFailed, stoppedMessage(constants.InstallationCancelled, result), result.Output
 	}
 	if result.ExitCode != 0 {
 		errMsg := result.GetErrorMessage()
@@ -205,5 +446,8 @@ func uninstallToolWithOutput(params ToolActionParams) (string, string, string) {
 		return constants.StatusFailed, errMsg, result.Output
 	}
 
+	if removed := releaseRepo(ctx, params.State, params.Method, params.Tool, timeout); removed != "" {
+		result.Output += removed + "\n"
+	}
 	return constants.StatusSuccess, "", result.Output
 }
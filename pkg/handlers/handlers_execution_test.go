@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -435,3 +437,44 @@ func TestUninstallToolWithOutput_InvalidMethod(t *testing.T) {
 		t.Logf("Invalid method - Status: %s, Error: %s, Output: %s", status, errMsg, output)
 	})
 }
+
+// TestWriteActionLog tests saving an action's output to the log directory.
+// Priority: P2 - Logs must not pile up without bound.
+// Tests writing a log, pruning the oldest beyond the limit and leaving other files alone.
+func TestWriteActionLog(t *testing.T) {
+	t.Run("keeps only the newest logs", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("mine"), 0644)
+		start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		for i := 0; i < 4; i++ {
+			now := start.Add(time.Duration(i) * time.Second)
+			if err := writeActionLog(dir, "install", "output", now, 2); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			path := filepath.Join(dir, "install-"+now.Format("20060102-150405")+".log")
+			os.Chtimes(path, now, now)
+		}
+
+		entries, _ := os.ReadDir(dir)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		want := []string{"install-20260102-030407.log", "install-20260102-030408.log", "notes.txt"}
+		if len(names) != len(want) {
+			t.Fatalf("Expected %v, got %v", want, names)
+		}
+		for i := range want {
+			if names[i] != want[i] {
+				t.Errorf("Expected %v, got %v", want, names)
+				break
+			}
+		}
+	})
+
+	t.Run("disabled without a directory", func(t *testing.T) {
+		if err := writeActionLog("", "install", "output", time.Now(), 2); err != nil {
+			t.Errorf("Expected nothing to be written, got %v", err)
+		}
+	})
+}
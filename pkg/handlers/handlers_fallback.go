@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/diagnostics"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// shouldFallBack reports whether a failed install moves on to the next method under the policy
+func shouldFallBack(policy, output string) bool {
+	switch policy {
+	case config.FallbackAnyFailure:
+		return true
+	case config.FallbackNotFound:
+		return diagnostics.IsPackageNotFound(output)
+	default:
+		return false
+	}
+}
+
+// nextFallbackMethod returns the next untried method in the fallback chain that has an install
+// command for the tool and is available here, or "" when the chain is exhausted
+// Methods that need a sudo password are skipped when the batch did not ask for one
+func nextFallbackMethod(state *models.State, tool string, tried map[string]bool, available func(string) bool) string {
+	for _, method := range state.GetFallbackMethods() {
+		if tried[method] {
+			continue
+		}
+		if needsSudoPassword(state, constants.ToolActionInstall, method, tool) && state.GetSudoPassword() == "" {
+			continue
+		}
+		if hasActionCommand(state, constants.ToolActionInstall, method, tool) && available(method) {
+			return method
+		}
+	}
+	return ""
+}
+
+// installWithFallback installs a tool with its resolved method and, when that fails in a way the
+// fallback policy covers, retries with the next methods in the fallback chain
+// Each method's pre-flight command runs through flights before the method's install
+// Returns: (status, errorMsg, output, method, retries) where method is the last method tried
+// and retries counts retries across every method
+func installWithFallback(state *models.State, tool string, available func(string) bool, flights *batchFlights) (string, string, string, string, int) {
+	method := resolveToolMethod(state, constants.ToolActionInstall, tool, available)
+	flights.preflight(method)
+	status, errMsg, output, retries := installToolWithRetry(state, method, tool)
+
+	policy := state.GetFallbackPolicy()
+	tried := map[string]bool{method: true}
+	ctx := state.GetToolContext(tool)
+	for status != constants.StatusSuccess && !state.GetAbortInstallation() && ctx.Err() == nil && shouldFallBack(policy, output+errMsg) {
+		next := nextFallbackMethod(state, tool, tried, available)
+		if next == "" {
+			break
+		}
+		tried[next] = true
+
+		var nextOutput string
+		var nextRetries int
+		notice := fmt.Sprintf(constants.FallbackNotice, method, next)
+		state.SetToolLastLine(tool, notice)
+		flights.preflight(next)
+		status, errMsg, nextOutput, nextRetries = installToolWithRetry(state, next, tool)
+		output += "\n" + notice + "\n" + nextOutput
+		method = next
+		retries += nextRetries
+	}
+
+	return status, errMsg, output, method, retries
+}
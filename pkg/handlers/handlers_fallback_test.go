@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/diagnostics"
+	"github.com/youpele52/lazysetup/pkg/executor"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// TestShouldFallBack tests when a failed install moves on to the next method.
+// Priority: P1 - Falling back on every failure can mask real errors, never falling back leaves tools uninstalled.
+// Tests package-not-found detection under each fallback policy.
+func TestShouldFallBack(t *testing.T) {
+	notFound := "Reading package lists...\nE: Unable to locate package lazygit"
+	networkError := "curl: (6) Could not resolve host: github.com"
+
+	t.Run("not-found policy only falls back for missing packages", func(t *testing.T) {
+		if !shouldFallBack(config.FallbackNotFound, notFound) {
+			t.Error("Expected fallback for a missing APT package")
+		}
+		if shouldFallBack(config.FallbackNotFound, networkError) {
+			t.Error("Expected no fallback for a network error")
+		}
+	})
+
+	t.Run("any-failure policy always falls back", func(t *testing.T) {
+		if !shouldFallBack(config.FallbackAnyFailure, networkError) {
+			t.Error("Expected fallback for any failure")
+		}
+	})
+
+	t.Run("off and missing policies never fall back after running", func(t *testing.T) {
+		if shouldFallBack(config.FallbackOff, notFound) || shouldFallBack(config.FallbackMissing, notFound) {
+			t.Error("Expected no fallback")
+		}
+	})
+
+}
+
+// TestNextFallbackMethod tests picking the next method after a failed install.
+// Priority: P1 - The fallback chain must not loop or hit methods that cannot run.
+// Tests skipping tried, unavailable and sudo-only methods.
+func TestNextFallbackMethod(t *testing.T) {
+	allAvailable := func(string) bool { return true }
+
+	t.Run("skips methods already tried", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSudoPassword("secret")
+
+		got := nextFallbackMethod(state, "lazygit", map[string]bool{"APT": true}, allAvailable)
+		if got != "Homebrew" {
+			t.Errorf("Expected Homebrew, got %s", got)
+		}
+	})
+
+	t.Run("skips sudo methods when no password was given", func(t *testing.T) {
+		state := models.NewState()
+
+		got := nextFallbackMethod(state, "lazygit", map[string]bool{"Homebrew": true}, allAvailable)
+		if got != "Curl" {
+			t.Errorf("Expected Curl, got %s", got)
+		}
+	})
+
+	t.Run("returns empty when the chain is exhausted", func(t *testing.T) {
+		state := models.NewState()
+		tried := map[string]bool{"APT": true, "Homebrew": true, "Curl": true}
+
+		if got := nextFallbackMethod(state, "lazygit", tried, allAvailable); got != "" {
+			t.Errorf("Expected no method, got %s", got)
+		}
+	})
+}
+
+// TestDiagnoseFailure tests classification of failed actions in the handlers.
+// Priority: P2 - Timeouts are reported through the error message, not the output.
+// Tests timeout, cancellation and output-based classification.
+func TestDiagnoseFailure(t *testing.T) {
+	t.Run("timeout message is classified as timeout", func(t *testing.T) {
+		if got := diagnoseFailure(constants.InstallationTimedOut, "").Category; got != diagnostics.CategoryTimeout {
+			t.Errorf("Expected %q, got %q", diagnostics.CategoryTimeout, got)
+		}
+	})
+
+	t.Run("timeout naming its limit is classified as timeout", func(t *testing.T) {
+		if got := diagnoseFailure(timedOutMessage(45*time.Minute), "").Category; got != diagnostics.CategoryTimeout {
+			t.Errorf("Expected %q, got %q", diagnostics.CategoryTimeout, got)
+		}
+	})
+
+	t.Run("cancellation is not retryable", func(t *testing.T) {
+		if diagnoseFailure(constants.InstallationCancelled, "").Retryable {
+			t.Error("Expected a cancelled install not to be retried")
+		}
+	})
+
+	t.Run("cancellation leaving processes running is still a cancellation", func(t *testing.T) {
+		msg := stoppedMessage(constants.InstallationCancelled, &executor.CommandResult{Cancelled: true, Orphaned: true})
+		if !strings.Contains(msg, "still running") {
+			t.Errorf("Expected the message to mention running processes, got %q", msg)
+		}
+		if got := diagnoseFailure(msg, "").Category; got != diagnostics.CategoryCancelled {
+			t.Errorf("Expected %q, got %q", diagnostics.CategoryCancelled, got)
+		}
+	})
+
+	t.Run("output is classified", func(t *testing.T) {
+		got := diagnoseFailure("E: Could not get lock", "E: Could not get lock /var/lib/dpkg/lock-frontend")
+		if got.Category != diagnostics.CategoryLockHeld || !got.Retryable {
+			t.Errorf("Expected retryable lock failure, got %+v", got)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// batchFlights runs the pre-flight and post-flight commands of the methods a batch uses
+// A method's pre-flight runs at most once per batch, before its first tool command; other
+// tools of the method wait for it. A failed pre-flight is shown but does not stop the tools,
+// since a package index that is only partly refreshed still serves most installs
+type batchFlights struct {
+	state  *models.State
+	action string
+
+	mu   sync.Mutex
+	once map[string]*sync.Once // Pre-flight guard per method
+	used []string              // Methods in the order their first tool started
+}
+
+func newBatchFlights(state *models.State, action string) *batchFlights {
+	return &batchFlights{state: state, action: action, once: make(map[string]*sync.Once)}
+}
+
+// preflight runs a method's pre-flight command unless this batch already ran it
+// Blocks while another tool of the method is running it
+func (f *batchFlights) preflight(method string) {
+	if f == nil || method == "" {
+		return
+	}
+	f.mu.Lock()
+	once, ok := f.once[method]
+	if !ok {
+		once = &sync.Once{}
+		f.once[method] = once
+		f.used = append(f.used, method)
+	}
+	f.mu.Unlock()
+
+	once.Do(func() {
+		f.run(models.StagePreflight, method, f.state.GetFlights().PreflightCommand(method, f.action))
+	})
+}
+
+// postflight runs the post-flight command of every method the batch ran a tool with
+// Nothing runs once the action has been cancelled
+func (f *batchFlights) postflight() {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	used := append([]string(nil), f.used...)
+	f.mu.Unlock()
+
+	for _, method := range used {
+		if f.state.GetAbortInstallation() || f.state.GetCancelContext().Err() != nil {
+			return
+		}
+		f.run(models.StagePostflight, method, f.state.GetFlights().PostflightCommand(method, f.action))
+	}
+}
+
+// run executes a flight command, escalated like the method's tool commands, and records it
+// in the progress dashboard and the action's output
+func (f *batchFlights) run(stage models.FlightStage, method, cmd string) {
+	if cmd == "" {
+		return
+	}
+	f.state.StartFlightStep(stage, method, time.Now().Unix())
+	result := runPrivileged(f.state.GetCancelContext(), f.state, method, cmd, f.state.GetTimeouts().Action(method, ""))
+	f.state.FinishFlightStep(stage, method, result.IsSuccess(), time.Now().Unix())
+
+	output := result.Output
+	if !result.IsSuccess() {
+		output += result.GetErrorMessage() + "\n"
+	}
+	f.state.AppendInstallOutput("Method: " + method + " (" + string(stage) + ": " + cmd + ")\n" + output + "\n")
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// TestBatchFlights tests running pre-flight and post-flight commands around a batch.
+// Priority: P1 - The index refresh must run once per batch, not once per tool.
+// Tests once-per-method pre-flights, post-flights for used methods and cancelled batches.
+func TestBatchFlights(t *testing.T) {
+	newState := func() *models.State {
+		state := models.NewState()
+		state.Flights = config.Flights{
+			Preflight:  map[string]string{"Homebrew": "echo refreshed", "Scoop": ""},
+			Postflight: map[string]string{"Homebrew": "echo cleaned", "Scoop": ""},
+		}
+		return state
+	}
+
+	t.Run("pre-flight runs once per method", func(t *testing.T) {
+		state := newState()
+		flights := newBatchFlights(state, constants.ToolActionInstall)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				flights.preflight("Homebrew")
+			}()
+		}
+		wg.Wait()
+
+		steps := state.GetFlightSteps()
+		if len(steps) != 1 || steps[0].Stage != models.StagePreflight || steps[0].Phase != models.PhaseSucceeded {
+			t.Fatalf("Expected one successful pre-flight, got %+v", steps)
+		}
+		if strings.Count(state.GetInstallOutput(), "\nrefreshed\n") != 1 {
+			t.Errorf("Expected the pre-flight output once, got %q", state.GetInstallOutput())
+		}
+	})
+
+	t.Run("post-flight runs for methods that ran", func(t *testing.T) {
+		state := newState()
+		flights := newBatchFlights(state, constants.ToolActionInstall)
+		flights.preflight("Homebrew")
+		flights.preflight("Scoop")
+		flights.postflight()
+
+		steps := state.GetFlightSteps()
+		if len(steps) != 2 || steps[1].Stage != models.StagePostflight || steps[1].Method != "Homebrew" {
+			t.Errorf("Expected Homebrew pre-flight and post-flight only, got %+v", steps)
+		}
+	})
+
+	t.Run("nothing runs for checks", func(t *testing.T) {
+		state := newState()
+		flights := newBatchFlights(state, constants.ToolActionCheck)
+		flights.preflight("Homebrew")
+		flights.postflight()
+
+		if steps := state.GetFlightSteps(); len(steps) != 0 {
+			t.Errorf("Expected no flight commands, got %+v", steps)
+		}
+	})
+
+	t.Run("cancelled batches skip the post-flight", func(t *testing.T) {
+		state := newState()
+		flights := newBatchFlights(state, constants.ToolActionUninstall)
+		flights.preflight("Homebrew")
+		state.CancelFunc()
+		flights.postflight()
+
+		if steps := state.GetFlightSteps(); len(steps) != 0 {
+			t.Errorf("Expected no post-flight after cancelling, got %+v", steps)
+		}
+	})
+}
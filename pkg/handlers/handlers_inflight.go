@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"github.com/jesseduffield/gocui"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// actionRunning reports whether a tool action is in progress
+func actionRunning(state *models.State) bool {
+	return state.GetInstallStartTime() > 0 && !state.GetInstallationDone()
+}
+
+// inFlightKeysActive reports whether the Status panel's running tools list takes keys
+// It does while an action runs and the Status panel is focused, outside popups and search
+func inFlightKeysActive(state *models.State) bool {
+	if state.GetShowSudoConfirm() || state.GetShowRollbackConfirm() || state.GetIsSearchMode() {
+		return false
+	}
+	return state.GetCurrentPage() == models.PageMultiPanel &&
+		state.GetActivePanel() == models.PanelStatus &&
+		actionRunning(state)
+}
+
+// inFlightList returns the running tools in the order the dashboard draws them: selection
+// order, then any running tool without a dashboard row, longest running first
+func inFlightList(state *models.State) []string {
+	running := state.GetInFlightTools()
+	isRunning := make(map[string]bool, len(running))
+	for _, tool := range running {
+		isRunning[tool] = true
+	}
+	tools := make([]string, 0, len(running))
+	for _, row := range state.GetToolProgress() {
+		if isRunning[row.Tool] {
+			tools = append(tools, row.Tool)
+			delete(isRunning, row.Tool)
+		}
+	}
+	for _, tool := range running {
+		if isRunning[tool] {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// inFlightCursor returns the cursor into a running tools list of n entries
+// The stored cursor may point past the end once tools finish, so it is read clamped
+func inFlightCursor(state *models.State, n int) int {
+	cursor := state.GetInFlightCursor()
+	if cursor >= n {
+		cursor = n - 1
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	return cursor
+}
+
+// SelectedInFlightTool returns the running tool under the Status panel cursor, or ""
+func SelectedInFlightTool(state *models.State) string {
+	tools := inFlightList(state)
+	if len(tools) == 0 {
+		return ""
+	}
+	return tools[inFlightCursor(state, len(tools))]
+}
+
+// MoveInFlightCursor moves the running tools cursor by delta, staying within the list
+// Returns false when the list is not taking keys so the caller can scroll instead
+func MoveInFlightCursor(state *models.State, delta int) bool {
+	if !inFlightKeysActive(state) {
+		return false
+	}
+	tools := inFlightList(state)
+	cursor := inFlightCursor(state, len(tools)) + delta
+	if cursor >= len(tools) {
+		cursor = len(tools) - 1
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	state.SetInFlightCursor(cursor)
+	return true
+}
+
+// CancelSelectedTool cancels the running tool under the Status panel cursor ('x' key)
+// The other tools keep running; the cancelled one is reported as cancelled
+func CancelSelectedTool(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if !inFlightKeysActive(state) {
+			return nil
+		}
+		if tool := SelectedInFlightTool(state); tool != "" {
+			state.CancelTool(tool)
+		}
+		return nil
+	}
+}
+
+// ToggleQueuePause stops or resumes starting queued tools while an action runs ('p' key)
+// Tools already running are not affected
+func ToggleQueuePause(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if state.GetShowSudoConfirm() || state.GetShowRollbackConfirm() || state.GetIsSearchMode() {
+			return nil
+		}
+		if state.GetCurrentPage() == models.PageMultiPanel && actionRunning(state) {
+			state.ToggleQueuePaused()
+		}
+		return nil
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// TestWaitForQueueSlot tests when queued tools may start.
+// Priority: P1 - A paused queue must hold tools back without blocking an abort.
+// Tests the parallelism limit, pausing and aborting while paused.
+func TestWaitForQueueSlot(t *testing.T) {
+	t.Run("starts while slots are free", func(t *testing.T) {
+		state := models.NewState()
+		slots := make(chan struct{}, 1)
+		if !waitForQueueSlot(state, slots) {
+			t.Fatal("Expected a free slot")
+		}
+		if len(slots) != 1 {
+			t.Errorf("Expected the slot to be taken, got %d", len(slots))
+		}
+	})
+
+	t.Run("paused queue waits until resumed", func(t *testing.T) {
+		state := models.NewState()
+		state.SetQueuePaused(true)
+		slots := make(chan struct{}, 1)
+
+		started := make(chan bool)
+		go func() { started <- waitForQueueSlot(state, slots) }()
+
+		select {
+		case <-started:
+			t.Fatal("Expected the paused queue to hold the tool back")
+		case <-time.After(3 * queuePollInterval):
+		}
+
+		state.SetQueuePaused(false)
+		select {
+		case ok := <-started:
+			if !ok {
+				t.Error("Expected the tool to start after resuming")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the tool to start after resuming")
+		}
+	})
+
+	t.Run("abort ends the wait", func(t *testing.T) {
+		state := models.NewState()
+		state.SetQueuePaused(true)
+		slots := make(chan struct{}, 1)
+
+		started := make(chan bool)
+		go func() { started <- waitForQueueSlot(state, slots) }()
+		state.CancelInstallations()
+
+		select {
+		case ok := <-started:
+			if ok {
+				t.Error("Expected no start after abort")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected abort to end the wait")
+		}
+	})
+}
+
+// TestCancelSelectedTool tests cancelling one running tool from the Status panel.
+// Priority: P1 - The 'x' key must cancel exactly the selected tool.
+// Tests the selected tool is cancelled in dashboard order, clamping, and keys outside the Status panel.
+func TestCancelSelectedTool(t *testing.T) {
+	setup := func() *models.State {
+		state := models.NewState()
+		state.SetInstallStartTime(time.Now().Unix())
+		state.SetActivePanel(models.PanelStatus)
+		state.SetToolStartTime("python3", 100)
+		state.SetToolStartTime("git", 200)
+		return state
+	}
+
+	t.Run("cancels only the selected tool", func(t *testing.T) {
+		state := setup()
+		python := state.StartToolContext("python3")
+		git := state.StartToolContext("git")
+
+		MoveInFlightCursor(state, 1)
+		if err := CancelSelectedTool(state)(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if git.Err() == nil {
+			t.Error("Expected git to be cancelled")
+		}
+		if python.Err() != nil {
+			t.Error("Expected python3 to keep running")
+		}
+	})
+
+	t.Run("cursor follows the dashboard order", func(t *testing.T) {
+		state := setup()
+		// Selected first, but started after python3
+		state.InitToolProgress([]string{"git", "python3"})
+		python := state.StartToolContext("python3")
+		git := state.StartToolContext("git")
+
+		if got := SelectedInFlightTool(state); got != "git" {
+			t.Fatalf("Expected the first dashboard row, git, got %q", got)
+		}
+		MoveInFlightCursor(state, 1)
+		if err := CancelSelectedTool(state)(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if python.Err() == nil || git.Err() != nil {
+			t.Error("Expected only python3, the second dashboard row, to be cancelled")
+		}
+	})
+
+	t.Run("finished tools do not move the cursor", func(t *testing.T) {
+		state := setup()
+		state.StartToolContext("python3")
+		state.StartToolContext("git")
+		MoveInFlightCursor(state, 1)
+		state.FinishToolContext("git")
+
+		if got := SelectedInFlightTool(state); got != "python3" {
+			t.Errorf("Expected the cursor clamped to python3, got %q", got)
+		}
+		if state.GetInFlightCursor() != 1 {
+			t.Errorf("Expected reading the selection to leave the cursor alone, got %d", state.GetInFlightCursor())
+		}
+	})
+
+	t.Run("ignored outside the Status panel", func(t *testing.T) {
+		state := setup()
+		state.SetActivePanel(models.PanelTools)
+		python := state.StartToolContext("python3")
+
+		if err := CancelSelectedTool(state)(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if python.Err() != nil {
+			t.Error("Expected no cancellation outside the Status panel")
+		}
+	})
+}
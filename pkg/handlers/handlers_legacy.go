@@ -55,7 +55,7 @@ func StartInstallation(state *models.State) func(*gocui.Gui, *gocui.View) error
 						defer wg.Done()
 						startTime := time.Now().Unix()
 						state.SetToolStartTime(toolName, startTime)
-						status, errMsg, output := installToolWithRetry(state, state.SelectedMethod, toolName)
+						status, errMsg, output, retries := installToolWithRetry(state, state.SelectedMethod, toolName)
 
 						mu.Lock()
 						state.AppendInstallOutput("Tool: " + toolName + "\n" + output + "\n")
@@ -67,7 +67,7 @@ func StartInstallation(state *models.State) func(*gocui.Gui, *gocui.View) error
 							Success:  status == constants.StatusSuccess,
 							Error:    errMsg,
 							Duration: duration,
-							Retries:  0,
+							Retries:  retries,
 						}
 						resultsChan <- result
 					}(tool)
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/youpele52/lazysetup/pkg/commands"
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/executor"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// CycleToolMethod moves the tool under the cursor to the next package manager ('m' key)
+// Cycles through every method and back to the selected one; only active in the Tools panel
+func CycleToolMethod(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if state.GetShowSudoConfirm() || state.GetShowRollbackConfirm() || state.GetIsSearchMode() {
+			return nil
+		}
+		if state.GetCurrentPage() != models.PageMultiPanel || state.GetActivePanel() != models.PanelTools {
+			return nil
+		}
+		if tool, ok := toolUnderCursor(state); ok {
+			state.CycleToolMethod(tool)
+		}
+		return nil
+	}
+}
+
+// toolUnderCursor returns the tool the Tools panel cursor is on, in the filtered list when searching
+func toolUnderCursor(state *models.State) (string, bool) {
+	toolList := state.Tools
+	cursorPos := state.ToolsScroll.Cursor
+	if state.GetIsSearchMode() {
+		toolList = state.GetFilteredTools()
+		cursorPos = state.ToolsSearchScroll.Cursor
+	}
+	if cursorPos < 0 || cursorPos >= len(toolList) {
+		return "", false
+	}
+	return toolList[cursorPos], true
+}
+
+// hasActionCommand reports whether a method has a command to run the action on a tool
+// Installs also require the method to be able to honor the tool's version pin
+func hasActionCommand(state *models.State, action, method, tool string) bool {
+	return actionCommand(state, action, method, tool) != ""
+}
+
+// actionCommand returns the command a method runs for the action on a tool, or "" if it has none
+func actionCommand(state *models.State, action, method, tool string) string {
+	switch action {
+	case constants.ToolActionInstall:
+		cmd, err := commands.GetPinnedInstallCommand(method, tool, state.GetVersionPin(tool))
+		if err != nil {
+			return ""
+		}
+		return cmd
+	case constants.ToolActionUpdate:
+		return commands.GetUpdateCommand(method, tool)
+	case constants.ToolActionUninstall:
+		return commands.GetUninstallCommand(method, tool)
+	}
+	return ""
+}
+
+// candidateMethods lists the methods a tool's action may run with, preferred method first
+// The fallback chain is only consulted when the preferred method has no command for the tool
+// and the fallback policy is not off
+func candidateMethods(state *models.State, action, tool string) []string {
+	preferred := state.GetPreferredMethod(tool)
+	if hasActionCommand(state, action, preferred, tool) || state.GetFallbackPolicy() == config.FallbackOff {
+		return []string{preferred}
+	}
+
+	candidates := []string{preferred}
+	for _, method := range state.GetFallbackMethods() {
+		if method != preferred {
+			candidates = append(candidates, method)
+		}
+	}
+	return candidates
+}
+
+// resolveToolMethod picks the method to run an action on a tool with
+// Returns the preferred method when it has a command, otherwise the first fallback method that
+// has one and is available on this machine. When nothing qualifies the preferred method is
+// returned so the action fails with its error rather than silently doing nothing
+func resolveToolMethod(state *models.State, action, tool string, available func(string) bool) string {
+	candidates := candidateMethods(state, action, tool)
+	for _, method := range candidates[1:] {
+		if hasActionCommand(state, action, method, tool) && available(method) {
+			return method
+		}
+	}
+	return candidates[0]
+}
+
+// batchNeedsSudo reports whether any method the selected tools may run with asks for a sudo password
+func batchNeedsSudo(state *models.State, action string) bool {
+	for tool, selected := range state.GetSelectedTools() {
+		if !selected {
+			continue
+		}
+		for _, method := range candidateMethods(state, action, tool) {
+			if needsSudoPassword(state, action, method, tool) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// needsSudoPassword reports whether a method's command for the action on a tool needs the sudo
+// password popup; that is the case when it is escalated with sudo, root, doas and polkit need none
+func needsSudoPassword(state *models.State, action, method, tool string) bool {
+	return state.GetEscalation().NeedsPassword(method, actionCommand(state, action, method, tool))
+}
+
+// runPrivileged runs a method's command, escalated as the method's privilege policy says
+// Escalation tools that would have to prompt on the terminal fail the command without running it
+func runPrivileged(ctx context.Context, state *models.State, method, cmd string, timeout time.Duration) *executor.CommandResult {
+	escalation := state.GetEscalation()
+	if err := escalation.PromptError(method, cmd); err != nil {
+		return &executor.CommandResult{Output: err.Error(), Error: err, ExitCode: -1}
+	}
+	command, input := escalation.Wrap(method, cmd, state.GetSudoPassword())
+	return executor.ExecuteWithInput(ctx, command, input, timeout)
+}
+
+// methodAvailability caches package manager availability checks for the length of a batch
+type methodAvailability struct {
+	mu      sync.Mutex
+	checked map[string]bool
+	timeout time.Duration // How long each package manager check may run
+}
+
+func newMethodAvailability(timeout time.Duration) *methodAvailability {
+	return &methodAvailability{checked: make(map[string]bool), timeout: timeout}
+}
+
+// isAvailable reports whether a method's check command succeeds, running it at most once
+func (m *methodAvailability) isAvailable(method string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if available, ok := m.checked[method]; ok {
+		return available
+	}
+	status, _ := checkInstallation(method, m.timeout)
+	m.checked[method] = status == constants.StatusAlreadyInstalled
+	return m.checked[method]
+}
+
+// getToolAction maps a selected action to the action name runToolAction expects
+func getToolAction(action models.ActionType) string {
+	switch action {
+	case models.ActionInstall:
+		return constants.ToolActionInstall
+	case models.ActionUpdate:
+		return constants.ToolActionUpdate
+	case models.ActionUninstall:
+		return constants.ToolActionUninstall
+	default:
+		return constants.ToolActionCheck
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/models"
+	"github.com/youpele52/lazysetup/pkg/privilege"
+)
+
+// TestResolveToolMethod tests per-tool method resolution.
+// Priority: P1 - Mixed-method batches must run each tool with the right package manager.
+// Tests overrides, the fallback chain, availability of fallback methods and pins.
+func TestResolveToolMethod(t *testing.T) {
+	allAvailable := func(string) bool { return true }
+
+	t.Run("uses the selected method when it has a command", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("Homebrew")
+
+		if got := resolveToolMethod(state, constants.ToolActionInstall, "git", allAvailable); got != "Homebrew" {
+			t.Errorf("Expected Homebrew, got %s", got)
+		}
+	})
+
+	t.Run("per-tool override wins over the selected method", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("APT")
+		state.SetToolMethod("lazygit", "Curl")
+
+		if got := resolveToolMethod(state, constants.ToolActionInstall, "lazygit", allAvailable); got != "Curl" {
+			t.Errorf("Expected Curl, got %s", got)
+		}
+		if got := resolveToolMethod(state, constants.ToolActionInstall, "git", allAvailable); got != "APT" {
+			t.Errorf("Expected git to keep APT, got %s", got)
+		}
+	})
+
+	t.Run("falls back to the first available method with a command", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("DNF")
+		onlyHomebrew := func(method string) bool { return method == "Homebrew" }
+
+		if got := resolveToolMethod(state, constants.ToolActionInstall, "git", allAvailable); got != "APT" {
+			t.Errorf("Expected APT, got %s", got)
+		}
+		if got := resolveToolMethod(state, constants.ToolActionInstall, "git", onlyHomebrew); got != "Homebrew" {
+			t.Errorf("Expected Homebrew, got %s", got)
+		}
+	})
+
+	t.Run("keeps the preferred method when nothing else qualifies", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("DNF")
+		noneAvailable := func(string) bool { return false }
+
+		if got := resolveToolMethod(state, constants.ToolActionInstall, "git", noneAvailable); got != "DNF" {
+			t.Errorf("Expected DNF, got %s", got)
+		}
+	})
+
+	t.Run("off policy never falls back", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("DNF")
+		state.SetFallbackPolicy(config.FallbackOff)
+
+		if got := resolveToolMethod(state, constants.ToolActionInstall, "git", allAvailable); got != "DNF" {
+			t.Errorf("Expected DNF, got %s", got)
+		}
+	})
+
+	t.Run("falls back when the method cannot honor the pin", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("Pacman")
+		state.SetVersionPin("terraform", "1.9.x")
+
+		if got := resolveToolMethod(state, constants.ToolActionInstall, "terraform", allAvailable); got != "APT" {
+			t.Errorf("Expected APT, got %s", got)
+		}
+	})
+}
+
+// TestBatchNeedsSudo tests whether a mixed-method batch asks for a sudo password.
+// Priority: P1 - Missing the prompt makes APT installs fail, an extra prompt is an annoyance.
+// Tests overrides and fallback methods that need sudo.
+func TestBatchNeedsSudo(t *testing.T) {
+	t.Run("Homebrew batch does not need sudo", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("Homebrew")
+		state.SetSelectedTools(map[string]bool{"git": true})
+
+		if batchNeedsSudo(state, constants.ToolActionInstall) {
+			t.Error("Expected no sudo for Homebrew")
+		}
+	})
+
+	t.Run("APT override needs sudo", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("Homebrew")
+		state.SetSelectedTools(map[string]bool{"git": true})
+		state.SetToolMethod("git", "APT")
+
+		if !batchNeedsSudo(state, constants.ToolActionInstall) {
+			t.Error("Expected sudo for an APT override")
+		}
+	})
+
+	t.Run("possible APT fallback needs sudo", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("DNF")
+		state.SetSelectedTools(map[string]bool{"git": true})
+
+		if !batchNeedsSudo(state, constants.ToolActionInstall) {
+			t.Error("Expected sudo when the fallback chain includes APT")
+		}
+	})
+
+	t.Run("Pacman batch needs sudo", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("Pacman")
+		state.SetSelectedTools(map[string]bool{"git": true})
+
+		if !batchNeedsSudo(state, constants.ToolActionInstall) {
+			t.Error("Expected sudo for Pacman")
+		}
+	})
+
+	t.Run("no password as root", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("APT")
+		state.SetSelectedTools(map[string]bool{"git": true})
+		state.SetEscalation(privilege.Escalation{Tool: privilege.ToolSudo, Root: true})
+
+		if batchNeedsSudo(state, constants.ToolActionInstall) {
+			t.Error("Expected no sudo password when running as root")
+		}
+	})
+
+	t.Run("no password with doas", func(t *testing.T) {
+		state := models.NewState()
+		state.SetSelectedMethod("APT")
+		state.SetSelectedTools(map[string]bool{"git": true})
+		state.SetEscalation(privilege.Escalation{Tool: privilege.ToolDoas})
+
+		if batchNeedsSudo(state, constants.ToolActionInstall) {
+			t.Error("Expected no sudo password with doas")
+		}
+	})
+}
+
+// TestRunPrivileged tests running a method's command with its escalation.
+// Priority: P1 - pkexec and run0 prompts must not fight the TUI for the terminal.
+// Tests that a polkit tool fails the command without running it.
+func TestRunPrivileged(t *testing.T) {
+	t.Run("polkit tool is refused", func(t *testing.T) {
+		state := models.NewState()
+		state.SetEscalation(privilege.Escalation{Tool: privilege.ToolRun0})
+
+		result := runPrivileged(context.Background(), state, "APT", "apt-get install -y git", time.Second)
+		if result.IsSuccess() || !strings.Contains(result.GetErrorMessage(), "polkit") {
+			t.Errorf("Expected the command to be refused, got %+v", result)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// ShutdownTimeout bounds how long quitting waits for cancelled actions to stop; it leaves
+// room for the executor's SIGTERM grace period before SIGKILL
+const ShutdownTimeout = 15 * time.Second
+
+// goAction runs an action in the background, counted as running until it returns so
+// quitting can cancel it and wait for it
+func goAction(state *models.State, action func()) {
+	state.BeginAction()
+	go func() {
+		defer state.EndAction()
+		action()
+	}()
+}
+
+// Quit closes the TUI, first asking for confirmation while actions are running, since
+// stopping apt-get or make install midway can leave the system half-configured
+func Quit(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if state.GetRunningActions() > 0 {
+			state.SetShowQuitConfirm(true)
+			return nil
+		}
+		return gocui.ErrQuit
+	}
+}
+
+// ConfirmQuit handles Enter on the quit confirmation popup: it closes the TUI, and
+// Shutdown then cancels the running actions and waits for them
+func ConfirmQuit(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if !state.GetShowQuitConfirm() {
+			return nil
+		}
+		state.SetShowQuitConfirm(false)
+		return gocui.ErrQuit
+	}
+}
+
+// CancelQuit handles Esc on the quit confirmation popup, leaving the actions running
+func CancelQuit(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		state.SetShowQuitConfirm(false)
+		return nil
+	}
+}
+
+// QuitConfirmMessage returns the quit popup's text, naming the running tools
+func QuitConfirmMessage(state *models.State) string {
+	running := constants.QuitConfirmUnnamed
+	if tools := state.GetInFlightTools(); len(tools) > 0 {
+		running = strings.Join(tools, ", ")
+	}
+	return fmt.Sprintf(constants.QuitConfirmMessage, running, ShutdownTimeout)
+}
+
+// Shutdown stops what is still running once the TUI has closed: it cancels running
+// actions, waits up to timeout for their commands to exit and writes a summary of the
+// last action to w
+// Returns false when actions were still running at the deadline
+func Shutdown(state *models.State, w io.Writer, timeout time.Duration) bool {
+	stopped := true
+	if state.GetRunningActions() > 0 {
+		fmt.Fprintf(w, constants.ShutdownWaiting, timeout)
+		state.SetAbortInstallation(true)
+		state.CancelInstallations()
+		stopped = state.WaitForActions(timeout)
+	}
+	writeSummary(w, state)
+	if !stopped {
+		running := constants.QuitConfirmUnnamed
+		if tools := state.GetInFlightTools(); len(tools) > 0 {
+			running = strings.Join(tools, ", ")
+		}
+		fmt.Fprintf(w, constants.ShutdownTimedOut, timeout, running)
+	}
+	return stopped
+}
+
+// writeSummary writes the outcome of every tool in the last action, nothing when no
+// action ran
+func writeSummary(w io.Writer, state *models.State) {
+	progress := state.GetToolProgress()
+	if len(progress) == 0 {
+		return
+	}
+	results := make(map[string]models.InstallResult)
+	for _, result := range state.GetInstallResults() {
+		if !result.RolledBack {
+			results[result.Tool] = result
+		}
+	}
+
+	fmt.Fprintf(w, constants.SummaryTitle, state.GetLastAction())
+	succeeded, failed, unfinished := 0, 0, 0
+	for _, row := range progress {
+		result, done := results[row.Tool]
+		switch {
+		case done && result.Success:
+			succeeded++
+			fmt.Fprintf(w, constants.SummarySucceeded, row.Tool, result.Duration)
+		case done:
+			failed++
+			fmt.Fprintf(w, constants.SummaryFailed, row.Tool, lastLine(result.Error))
+		case row.Phase == models.PhaseQueued:
+			unfinished++
+			fmt.Fprintf(w, constants.SummaryNotStarted, row.Tool)
+		default:
+			unfinished++
+			fmt.Fprintf(w, constants.SummaryStillRunning, row.Tool)
+		}
+	}
+	fmt.Fprintf(w, constants.SummaryTotals, succeeded, failed, unfinished)
+}
+
+// lastLine returns the last non-blank line of s, keeping summary lines to one line each
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
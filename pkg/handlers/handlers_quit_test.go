@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/youpele52/lazysetup/pkg/keymap"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// TestQuit tests quitting while actions run.
+// Priority: P1 - Quitting mid apt-get or make install can leave the system half-configured.
+// Tests the confirmation popup, its keys, cancelling on shutdown and the exit summary.
+func TestQuit(t *testing.T) {
+	t.Run("quits at once when nothing runs", func(t *testing.T) {
+		state := models.NewState()
+		if err := Quit(state)(nil, nil); err != gocui.ErrQuit {
+			t.Errorf("Expected ErrQuit, got %v", err)
+		}
+	})
+
+	t.Run("asks first while an action runs", func(t *testing.T) {
+		state := models.NewState()
+		state.BeginAction()
+		if err := Quit(state)(nil, nil); err != nil || !state.GetShowQuitConfirm() {
+			t.Fatalf("Expected the quit popup, got err=%v shown=%v", err, state.GetShowQuitConfirm())
+		}
+
+		d, ran := newTestDispatcher(state)
+		if err := d.Dispatch(nil, nil, keymap.Char('c')); err != nil || len(*ran) != 0 {
+			t.Errorf("Expected other keys to be ignored, got %v and %v", err, *ran)
+		}
+		if err := d.Dispatch(nil, nil, keymap.Special(gocui.KeyEsc)); err != nil || state.GetShowQuitConfirm() {
+			t.Errorf("Expected Esc to close the popup, got %v", err)
+		}
+
+		state.SetShowQuitConfirm(true)
+		if err := d.Dispatch(nil, nil, keymap.Special(gocui.KeyCtrlC)); err != gocui.ErrQuit {
+			t.Errorf("Expected the quit key to confirm, got %v", err)
+		}
+	})
+
+	t.Run("shutdown cancels running actions and summarizes them", func(t *testing.T) {
+		state := models.NewState()
+		state.SetLastAction("install")
+		state.InitToolProgress([]string{"git", "nvim", "fzf"})
+		state.MarkToolRunning("git", 1)
+		state.MarkToolDone("git", true, 2)
+		state.AddInstallResult(models.InstallResult{Tool: "git", Success: true, Duration: 1})
+		state.MarkToolRunning("nvim", 1)
+		ctx := state.GetCancelContext()
+		goAction(state, func() {
+			<-ctx.Done()
+			state.AddInstallResult(models.InstallResult{Tool: "nvim", Error: "Installation was cancelled"})
+		})
+
+		var out bytes.Buffer
+		if !Shutdown(state, &out, 5*time.Second) {
+			t.Fatal("Expected the action to stop before the deadline")
+		}
+		for _, want := range []string{"lazysetup install results", "✓ git", "✗ nvim: Installation was cancelled", "- fzf: not started", "1 succeeded, 1 failed, 1 not finished"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Expected %q in summary:\n%s", want, out.String())
+			}
+		}
+	})
+
+	t.Run("shutdown gives up at the deadline", func(t *testing.T) {
+		state := models.NewState()
+		block := make(chan struct{})
+		defer close(block)
+		goAction(state, func() { <-block })
+
+		var out bytes.Buffer
+		if Shutdown(state, &out, 100*time.Millisecond) {
+			t.Error("Expected the deadline to pass with the action still running")
+		}
+		if !strings.Contains(out.String(), "Gave up waiting") {
+			t.Errorf("Expected a timeout notice, got %q", out.String())
+		}
+	})
+}
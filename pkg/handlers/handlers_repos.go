@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/commands"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/models"
+	"github.com/youpele52/lazysetup/pkg/repos"
+)
+
+// repoCheckTimeout bounds asking the package manager whether it already has a package
+const repoCheckTimeout = 30 * time.Second
+
+// keyClient downloads vendor signing keys; requests are bounded by the action's timeout
+var keyClient = &http.Client{}
+
+// vendorRepo adds the vendor repository of an APT, DNF or YUM tool when the configured
+// repositories do not have its package, verifying the signing key's fingerprint first
+// Returns the command to install the tool with and a line for the output about the added
+// repository; errMsg is set when it could not be added, with the output of the setup command
+// in place of the line
+func vendorRepo(ctx context.Context, state *models.State, method, tool, cmd string, timeout time.Duration) (string, string, string) {
+	store := state.GetRepos()
+	repo, ok := repos.Find(method, tool)
+	if store == nil || !ok {
+		return cmd, "", ""
+	}
+	if repos.Available(ctx, method, commands.GetPackageName(tool, method), repoCheckTimeout) {
+		return cmd, "", ""
+	}
+	if repo.Present(method) {
+		return repo.Command(method, tool, cmd), "", ""
+	}
+
+	keyCtx, cancel := context.WithTimeout(ctx, timeout)
+	prepared, err := repos.Prepare(keyCtx, keyClient, repo, method)
+	cancel()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", "", constants.InstallationCancelled
+		}
+		return "", "", fmt.Sprintf(constants.RepoAddFailed, repo.Vendor, err.Error())
+	}
+	defer prepared.Cleanup()
+
+	result := runPrivileged(ctx, state, method, prepared.Command, timeout)
+	switch {
+	case result.TimedOut:
+		return "", result.Output, stoppedMessage(timedOutMessage(timeout), result)
+	case result.Cancelled:
+		return "", result.Output, stoppedMessage(constants.InstallationCancelled, result)
+	case !result.IsSuccess():
+		return "", result.Output, fmt.Sprintf(constants.RepoAddFailed, repo.Vendor, result.GetErrorMessage())
+	}
+
+	store.Add(repos.Record{Name: repo.Name, Vendor: repo.Vendor, Method: method, Files: prepared.Files, AddedAt: time.Now()})
+	// The records are best effort; the repository works without them, it is only not removed later
+	_ = store.Save()
+	return repo.Command(method, tool, cmd), fmt.Sprintf(constants.RepoAdded, repo.Vendor, prepared.Fingerprint), ""
+}
+
+// recordRepoTool records that a tool was installed from the vendor repository lazysetup
+// added for it, so uninstalling the repository's last tool removes the repository
+func recordRepoTool(state *models.State, method, tool string) {
+	repo, ok := repos.Find(method, tool)
+	store := state.GetRepos()
+	if ok && store.AddTool(repo.Name, method, tool) {
+		_ = store.Save()
+	}
+}
+
+// repoCommand rewrites an update or uninstall command for a tool installed from a vendor
+// repository to use the repository's package name
+func repoCommand(state *models.State, method, tool, cmd string) string {
+	if _, ok := state.GetRepos().InstalledFrom(method, tool); !ok {
+		return cmd
+	}
+	repo, ok := repos.Find(method, tool)
+	if !ok {
+		return cmd
+	}
+	return repo.Command(method, tool, cmd)
+}
+
+// releaseRepo forgets that an uninstalled tool came from a vendor repository and removes
+// the repository's files once no tool installed from it is left
+// Returns a line for the output, empty when no repository was removed
+func releaseRepo(ctx context.Context, state *models.State, method, tool string, timeout time.Duration) string {
+	store := state.GetRepos()
+	record, last := store.RemoveTool(method, tool)
+	if record.Name == "" {
+		return ""
+	}
+	if !last {
+		_ = store.Save()
+		return ""
+	}
+
+	result := runPrivileged(ctx, state, method, repos.RemoveCommand(record.Files), timeout)
+	_ = store.Save()
+	if !result.IsSuccess() {
+		return fmt.Sprintf(constants.RepoRemoveFailed, record.Vendor, result.GetErrorMessage())
+	}
+	return fmt.Sprintf(constants.RepoRemoved, record.Vendor)
+}
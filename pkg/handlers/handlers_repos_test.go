@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/models"
+	"github.com/youpele52/lazysetup/pkg/privilege"
+	"github.com/youpele52/lazysetup/pkg/repos"
+)
+
+// TestVendorRepoRecords tests the bookkeeping of vendor repositories around tool actions.
+// Priority: P1 - Uninstall must remove a repository lazysetup added, and only that one.
+// Tests disabled repositories, recording installs, renamed packages and releasing the last tool.
+func TestVendorRepoRecords(t *testing.T) {
+	newState := func() *models.State {
+		state := models.NewState()
+		state.Repos = repos.NewStore("")
+		// Run the removal directly, as in a root container
+		state.SetEscalation(privilege.Escalation{Root: true})
+		return state
+	}
+
+	t.Run("disabled repositories leave the command alone", func(t *testing.T) {
+		state := models.NewState()
+		cmd, notice, errMsg := vendorRepo(context.Background(), state, "APT", "terraform", "apt-get install -y terraform", time.Minute)
+		if cmd != "apt-get install -y terraform" || notice != "" || errMsg != "" {
+			t.Errorf("Unexpected result %q %q %q", cmd, notice, errMsg)
+		}
+	})
+
+	t.Run("installs are recorded only for added repositories", func(t *testing.T) {
+		state := newState()
+		recordRepoTool(state, "DNF", "docker")
+		if _, ok := state.GetRepos().InstalledFrom("DNF", "docker"); ok {
+			t.Error("Expected no record without an added repository")
+		}
+
+		state.GetRepos().Add(repos.Record{Name: "docker", Vendor: "Docker", Method: "DNF"})
+		recordRepoTool(state, "DNF", "docker")
+		if got := repoCommand(state, "DNF", "docker", "dnf remove -y docker"); got != "dnf remove -y docker-ce" {
+			t.Errorf("Expected the repository's package name, got %q", got)
+		}
+		if got := repoCommand(state, "YUM", "docker", "yum remove -y docker"); got != "yum remove -y docker" {
+			t.Errorf("Expected other methods unchanged, got %q", got)
+		}
+	})
+
+	t.Run("last tool removes the repository", func(t *testing.T) {
+		state := newState()
+		dir := t.TempDir()
+		files := []string{filepath.Join(dir, "lazysetup-test.list"), filepath.Join(dir, "lazysetup-test.gpg")}
+		for _, file := range files {
+			os.WriteFile(file, []byte("x"), 0644)
+		}
+		state.GetRepos().Add(repos.Record{Name: "hashicorp", Vendor: "HashiCorp", Method: "APT", Files: files})
+		state.GetRepos().AddTool("hashicorp", "APT", "terraform")
+
+		if got := releaseRepo(context.Background(), state, "APT", "git", time.Minute); got != "" {
+			t.Errorf("Expected nothing released for git, got %q", got)
+		}
+		got := releaseRepo(context.Background(), state, "APT", "terraform", time.Minute)
+		if !strings.Contains(got, "Removed the HashiCorp repository") {
+			t.Errorf("Expected the removal to be reported, got %q", got)
+		}
+		for _, file := range files {
+			if _, err := os.Stat(file); !os.IsNotExist(err) {
+				t.Errorf("Expected %s to be removed", file)
+			}
+		}
+		if len(state.GetRepos().Records()) != 0 {
+			t.Error("Expected the record to be dropped")
+		}
+	})
+}
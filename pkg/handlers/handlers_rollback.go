@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// ToggleTransactionalMode turns rollback-on-failure for install batches on or off ('t' key)
+// Only active in the Tools panel so it never collides with search or password input
+func ToggleTransactionalMode(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if state.GetShowSudoConfirm() || state.GetShowRollbackConfirm() || state.GetIsSearchMode() {
+			return nil
+		}
+		if state.GetCurrentPage() == models.PageMultiPanel && state.GetActivePanel() == models.PanelTools {
+			state.ToggleTransactionalMode()
+		}
+		return nil
+	}
+}
+
+// isToolPresent runs the tool's check command to see whether it is already installed
+// Used as the pre-check that separates newly installed tools from pre-existing ones
+func isToolPresent(params ToolActionParams) bool {
+	status, _, _ := checkToolWithOutput(params)
+	return status == constants.StatusSuccess
+}
+
+// batchHasFailures reports whether any result in the batch failed
+func batchHasFailures(results []models.InstallResult) bool {
+	for _, result := range results {
+		if !result.Success {
+			return true
+		}
+	}
+	return false
+}
+
+// installedWith returns the method the batch installed a tool with
+// Tools may have run with their own override or a fallback method rather than the selected one
+func installedWith(state *models.State, tool string) string {
+	for _, result := range state.GetInstallResults() {
+		if result.Tool == tool && !result.RolledBack && result.Method != "" {
+			return result.Method
+		}
+	}
+	return state.GetSelectedMethod()
+}
+
+// offerRollback shows the rollback popup when a transactional batch partially failed
+// and installed at least one tool that was not present before
+// Returns true if the popup was shown
+func offerRollback(state *models.State) bool {
+	if len(state.GetNewlyInstalled()) == 0 || !batchHasFailures(state.GetInstallResults()) {
+		state.ClearNewlyInstalled()
+		return false
+	}
+	state.SetShowRollbackConfirm(true)
+	return true
+}
+
+// ConfirmRollbackPopup handles Enter key on the rollback confirmation popup
+func ConfirmRollbackPopup(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if !state.GetShowRollbackConfirm() {
+			return nil
+		}
+		state.SetShowRollbackConfirm(false)
+
+		// Re-render the whole batch once the rollback results are in
+		state.SetInstallationDone(false)
+		state.ActionCompletionTime = 0
+		state.LastRenderedResultCount = 0
+
+		goAction(state, func() { runRollback(state) })
+		return nil
+	}
+}
+
+// CancelRollbackPopup handles Esc key on the rollback confirmation popup
+// Keeps the newly installed tools and forgets the rollback candidates
+func CancelRollbackPopup(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if !state.GetShowRollbackConfirm() {
+			return nil
+		}
+		state.SetShowRollbackConfirm(false)
+		state.ClearNewlyInstalled()
+		state.ClearSudoPassword()
+		return nil
+	}
+}
+
+// runRollback uninstalls the tools a failed transactional batch installed, newest first,
+// and appends one RolledBack result per tool to the batch results
+func runRollback(state *models.State) {
+	stopSpinner := startSpinner(state)
+	state.SetRollingBack(true)
+
+	tools := state.GetNewlyInstalled()
+	newestFirst := make([]string, 0, len(tools))
+	for i := len(tools) - 1; i >= 0; i-- {
+		newestFirst = append(newestFirst, tools[i])
+	}
+	state.InitToolProgress(newestFirst)
+	durations := state.GetDurationHistory()
+	for _, tool := range newestFirst {
+		if expected, ok := durations.Expected(constants.ToolActionUninstall, installedWith(state, tool), tool); ok {
+			state.SetToolExpected(tool, expected)
+		}
+	}
+
+	for i := len(tools) - 1; i >= 0; i-- {
+		if state.GetAbortInstallation() {
+			break
+		}
+
+		tool := tools[i]
+		startTime := time.Now().Unix()
+		method := installedWith(state, tool)
+		state.SetToolStartTime(tool, startTime)
+		state.StartToolContext(tool)
+		state.MarkToolRunning(tool, startTime)
+		status, errMsg, output, retries := withRetry(state, tool, func() (string, string, string) {
+			return uninstallToolWithOutput(ToolActionParams{
+				State:  state,
+				Method: method,
+				Tool:   tool,
+			})
+		})
+		state.FinishToolContext(tool)
+		state.AppendInstallOutput("Tool: " + tool + " (rollback)\n" + output + "\n")
+
+		result := models.InstallResult{
+			Tool:       tool,
+			Success:    status == constants.StatusSuccess,
+			Error:      errMsg,
+			Duration:   time.Now().Unix() - startTime,
+			Retries:    retries,
+			Method:     method,
+			RolledBack: true,
+		}
+		state.MarkToolDone(tool, result.Success, time.Now().Unix())
+		if !result.Success {
+			diagnosis := diagnoseFailure(errMsg, output)
+			result.Category = string(diagnosis.Category)
+			result.Hint = diagnosis.Hint
+		}
+		state.AddInstallResult(result)
+	}
+
+	state.ClearNewlyInstalled()
+	state.SetRollingBack(false)
+	state.SetInstallationDone(true)
+	stopSpinner()
+	state.ActionCompletionTime = time.Now().Unix()
+	state.ClearSudoPassword()
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// TestOfferRollback_OnlyOnPartialFailure tests when a transactional batch offers a rollback.
+// Priority: P1 - A rollback must never be offered for a clean batch or when nothing new was installed.
+// Tests the popup flag and candidate list for success, partial failure and no-new-tools batches.
+func TestOfferRollback_OnlyOnPartialFailure(t *testing.T) {
+	t.Run("partial failure with newly installed tools shows popup", func(t *testing.T) {
+		state := models.NewState()
+		state.AddNewlyInstalled("jq")
+		state.AddInstallResult(models.InstallResult{Tool: "jq", Success: true})
+		state.AddInstallResult(models.InstallResult{Tool: "k9s", Success: false})
+
+		if !offerRollback(state) {
+			t.Error("Expected rollback to be offered")
+		}
+		if !state.GetShowRollbackConfirm() {
+			t.Error("Expected rollback popup to be visible")
+		}
+		if got := state.GetNewlyInstalled(); len(got) != 1 || got[0] != "jq" {
+			t.Errorf("Expected candidates [jq], got %v", got)
+		}
+	})
+
+	t.Run("fully successful batch does not offer rollback", func(t *testing.T) {
+		state := models.NewState()
+		state.AddNewlyInstalled("jq")
+		state.AddInstallResult(models.InstallResult{Tool: "jq", Success: true})
+
+		if offerRollback(state) {
+			t.Error("Expected no rollback for a successful batch")
+		}
+		if state.GetShowRollbackConfirm() {
+			t.Error("Expected rollback popup to stay hidden")
+		}
+		if len(state.GetNewlyInstalled()) != 0 {
+			t.Error("Expected candidates to be cleared")
+		}
+	})
+
+	t.Run("failure without newly installed tools does not offer rollback", func(t *testing.T) {
+		state := models.NewState()
+		state.AddInstallResult(models.InstallResult{Tool: "k9s", Success: false})
+
+		if offerRollback(state) {
+			t.Error("Expected no rollback when nothing new was installed")
+		}
+	})
+}
+
+// TestCancelRollbackPopup_KeepsTools tests dismissing the rollback offer.
+// Priority: P2 - Esc must keep the installed tools and drop the sudo password.
+// Tests that the popup hides, candidates are forgotten and the password is cleared.
+func TestCancelRollbackPopup_KeepsTools(t *testing.T) {
+	t.Run("esc hides popup and clears candidates", func(t *testing.T) {
+		state := models.NewState()
+		state.AddNewlyInstalled("jq")
+		state.SetSudoPassword("secret")
+		state.SetShowRollbackConfirm(true)
+
+		_ = CancelRollbackPopup(state)(nil, nil)
+
+		if state.GetShowRollbackConfirm() {
+			t.Error("Expected rollback popup to be hidden")
+		}
+		if len(state.GetNewlyInstalled()) != 0 {
+			t.Error("Expected candidates to be cleared")
+		}
+		if state.GetSudoPassword() != "" {
+			t.Error("Expected sudo password to be cleared")
+		}
+	})
+}
+
+// TestToggleTransactionalMode_ToolsPanelOnly tests the 't' toggle.
+// Priority: P2 - The toggle must not fire while typing a search query.
+// Tests toggling in the Tools panel and ignoring it in search mode.
+func TestToggleTransactionalMode_ToolsPanelOnly(t *testing.T) {
+	t.Run("toggles in tools panel", func(t *testing.T) {
+		state := models.NewState()
+		state.SetActivePanel(models.PanelTools)
+
+		_ = ToggleTransactionalMode(state)(nil, nil)
+		if !state.GetTransactionalMode() {
+			t.Error("Expected transactional mode to be enabled")
+		}
+
+		_ = ToggleTransactionalMode(state)(nil, nil)
+		if state.GetTransactionalMode() {
+			t.Error("Expected transactional mode to be disabled")
+		}
+	})
+
+	t.Run("ignored in search mode", func(t *testing.T) {
+		state := models.NewState()
+		state.SetActivePanel(models.PanelTools)
+		state.SetIsSearchMode(true)
+
+		_ = ToggleTransactionalMode(state)(nil, nil)
+		if state.GetTransactionalMode() {
+			t.Error("Expected transactional mode to stay disabled while searching")
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jesseduffield/gocui"
+	"github.com/youpele52/lazysetup/pkg/constants"
 	"github.com/youpele52/lazysetup/pkg/models"
 	"github.com/youpele52/lazysetup/pkg/updater"
 	"github.com/youpele52/lazysetup/pkg/version"
@@ -22,6 +24,8 @@ func PerformUpdate(state *models.State) {
 }
 
 // ExecuteUpdate performs the actual download and installation
+// The download is checked against the release's SHA256SUMS and smoke-tested before it
+// replaces the executable; the previous binary is kept for `lazysetup self rollback`
 func ExecuteUpdate(state *models.State) {
 	if !state.UpdateAvailable || state.UpdateDownloadURL == "" {
 		state.UpdateMessage = "No update available"
@@ -32,7 +36,12 @@ func ExecuteUpdate(state *models.State) {
 	state.UpdateMessage = fmt.Sprintf("%s Downloading update to %s...", "⠋", state.UpdateVersion)
 	state.UpdateMessageTime = time.Now().Unix()
 
-	err := updater.DownloadAndInstall(state.UpdateDownloadURL)
+	err := updater.DownloadAndInstall(updater.Download{
+		URL:         state.UpdateDownloadURL,
+		Name:        state.UpdateAssetName,
+		ChecksumURL: state.UpdateChecksumURL,
+		Version:     state.UpdateVersion,
+	})
 	if err != nil {
 		state.UpdateMessage = fmt.Sprintf("✗ Update failed: %s", err.Error())
 		state.UpdateMessageTime = time.Now().Unix()
@@ -40,7 +49,53 @@ func ExecuteUpdate(state *models.State) {
 	}
 
 	// Show success message
-	state.UpdateMessage = fmt.Sprintf("✓ Update successful! Restarting with %s...", state.UpdateVersion)
+	state.UpdateMessage = fmt.Sprintf(constants.UpdateInstalled, state.UpdateVersion)
 	state.UpdateMessageTime = time.Now().Unix()
 	state.UpdateAvailable = false
 }
+
+// OpenReleaseNotes shows the notes of the pending releases ('u' key); the update is only
+// installed once the popup is confirmed
+func OpenReleaseNotes(state *models.State) gocui.KeybindingHandler {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if state.UpdateAvailable {
+			state.SetShowReleaseNotes(true)
+		}
+		return nil
+	}
+}
+
+// ConfirmReleaseNotes closes the release notes popup and installs the update
+func ConfirmReleaseNotes(state *models.State) gocui.KeybindingHandler {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if !state.GetShowReleaseNotes() {
+			return nil
+		}
+		state.SetShowReleaseNotes(false)
+		goAction(state, func() { ExecuteUpdate(state) })
+		return nil
+	}
+}
+
+// CloseReleaseNotes closes the release notes popup without updating
+func CloseReleaseNotes(state *models.State) gocui.KeybindingHandler {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		state.SetShowReleaseNotes(false)
+		return nil
+	}
+}
+
+// ScrollReleaseNotes scrolls the release notes popup by delta lines
+func ScrollReleaseNotes(delta int) gocui.KeybindingHandler {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		popup, err := g.View(constants.PopupReleaseNotes)
+		if err != nil {
+			return nil
+		}
+		ox, oy := popup.Origin()
+		if oy+delta < 0 {
+			return nil
+		}
+		return popup.SetOrigin(ox, oy+delta)
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"unicode"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/youpele52/lazysetup/pkg/keymap"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// InputMode decides how a key press is interpreted
+type InputMode int
+
+const (
+	InputNormal       InputMode = iota // Keys trigger the action the keymap binds them to
+	InputSearch                        // Characters are typed into the Tools panel filter
+	InputPassword                      // Characters are typed into the sudo password popup
+	InputConfirm                       // Only Enter and Esc, answering the rollback popup
+	InputReleaseNotes                  // Enter or the update key installs, Esc closes, arrows scroll
+	InputQuitConfirm                   // Enter or the quit key quits, Esc keeps the actions running
+)
+
+// CurrentInputMode returns the input mode for the current state
+// Popups take priority over search, since a popup can open while the Tools panel is filtered
+// The quit popup comes first, as the quit key opens it from any other mode
+func CurrentInputMode(state *models.State) InputMode {
+	switch {
+	case state.GetShowQuitConfirm():
+		return InputQuitConfirm
+	case state.GetShowSudoConfirm():
+		return InputPassword
+	case state.GetShowRollbackConfirm():
+		return InputConfirm
+	case state.GetShowReleaseNotes():
+		return InputReleaseNotes
+	case state.GetIsSearchMode():
+		return InputSearch
+	}
+	return InputNormal
+}
+
+// InputDispatcher routes every key press to exactly one handler based on the input mode,
+// so typing a password or a search query never triggers a panel shortcut
+type InputDispatcher struct {
+	state   *models.State
+	actions map[string]gocui.KeybindingHandler // Handler per keymap action
+}
+
+// NewInputDispatcher creates a dispatcher running actions' handlers for keymap actions
+func NewInputDispatcher(state *models.State, actions map[string]gocui.KeybindingHandler) *InputDispatcher {
+	return &InputDispatcher{state: state, actions: actions}
+}
+
+// Keys returns every key the dispatcher needs to receive: the keymap's keys, all printable
+// characters for typing, and the keys popups and search handle themselves
+func (d *InputDispatcher) Keys() []keymap.Key {
+	seen := make(map[keymap.Key]bool)
+	var keys []keymap.Key
+	add := func(key keymap.Key) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range d.keymap().Bound() {
+		add(key)
+	}
+	for ch := rune(33); ch <= 126; ch++ {
+		add(keymap.Char(ch))
+	}
+	for _, key := range []gocui.Key{gocui.KeySpace, gocui.KeyEnter, gocui.KeyEsc, gocui.KeyBackspace, gocui.KeyBackspace2, gocui.KeyCtrlC} {
+		add(keymap.Special(key))
+	}
+	return keys
+}
+
+// Handler returns the gocui handler for one key
+func (d *InputDispatcher) Handler(key keymap.Key) gocui.KeybindingHandler {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		return d.Dispatch(g, v, key)
+	}
+}
+
+// Dispatch handles a key press according to the input mode
+func (d *InputDispatcher) Dispatch(g *gocui.Gui, v *gocui.View, key keymap.Key) error {
+	switch CurrentInputMode(d.state) {
+	case InputQuitConfirm:
+		return d.quitConfirm(g, v, key)
+	case InputPassword:
+		return d.password(g, v, key)
+	case InputConfirm:
+		return d.confirm(g, v, key)
+	case InputReleaseNotes:
+		return d.releaseNotes(g, v, key)
+	case InputSearch:
+		if d.search(key) {
+			return nil
+		}
+	}
+	return d.run(g, v, key)
+}
+
+// password types into the sudo popup; Enter confirms and Esc cancels whatever the keymap says
+func (d *InputDispatcher) password(g *gocui.Gui, v *gocui.View, key keymap.Key) error {
+	switch {
+	case key.IsChar():
+		d.state.AppendPasswordInput(key.Ch)
+	case key.Key == gocui.KeySpace:
+		d.state.AppendPasswordInput(' ')
+	case isBackspace(key):
+		d.state.BackspacePasswordInput()
+	case key.Key == gocui.KeyEnter:
+		return ConfirmSudoPopup(d.state)(g, v)
+	case key.Key == gocui.KeyEsc:
+		return CancelSudoPopup(d.state)(g, v)
+	case key.Key == gocui.KeyCtrlC:
+		return d.run(g, v, key)
+	}
+	return nil
+}
+
+// confirm answers the rollback popup with Enter or Esc and ignores everything else
+func (d *InputDispatcher) confirm(g *gocui.Gui, v *gocui.View, key keymap.Key) error {
+	switch key.Key {
+	case gocui.KeyEnter:
+		return ConfirmRollbackPopup(d.state)(g, v)
+	case gocui.KeyEsc:
+		return CancelRollbackPopup(d.state)(g, v)
+	case gocui.KeyCtrlC:
+		return d.run(g, v, key)
+	}
+	return nil
+}
+
+// releaseNotes answers the release notes popup: Enter or the update key installs the
+// update, Esc closes the popup and the up and down keys scroll it
+func (d *InputDispatcher) releaseNotes(g *gocui.Gui, v *gocui.View, key keymap.Key) error {
+	switch key.Key {
+	case gocui.KeyEnter:
+		return ConfirmReleaseNotes(d.state)(g, v)
+	case gocui.KeyEsc:
+		return CloseReleaseNotes(d.state)(g, v)
+	}
+	switch action, _ := d.keymap().Action(key); action {
+	case keymap.Update:
+		return ConfirmReleaseNotes(d.state)(g, v)
+	case keymap.Up:
+		return ScrollReleaseNotes(-1)(g, v)
+	case keymap.Down:
+		return ScrollReleaseNotes(1)(g, v)
+	case keymap.Quit:
+		return d.run(g, v, key)
+	}
+	return nil
+}
+
+// quitConfirm answers the quit popup: Enter or the quit key again cancels the running
+// actions and quits, Esc keeps them running
+func (d *InputDispatcher) quitConfirm(g *gocui.Gui, v *gocui.View, key keymap.Key) error {
+	if key.Key == gocui.KeyEsc {
+		return CancelQuit(d.state)(g, v)
+	}
+	if action, _ := d.keymap().Action(key); key.Key == gocui.KeyEnter || action == keymap.Quit {
+		return ConfirmQuit(d.state)(g, v)
+	}
+	return nil
+}
+
+// search types characters into the filter and reports whether it handled the key
+// Keys it leaves alone (arrows, Enter, Space, ...) keep their keymap action. The search
+// key closes the filter unless it is a character tool names contain, which is typed instead
+func (d *InputDispatcher) search(key keymap.Key) bool {
+	switch {
+	case isBackspace(key):
+		backspaceSearch(d.state)
+		return true
+	case key.Key == gocui.KeyEsc:
+		ExitSearch(d.state)
+		return true
+	case key.IsChar():
+		if action, _ := d.keymap().Action(key); action == keymap.Search && !inToolName(key.Ch) {
+			return false
+		}
+		appendSearchChar(d.state, key.Ch)
+		return true
+	}
+	return false
+}
+
+// run triggers the action bound to key, if any
+func (d *InputDispatcher) run(g *gocui.Gui, v *gocui.View, key keymap.Key) error {
+	action, ok := d.keymap().Action(key)
+	if !ok {
+		return nil
+	}
+	if handler := d.actions[action]; handler != nil {
+		return handler(g, v)
+	}
+	return nil
+}
+
+func (d *InputDispatcher) keymap() *keymap.Keymap {
+	if km := d.state.GetKeymap(); km != nil {
+		return km
+	}
+	return keymap.Default()
+}
+
+// isBackspace reports whether key is either of the codes terminals send for Backspace
+func isBackspace(key keymap.Key) bool {
+	return key.Key == gocui.KeyBackspace || key.Key == gocui.KeyBackspace2
+}
+
+// inToolName reports whether ch can appear in a tool name or display name
+func inToolName(ch rune) bool {
+	return unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '-' || ch == '_' || ch == '.'
+}
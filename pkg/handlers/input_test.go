@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/youpele52/lazysetup/pkg/keymap"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+// newTestDispatcher returns a dispatcher whose actions only record that they ran
+func newTestDispatcher(state *models.State) (*InputDispatcher, *[]string) {
+	var ran []string
+	actions := make(map[string]gocui.KeybindingHandler)
+	for _, action := range append(keymap.Actions, keymap.Quit, keymap.Back, keymap.PanelStatus, keymap.Transactional) {
+		action := action
+		actions[action] = func(g *gocui.Gui, v *gocui.View) error {
+			ran = append(ran, action)
+			return nil
+		}
+	}
+	return NewInputDispatcher(state, actions), &ran
+}
+
+// TestInputDispatcher tests routing key presses by input mode.
+// Priority: P1 - Typing a password or search query must never trigger panel shortcuts.
+// Tests normal, search, password, confirm and release notes modes and remapped keys.
+func TestInputDispatcher(t *testing.T) {
+	t.Run("normal mode runs the bound action", func(t *testing.T) {
+		state := models.NewState()
+		d, ran := newTestDispatcher(state)
+		_ = d.Dispatch(nil, nil, keymap.Char('c'))
+		_ = d.Dispatch(nil, nil, keymap.Char('0'))
+		_ = d.Dispatch(nil, nil, keymap.Char('z'))
+		if len(*ran) != 2 || (*ran)[0] != keymap.Clear || (*ran)[1] != keymap.PanelStatus {
+			t.Errorf("Expected clear and panel_status, got %v", *ran)
+		}
+	})
+
+	t.Run("search mode types shortcuts into the query", func(t *testing.T) {
+		state := models.NewState()
+		state.SetIsSearchMode(true)
+		d, ran := newTestDispatcher(state)
+		for _, ch := range "c0gut" {
+			_ = d.Dispatch(nil, nil, keymap.Char(ch))
+		}
+		if len(*ran) != 0 {
+			t.Errorf("Expected no actions while searching, got %v", *ran)
+		}
+		if got := state.GetSearchQuery(); got != "c0gut" {
+			t.Errorf("Expected query c0gut, got %q", got)
+		}
+
+		_ = d.Dispatch(nil, nil, keymap.Special(gocui.KeyBackspace2))
+		_ = d.Dispatch(nil, nil, keymap.Special(gocui.KeySpace))
+		if got := state.GetSearchQuery(); got != "c0gu" {
+			t.Errorf("Expected query c0gu after backspace, got %q", got)
+		}
+		if len(*ran) != 1 || (*ran)[0] != keymap.Toggle {
+			t.Errorf("Expected Space to toggle while searching, got %v", *ran)
+		}
+	})
+
+	t.Run("search key closes search unless it is typed in tool names", func(t *testing.T) {
+		state := models.NewState()
+		state.SetIsSearchMode(true)
+		d, ran := newTestDispatcher(state)
+		_ = d.Dispatch(nil, nil, keymap.Char('/'))
+		if len(*ran) != 1 || (*ran)[0] != keymap.Search {
+			t.Errorf("Expected / to run search, got %v", *ran)
+		}
+
+		km, _ := keymap.New(map[string]string{keymap.Search: "f"})
+		state.Keymap = km
+		*ran = nil
+		_ = d.Dispatch(nil, nil, keymap.Char('f'))
+		if len(*ran) != 0 || state.GetSearchQuery() != "f" {
+			t.Errorf("Expected f to be typed, got actions %v and query %q", *ran, state.GetSearchQuery())
+		}
+
+		_ = d.Dispatch(nil, nil, keymap.Special(gocui.KeyEsc))
+		if state.GetIsSearchMode() {
+			t.Error("Expected Esc to close search")
+		}
+	})
+
+	t.Run("password mode types every character", func(t *testing.T) {
+		state := models.NewState()
+		state.SetShowSudoConfirm(true)
+		state.SetIsSearchMode(true)
+		d, ran := newTestDispatcher(state)
+		for _, key := range []keymap.Key{keymap.Char('c'), keymap.Char('/'), keymap.Special(gocui.KeySpace), keymap.Char('1')} {
+			_ = d.Dispatch(nil, nil, key)
+		}
+		if len(*ran) != 0 {
+			t.Errorf("Expected no actions while typing a password, got %v", *ran)
+		}
+		if got := state.GetPasswordInput(); got != "c/ 1" {
+			t.Errorf("Expected password \"c/ 1\", got %q", got)
+		}
+		if state.GetSearchQuery() != "" {
+			t.Errorf("Expected search query untouched, got %q", state.GetSearchQuery())
+		}
+	})
+
+	t.Run("confirm mode ignores shortcuts", func(t *testing.T) {
+		state := models.NewState()
+		state.SetShowRollbackConfirm(true)
+		d, ran := newTestDispatcher(state)
+		_ = d.Dispatch(nil, nil, keymap.Char('t'))
+		_ = d.Dispatch(nil, nil, keymap.Char('c'))
+		_ = d.Dispatch(nil, nil, keymap.Special(gocui.KeyEsc))
+		if len(*ran) != 0 {
+			t.Errorf("Expected no actions on the rollback popup, got %v", *ran)
+		}
+		if state.GetShowRollbackConfirm() {
+			t.Error("Expected Esc to close the rollback popup")
+		}
+	})
+
+	t.Run("release notes mode confirms, closes and ignores shortcuts", func(t *testing.T) {
+		state := models.NewState()
+		d, ran := newTestDispatcher(state)
+		_ = OpenReleaseNotes(state)(nil, nil)
+		if state.GetShowReleaseNotes() {
+			t.Error("Expected no release notes without an update")
+		}
+
+		state.UpdateAvailable = true
+		_ = OpenReleaseNotes(state)(nil, nil)
+		if CurrentInputMode(state) != InputReleaseNotes {
+			t.Fatalf("Expected release notes mode, got %v", CurrentInputMode(state))
+		}
+		_ = d.Dispatch(nil, nil, keymap.Char('c'))
+		_ = d.Dispatch(nil, nil, keymap.Char('0'))
+		if len(*ran) != 0 || !state.GetShowReleaseNotes() {
+			t.Errorf("Expected shortcuts ignored with the popup open, got %v", *ran)
+		}
+		_ = d.Dispatch(nil, nil, keymap.Special(gocui.KeyEsc))
+		if state.GetShowReleaseNotes() {
+			t.Error("Expected Esc to close the release notes")
+		}
+		if len(*ran) != 0 {
+			t.Errorf("Expected Esc not to run back, got %v", *ran)
+		}
+	})
+
+	t.Run("keys cover typing and every bound key", func(t *testing.T) {
+		state := models.NewState()
+		km, _ := keymap.New(map[string]string{keymap.Clear: "f5"})
+		state.Keymap = km
+		d, _ := newTestDispatcher(state)
+		keys := make(map[keymap.Key]bool)
+		for _, key := range d.Keys() {
+			if keys[key] {
+				t.Errorf("Key %v listed twice", key)
+			}
+			keys[key] = true
+		}
+		for _, key := range []keymap.Key{keymap.Special(gocui.KeyF5), keymap.Char('q'), keymap.Special(gocui.KeyBackspace)} {
+			if !keys[key] {
+				t.Errorf("Expected %v to be bound", key)
+			}
+		}
+	})
+}
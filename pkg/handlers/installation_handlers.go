@@ -77,7 +77,7 @@ func MultiPanelStartInstallation(state *models.State) func(*gocui.Gui, *gocui.Vi
 
 							startTime := time.Now().Unix()
 							state.SetToolStartTime(toolName, startTime)
-							status, errMsg, output := installToolWithRetry(state, state.SelectedMethod, toolName)
+							status, errMsg, output, retries := installToolWithRetry(state, state.SelectedMethod, toolName)
 
 							mu.Lock()
 							state.AppendInstallOutput("Tool: " + toolName + "\n" + output + "\n")
@@ -89,7 +89,7 @@ func MultiPanelStartInstallation(state *models.State) func(*gocui.Gui, *gocui.Vi
 								Success:  status == constants.StatusSuccess,
 								Error:    errMsg,
 								Duration: duration,
-								Retries:  0,
+								Retries:  retries,
 							}
 							resultsChan <- result
 						}(tool)
@@ -7,6 +7,7 @@ import (
 	"github.com/jesseduffield/gocui"
 	"github.com/youpele52/lazysetup/pkg/commands"
 	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/diagnostics"
 	"github.com/youpele52/lazysetup/pkg/executor"
 	"github.com/youpele52/lazysetup/pkg/models"
 	"github.com/youpele52/lazysetup/pkg/tools"
@@ -15,7 +16,7 @@ import (
 func SelectMethod(state *models.State) func(*gocui.Gui, *gocui.View) error {
 	return func(g *gocui.Gui, v *gocui.View) error {
 		state.SelectedMethod = state.InstallMethods[state.PackageManagerScroll.Cursor]
-		state.CheckStatus, state.Error = checkInstallation(state.SelectedMethod)
+		state.CheckStatus, state.Error = checkInstallation(state.SelectedMethod, state.GetTimeouts().ManagerCheck())
 		if state.Error == "" && state.CheckStatus == constants.StatusAlreadyInstalled {
 			state.Tools = tools.Tools
 			state.SelectedTools = make(map[string]bool)
@@ -28,61 +29,76 @@ func SelectMethod(state *models.State) func(*gocui.Gui, *gocui.View) error {
 	}
 }
 
-// installToolWithRetry attempts to install a tool with automatic retry logic
-// on failure. It will retry up to maxRetries times with exponential backoff.
-// Returns: (status, errorMsg, output) where status is StatusSuccess or StatusFailed
-func installToolWithRetry(state *models.State, method, tool string) (string, string, string) {
-	maxRetries := 2
-	var lastErr string
-	var lastOutput string
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: 1s, 2s, 4s
-			delay := time.Duration(1<<uint(attempt-1)) * time.Second
-			time.Sleep(delay)
-		}
-
-		status, errMsg, output := installToolWithOutput(state, method, tool)
-		lastOutput = output
-		lastErr = errMsg
+// installToolWithRetry installs a tool, retrying failures per the state's retry policy
+// Returns: (status, errorMsg, output, retries) where status is StatusSuccess or StatusFailed
+func installToolWithRetry(state *models.State, method, tool string) (string, string, string, int) {
+	return withRetry(state, tool, func() (string, string, string) {
+		return installToolWithOutput(state, method, tool)
+	})
+}
 
+// withRetry runs an action on a tool until it succeeds or the retry policy gives up
+// Only failure categories the policy lists are retried, and waits end early when the
+// tool or the whole action is cancelled
+// Returns: (status, errorMsg, output, retries) from the last attempt
+func withRetry(state *models.State, tool string, action func() (string, string, string)) (string, string, string, int) {
+	var status, errMsg, output string
+	attempt := 0
+	attempts := state.GetRetryPolicy().Do(state.GetToolContext(tool), func() (bool, diagnostics.Category) {
+		attempt++
+		state.MarkToolAttempt(tool, attempt)
+		status, errMsg, output = action()
 		if status == constants.StatusSuccess {
-			return constants.StatusSuccess, "", output
+			return true, ""
 		}
-	}
-
-	return constants.StatusFailed, lastErr, lastOutput
+		return false, diagnoseFailure(errMsg, output).Category
+	})
+	return status, errMsg, output, attempts - 1
 }
 
 // installToolWithOutput executes installation command with cancellation support
-// Uses state's cancel context to allow aborting running installations
-// Uses sudo password if available for APT and Curl methods only
+// Uses the tool's cancel context to allow aborting it alone or with the whole action
+// Commands are escalated per the method's privilege policy
+// Curl recipes run in their own work directory, their download fetched through the shared cache
+// APT, DNF and YUM tools the configured repositories lack are installed from their vendor's
+// repository, added first when needed
+// Honors the tool's version pin, failing when the method cannot express it
 // Returns: (status, errorMsg, output) where status is StatusSuccess or StatusFailed
 // errorMsg contains the actual error from command output when possible
 func installToolWithOutput(state *models.State, method, tool string) (string, string, string) {
-	cmd := commands.GetInstallCommand(method, tool)
+	cmd, err := commands.GetPinnedInstallCommand(method, tool, state.GetVersionPin(tool))
+	if err != nil {
+		// The method cannot honor the pin, fail instead of silently installing latest
+		return constants.StatusFailed, err.Error(), ""
+	}
 	if cmd == "" {
 		return constants.StatusFailed, constants.NoInstallCommandError, ""
 	}
 
-	ctx := state.GetCancelContext()
-	var result *executor.CommandResult
+	ctx := toolContext(state, tool)
 
-	// Use sudo password only for APT and Curl methods
-	password := state.GetSudoPassword()
-	needsSudo := method == "APT" || method == "Curl" || method == "YUM"
-	if password != "" && needsSudo {
-		result = executor.ExecuteWithSudo(ctx, cmd, password, 15*time.Minute)
-	} else {
-		result = executor.ExecuteWithTimeout(ctx, cmd, 15*time.Minute)
+	timeout := state.GetTimeouts().Action(method, tool)
+	prepared, errMsg := prepareCommand(ctx, state, method, cmd, timeout)
+	if errMsg != "" {
+		return constants.StatusFailed, errMsg, ""
+	}
+	defer prepared.Cleanup()
+	cmd, notice := prepared.Command, prepared.Notice
+	if method == "APT" || method == "DNF" || method == "YUM" {
+		cmd, notice, errMsg = vendorRepo(ctx, state, method, tool, cmd, timeout)
+		if errMsg != "" {
+			return constants.StatusFailed, errMsg, notice
+		}
 	}
 
+	result := runPrivileged(ctx, state, method, cmd, timeout)
+	result.Output = withNotice(notice, result.Output)
+
 	if result.TimedOut {
-		return constants.StatusFailed, constants.InstallationTimedOut, result.Output
+		return constants.StatusFailed, stoppedMessage(timedOutMessage(timeout), result), result.Output
 	}
 	if result.Cancelled {
-		return constants.StatusFailed, constants.InstallationCancelled, result.Output
+		return constants.StatusFailed, stoppedMessage(constants.InstallationCancelled, result), result.Output
 	}
 	if result.ExitCode != 0 {
 		// Use actual command output as error message if available, otherwise use generic message
@@ -98,19 +114,20 @@ func installToolWithOutput(state *models.State, method, tool string) (string, st
 		return constants.StatusFailed, errMsg, result.Output
 	}
 
+	recordRepoTool(state, method, tool)
 	return constants.StatusSuccess, "", result.Output
 }
 
 // checkInstallation verifies if a package manager is installed and available
 // Returns: (status, errorMsg) where status is StatusAlreadyInstalled or StatusNotInstalled
-func checkInstallation(method string) (string, string) {
+func checkInstallation(method string, timeout time.Duration) (string, string) {
 	cmd := commands.GetCheckCommand(method)
 	if cmd == "" {
 		return "", "Unknown method"
 	}
 
 	ctx := context.Background()
-	result := executor.ExecuteWithTimeout(ctx, cmd, 10*time.Second)
+	result := executor.ExecuteWithTimeout(ctx, cmd, timeout)
 
 	if result.ExitCode != 0 {
 		errMsg := result.GetErrorMessage()
@@ -154,7 +171,3 @@ func GoBack(state *models.State) func(*gocui.Gui, *gocui.View) error {
 		return nil
 	}
 }
-
-func Quit(g *gocui.Gui, v *gocui.View) error {
-	return gocui.ErrQuit
-}
@@ -3,7 +3,9 @@ package handlers
 import (
 	"strings"
 
+	"github.com/jesseduffield/gocui"
 	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/models"
 )
 
 // FilterTools returns tools matching the search query (case-insensitive substring match)
@@ -32,3 +34,63 @@ func FilterTools(tools []string, query string) []string {
 
 	return filtered
 }
+
+// ToggleSearch starts filtering the Tools panel, or stops when already filtering ('/' key)
+// Only active in the Tools panel
+func ToggleSearch(state *models.State) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if state.GetCurrentPage() != models.PageMultiPanel || state.GetActivePanel() != models.PanelTools {
+			return nil
+		}
+		if state.GetIsSearchMode() {
+			ExitSearch(state)
+			return nil
+		}
+		state.SetIsSearchMode(true)
+		state.SetSearchQuery("")
+		state.SetFilteredTools(state.Tools)
+		state.ToolsSearchScroll.ItemCount = len(state.Tools)
+		state.ToolsSearchScroll.Cursor = 0
+		state.ToolsSearchScroll.Offset = 0
+		return nil
+	}
+}
+
+// ExitSearch stops filtering and clears the query and filtered list
+func ExitSearch(state *models.State) {
+	state.SetIsSearchMode(false)
+	state.SetSearchQuery("")
+	state.SetFilteredTools([]string{})
+	state.ToolsScroll.JumpToFirst()
+	state.ToolsSearchScroll.JumpToFirst()
+	state.ToolsSearchScroll.ItemCount = 0
+}
+
+// appendSearchChar adds a character to the query and refilters, resetting the cursor
+// when it falls outside the filtered list
+func appendSearchChar(state *models.State, ch rune) {
+	state.AppendSearchQuery(ch)
+	filtered := refilter(state)
+	if state.ToolsSearchScroll.Cursor >= len(filtered) {
+		state.ToolsSearchScroll.Cursor = 0
+		state.ToolsSearchScroll.Offset = 0
+	}
+}
+
+// backspaceSearch removes the query's last character and refilters, keeping the cursor
+// on the last tool when the list shrinks below it
+func backspaceSearch(state *models.State) {
+	state.BackspaceSearchQuery()
+	filtered := refilter(state)
+	if state.ToolsSearchScroll.Cursor >= len(filtered) && len(filtered) > 0 {
+		state.ToolsSearchScroll.Cursor = len(filtered) - 1
+	}
+}
+
+// refilter applies the current query to the tool list
+func refilter(state *models.State) []string {
+	filtered := FilterTools(state.Tools, state.GetSearchQuery())
+	state.SetFilteredTools(filtered)
+	state.ToolsSearchScroll.ItemCount = len(filtered)
+	return filtered
+}
@@ -0,0 +1,212 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	// FileVersion is the format version written to the durations file
+	FileVersion = 1
+
+	maxSamples = 10 // Most recent durations kept per tool, method and action
+
+	// A running tool is probably hung once it has taken hungFactor times its usual
+	// duration and at least hungMinExtra seconds longer than usual
+	hungFactor   = 3
+	hungMinExtra = 60
+)
+
+// Record holds the recent successful durations of one action on one tool with one method
+type Record struct {
+	Action  string  `json:"action"`
+	Method  string  `json:"method"`
+	Tool    string  `json:"tool"`
+	Samples []int64 `json:"samples"` // Seconds, oldest first
+}
+
+type file struct {
+	Version int      `json:"version"`
+	Records []Record `json:"records"`
+}
+
+// Store keeps past action durations and estimates new ones from them
+// Safe for concurrent use; a nil Store has no history and records nothing
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	records []Record
+}
+
+// Path returns the durations file, $XDG_STATE_HOME/lazysetup/durations.json
+// (~/.local/state when XDG_STATE_HOME is unset)
+func Path() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate state directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "lazysetup", "durations.json"), nil
+}
+
+// New returns an empty store that saves to path; an empty path keeps it in memory only
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load reads the durations file at path
+// A missing file is not an error and yields an empty store
+func Load(path string) (*Store, error) {
+	store := New(path)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return store, fmt.Errorf("failed to read duration history: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return store, fmt.Errorf("failed to parse duration history %s: %w", path, err)
+	}
+	if f.Version > FileVersion {
+		return store, fmt.Errorf("unsupported duration history version %d", f.Version)
+	}
+	store.records = f.Records
+	return store, nil
+}
+
+// Save writes the store to its path, replacing the file atomically
+func (s *Store) Save() error {
+	if s == nil || s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	data, err := json.MarshalIndent(file{Version: FileVersion, Records: s.records}, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode duration history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write duration history: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write duration history: %w", err)
+	}
+	return nil
+}
+
+// Add records how many seconds a successful action took, keeping the latest maxSamples
+func (s *Store) Add(action, method, tool string, seconds int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.records {
+		r := &s.records[i]
+		if r.Action == action && r.Method == method && r.Tool == tool {
+			r.Samples = append(r.Samples, seconds)
+			if len(r.Samples) > maxSamples {
+				r.Samples = r.Samples[len(r.Samples)-maxSamples:]
+			}
+			return
+		}
+	}
+	s.records = append(s.records, Record{Action: action, Method: method, Tool: tool, Samples: []int64{seconds}})
+}
+
+// Expected returns the usual duration in seconds of an action on a tool with a method:
+// the median of its recent samples, or of the tool's samples with any method when the
+// method has none. ok is false when the tool has no history for the action
+func (s *Store) Expected(action, method, tool string) (seconds int64, ok bool) {
+	if s == nil {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var anyMethod []int64
+	for _, r := range s.records {
+		if r.Action != action || r.Tool != tool {
+			continue
+		}
+		if r.Method == method && len(r.Samples) > 0 {
+			return median(r.Samples), true
+		}
+		anyMethod = append(anyMethod, r.Samples...)
+	}
+	if len(anyMethod) == 0 {
+		return 0, false
+	}
+	return median(anyMethod), true
+}
+
+// median returns the middle value of samples, the lower one for an even count
+func median(samples []int64) int64 {
+	sorted := append([]int64(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)-1)/2]
+}
+
+// ProbablyHung reports whether a tool running for elapsed seconds is taking far longer
+// than its expected duration
+func ProbablyHung(elapsed, expected int64) bool {
+	return expected > 0 && elapsed >= hungFactor*expected && elapsed-expected >= hungMinExtra
+}
+
+// EstimateRemaining returns the seconds until a batch finishes when slots tools run at once
+// running holds the remaining seconds of each running tool and queued the expected seconds
+// of each waiting tool in start order; each queued tool takes the first slot to free up
+func EstimateRemaining(running, queued []int64, slots int) int64 {
+	if slots < 1 {
+		slots = 1
+	}
+	free := make([]int64, slots)
+	for i, remaining := range running {
+		if remaining < 0 {
+			remaining = 0
+		}
+		if i < slots {
+			free[i] = remaining
+		} else {
+			free[earliest(free)] += remaining
+		}
+	}
+	for _, expected := range queued {
+		free[earliest(free)] += expected
+	}
+
+	var finish int64
+	for _, t := range free {
+		if t > finish {
+			finish = t
+		}
+	}
+	return finish
+}
+
+// earliest returns the index of the slot that frees up first
+func earliest(free []int64) int {
+	best := 0
+	for i := range free {
+		if free[i] < free[best] {
+			best = i
+		}
+	}
+	return best
+}
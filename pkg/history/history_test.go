@@ -0,0 +1,148 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestStore_Expected tests estimating a tool's duration from past runs.
+// Priority: P1 - ETAs and hung warnings are only as good as these estimates.
+// Tests the median, the fallback to other methods and the sample limit.
+func TestStore_Expected(t *testing.T) {
+	t.Run("median of recent samples", func(t *testing.T) {
+		store := New("")
+		for _, seconds := range []int64{10, 300, 12} {
+			store.Add("install", "APT", "git", seconds)
+		}
+		if got, ok := store.Expected("install", "APT", "git"); !ok || got != 12 {
+			t.Errorf("Expected 12s, got %d (ok=%v)", got, ok)
+		}
+	})
+
+	t.Run("falls back to other methods for the same tool", func(t *testing.T) {
+		store := New("")
+		store.Add("install", "Homebrew", "git", 20)
+		if got, ok := store.Expected("install", "APT", "git"); !ok || got != 20 {
+			t.Errorf("Expected 20s from Homebrew history, got %d (ok=%v)", got, ok)
+		}
+		if _, ok := store.Expected("update", "APT", "git"); ok {
+			t.Error("Expected no estimate for an action without history")
+		}
+	})
+
+	t.Run("keeps only the latest samples", func(t *testing.T) {
+		store := New("")
+		for i := 0; i < maxSamples; i++ {
+			store.Add("install", "APT", "git", 1000)
+		}
+		for i := 0; i < maxSamples; i++ {
+			store.Add("install", "APT", "git", 5)
+		}
+		if got, _ := store.Expected("install", "APT", "git"); got != 5 {
+			t.Errorf("Expected old samples to be dropped, got %d", got)
+		}
+	})
+
+	t.Run("nil store has no history", func(t *testing.T) {
+		var store *Store
+		store.Add("install", "APT", "git", 5)
+		if _, ok := store.Expected("install", "APT", "git"); ok {
+			t.Error("Expected no estimate from a nil store")
+		}
+	})
+}
+
+// TestStore_SaveLoad tests persisting durations between runs.
+// Priority: P1 - Durations thrown away at exit give no estimates next time.
+// Tests a round trip through the file and that a missing file is not an error.
+func TestStore_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("missing file yields empty store", func(t *testing.T) {
+		store, err := Load(filepath.Join(dir, "missing.json"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, ok := store.Expected("install", "APT", "git"); ok {
+			t.Error("Expected no history")
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		path := filepath.Join(dir, "nested", "durations.json")
+		store := New(path)
+		store.Add("install", "APT", "git", 42)
+		if err := store.Save(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got, ok := loaded.Expected("install", "APT", "git"); !ok || got != 42 {
+			t.Errorf("Expected 42s after reload, got %d (ok=%v)", got, ok)
+		}
+	})
+}
+
+// TestPath tests the durations file location.
+// Priority: P2 - History must live where XDG-aware users expect state files.
+// Tests that XDG_STATE_HOME is honored.
+func TestPath(t *testing.T) {
+	t.Run("honors XDG_STATE_HOME", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "/tmp/state")
+		path, err := Path()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != "/tmp/state/lazysetup/durations.json" {
+			t.Errorf("Expected /tmp/state/lazysetup/durations.json, got %s", path)
+		}
+	})
+}
+
+// TestProbablyHung tests flagging tools that take far longer than usual.
+// Priority: P2 - False alarms on slightly slow tools would train users to ignore the warning.
+// Tests the factor, the minimum extra time and unknown durations.
+func TestProbablyHung(t *testing.T) {
+	cases := []struct {
+		name              string
+		elapsed, expected int64
+		want              bool
+	}{
+		{"well over usual", 400, 100, true},
+		{"slower than usual", 200, 100, false},
+		{"short tool slightly over", 15, 5, false},
+		{"no history", 10000, 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ProbablyHung(c.elapsed, c.expected); got != c.want {
+				t.Errorf("Expected %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+// TestEstimateRemaining tests the batch ETA under limited parallelism.
+// Priority: P1 - The ETA must account for tools waiting for a free slot.
+// Tests running tools, queued tools taking the first free slot and overdue tools.
+func TestEstimateRemaining(t *testing.T) {
+	t.Run("queued tools wait for the first free slot", func(t *testing.T) {
+		// Slots free at 10s and 30s; the queued 15s tool takes the first and ends at 25s
+		if got := EstimateRemaining([]int64{10, 30}, []int64{15}, 2); got != 30 {
+			t.Errorf("Expected 30s, got %d", got)
+		}
+		// A second queued 20s tool takes the slot freed at 25s
+		if got := EstimateRemaining([]int64{10, 30}, []int64{15, 20}, 2); got != 45 {
+			t.Errorf("Expected 45s, got %d", got)
+		}
+	})
+
+	t.Run("overdue tools count as finishing now", func(t *testing.T) {
+		if got := EstimateRemaining([]int64{-50}, []int64{10}, 1); got != 10 {
+			t.Errorf("Expected 10s, got %d", got)
+		}
+	})
+}
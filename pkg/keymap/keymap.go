@@ -0,0 +1,288 @@
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+)
+
+// Remappable actions, the keys of the config file's keybindings section
+const (
+	NextPanel = "next_panel" // Move focus to the next panel
+	JumpFirst = "jump_first" // Jump to the first item of the active panel
+	JumpLast  = "jump_last"  // Jump to the last item of the active panel
+	Toggle    = "toggle"     // Select or deselect the tool under the cursor
+	Search    = "search"     // Start or stop filtering the Tools panel
+	Execute   = "execute"    // Confirm the selection or run the action
+	Clear     = "clear"      // Clear the Status panel
+	Update    = "update"     // Install the available lazysetup update
+	Website   = "website"    // Open the lazysetup website, unbound by default
+)
+
+// Fixed actions, always on the keys in Reserved
+const (
+	Quit          = "quit"
+	Back          = "back"
+	Up            = "up"
+	Down          = "down"
+	PanelStatus   = "panel_status"
+	PanelMethod   = "panel_method"
+	PanelAction   = "panel_action"
+	PanelTools    = "panel_tools"
+	Transactional = "transactional"
+	AISuggestions = "ai_suggestions"
+	CycleMethod   = "cycle_method"
+	CancelTool    = "cancel_tool"
+	PauseQueue    = "pause_queue"
+)
+
+// Key is a key press: a special key such as Tab, or a character
+type Key struct {
+	Key gocui.Key // Special key, zero for characters
+	Ch  rune      // Character, zero for special keys
+}
+
+// Char returns the Key for a character
+func Char(ch rune) Key {
+	return Key{Ch: ch}
+}
+
+// Special returns the Key for a special key
+func Special(key gocui.Key) Key {
+	return Key{Key: key}
+}
+
+// Binding returns the value gocui.SetKeybinding expects for the key
+func (k Key) Binding() interface{} {
+	if k.Ch != 0 {
+		return k.Ch
+	}
+	return k.Key
+}
+
+// IsChar reports whether the key types a character
+func (k Key) IsChar() bool {
+	return k.Ch != 0
+}
+
+// String returns the key as shown in the status bar, e.g. "g", "Tab" or "Ctrl+F"
+func (k Key) String() string {
+	if k.Ch != 0 {
+		return string(k.Ch)
+	}
+	for name, key := range specialKeys {
+		if key == k.Key {
+			return specialLabels[name]
+		}
+	}
+	for i := 0; i < 26; i++ {
+		if k.Key == gocui.KeyCtrlA+gocui.Key(i) {
+			return "Ctrl+" + string(rune('A'+i))
+		}
+	}
+	return "?"
+}
+
+// specialKeys are the named keys accepted in the config file besides single characters,
+// Ctrl+letter and F1-F12
+var specialKeys = map[string]gocui.Key{
+	"tab":       gocui.KeyTab,
+	"enter":     gocui.KeyEnter,
+	"space":     gocui.KeySpace,
+	"esc":       gocui.KeyEsc,
+	"backspace": gocui.KeyBackspace2,
+	"up":        gocui.KeyArrowUp,
+	"down":      gocui.KeyArrowDown,
+	"home":      gocui.KeyHome,
+	"end":       gocui.KeyEnd,
+	"pgup":      gocui.KeyPgup,
+	"pgdn":      gocui.KeyPgdn,
+	"f1":        gocui.KeyF1,
+	"f2":        gocui.KeyF2,
+	"f3":        gocui.KeyF3,
+	"f4":        gocui.KeyF4,
+	"f5":        gocui.KeyF5,
+	"f6":        gocui.KeyF6,
+	"f7":        gocui.KeyF7,
+	"f8":        gocui.KeyF8,
+	"f9":        gocui.KeyF9,
+	"f10":       gocui.KeyF10,
+	"f11":       gocui.KeyF11,
+	"f12":       gocui.KeyF12,
+}
+
+var specialLabels = map[string]string{
+	"tab": "Tab", "enter": "⏎", "space": "Space", "esc": "Esc", "backspace": "Backspace",
+	"up": "↑", "down": "↓", "home": "Home", "end": "End", "pgup": "PgUp", "pgdn": "PgDn",
+	"f1": "F1", "f2": "F2", "f3": "F3", "f4": "F4", "f5": "F5", "f6": "F6",
+	"f7": "F7", "f8": "F8", "f9": "F9", "f10": "F10", "f11": "F11", "f12": "F12",
+}
+
+// ctrlAliases are the Ctrl+letter keys terminals cannot tell apart from a named key
+var ctrlAliases = map[byte]string{'h': "backspace", 'i': "tab", 'm': "enter"}
+
+// Parse reads a key name from the config file: a single printable character such as "g"
+// or "/", a named key such as "tab", "enter", "space" or "f5", or "ctrl+<letter>"
+func Parse(name string) (Key, error) {
+	if runes := []rune(name); len(runes) == 1 {
+		if runes[0] < 33 || runes[0] > 126 {
+			return Key{}, fmt.Errorf("unsupported key %q", name)
+		}
+		return Char(runes[0]), nil
+	}
+
+	lower := strings.ToLower(name)
+	if key, ok := specialKeys[lower]; ok {
+		return Special(key), nil
+	}
+	if letter := strings.TrimPrefix(lower, "ctrl+"); letter != lower && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		if same, ok := ctrlAliases[letter[0]]; ok {
+			return Key{}, fmt.Errorf("%s sends the same key as %s, use %s", lower, same, same)
+		}
+		return Special(gocui.KeyCtrlA + gocui.Key(letter[0]-'a')), nil
+	}
+	return Key{}, fmt.Errorf("unknown key %q", name)
+}
+
+// defaults are the keys of each remappable action when the config file does not set them
+var defaults = map[string][]Key{
+	NextPanel: {Special(gocui.KeyTab)},
+	JumpFirst: {Char('g'), Char('w')},
+	JumpLast:  {Char('G'), Char('s')},
+	Toggle:    {Special(gocui.KeySpace)},
+	Search:    {Char('/')},
+	Execute:   {Special(gocui.KeyEnter)},
+	Clear:     {Char('c')},
+	Update:    {Char('u')},
+	Website:   nil,
+}
+
+// Reserved are the fixed actions and their keys, which cannot be remapped or reused
+var Reserved = map[string][]Key{
+	Quit:          {Special(gocui.KeyCtrlC)},
+	Back:          {Special(gocui.KeyEsc)},
+	Up:            {Special(gocui.KeyArrowUp)},
+	Down:          {Special(gocui.KeyArrowDown)},
+	PanelStatus:   {Char('0')},
+	PanelMethod:   {Char('1')},
+	PanelAction:   {Char('2')},
+	PanelTools:    {Char('3')},
+	Transactional: {Char('t')},
+	AISuggestions: {Char('a')},
+	CycleMethod:   {Char('m')},
+	CancelTool:    {Char('x')},
+	PauseQueue:    {Char('p')},
+}
+
+// Actions lists the remappable actions in the order they are documented
+var Actions = []string{NextPanel, JumpFirst, JumpLast, Toggle, Search, Execute, Clear, Update, Website}
+
+// Keymap maps each key to the action it triggers
+type Keymap struct {
+	actions map[Key]string
+	keys    map[string][]Key
+}
+
+// Default returns the keymap with every action on its default keys
+func Default() *Keymap {
+	km, _ := New(nil)
+	return km
+}
+
+// New returns the default keymap with the config file's overrides applied
+// Each override replaces all default keys of its action and may list several keys
+// separated by spaces, e.g. jump_first: "g home". Unknown actions, unparsable keys and
+// keys already used by another action are errors
+func New(overrides map[string]string) (*Keymap, error) {
+	km := &Keymap{actions: make(map[Key]string), keys: make(map[string][]Key)}
+	for action, keys := range Reserved {
+		km.keys[action] = keys
+	}
+	for action, keys := range defaults {
+		km.keys[action] = keys
+	}
+
+	names := make([]string, 0, len(overrides))
+	for action := range overrides {
+		names = append(names, action)
+	}
+	sort.Strings(names)
+	for _, action := range names {
+		if _, ok := Reserved[action]; ok {
+			return nil, fmt.Errorf("%s: cannot be remapped", action)
+		}
+		if _, ok := defaults[action]; !ok {
+			return nil, fmt.Errorf("%s: unknown action (use one of %v)", action, Actions)
+		}
+		fields := strings.Fields(overrides[action])
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s: key must not be empty", action)
+		}
+		var keys []Key
+		for _, field := range fields {
+			key, err := Parse(field)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", action, err)
+			}
+			keys = append(keys, key)
+		}
+		km.keys[action] = keys
+	}
+
+	for _, action := range km.sortedActions() {
+		for _, key := range km.keys[action] {
+			if other, taken := km.actions[key]; taken {
+				return nil, fmt.Errorf("%s: key %s is already used by %s", action, key, other)
+			}
+			km.actions[key] = action
+		}
+	}
+	return km, nil
+}
+
+// sortedActions lists reserved actions first, so conflicts are reported on the remapped one
+func (km *Keymap) sortedActions() []string {
+	var reserved []string
+	for action := range Reserved {
+		reserved = append(reserved, action)
+	}
+	sort.Strings(reserved)
+	return append(reserved, Actions...)
+}
+
+// Action returns the action bound to key
+func (km *Keymap) Action(key Key) (string, bool) {
+	action, ok := km.actions[key]
+	return action, ok
+}
+
+// Keys returns the keys bound to action
+func (km *Keymap) Keys(action string) []Key {
+	return km.keys[action]
+}
+
+// Label returns the keys of action for the status bar, e.g. "g/w"
+func (km *Keymap) Label(action string) string {
+	var labels []string
+	for _, key := range km.keys[action] {
+		labels = append(labels, key.String())
+	}
+	return strings.Join(labels, "/")
+}
+
+// Bound returns every key with an action, in a stable order
+func (km *Keymap) Bound() []Key {
+	keys := make([]Key, 0, len(km.actions))
+	for key := range km.actions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Key != keys[j].Key {
+			return keys[i].Key < keys[j].Key
+		}
+		return keys[i].Ch < keys[j].Ch
+	})
+	return keys
+}
@@ -0,0 +1,94 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jesseduffield/gocui"
+)
+
+// TestParse tests reading key names from the config file.
+// Priority: P1 - A misparsed key leaves an action unreachable.
+// Tests characters, named keys, Ctrl combinations and invalid names.
+func TestParse(t *testing.T) {
+	t.Run("accepts characters and named keys", func(t *testing.T) {
+		cases := map[string]Key{
+			"g":      Char('g'),
+			"/":      Char('/'),
+			"tab":    Special(gocui.KeyTab),
+			"Enter":  Special(gocui.KeyEnter),
+			"f5":     Special(gocui.KeyF5),
+			"ctrl+f": Special(gocui.KeyCtrlF),
+		}
+		for name, want := range cases {
+			got, err := Parse(name)
+			if err != nil || got != want {
+				t.Errorf("Parse(%q) = %v, %v; want %v", name, got, err, want)
+			}
+		}
+	})
+
+	t.Run("rejects unknown names and ctrl keys that alias named keys", func(t *testing.T) {
+		for _, name := range []string{"", "hyper", "ctrl+1", "é", "ctrl+h", "ctrl+i", "ctrl+m"} {
+			if _, err := Parse(name); err == nil {
+				t.Errorf("Expected error for %q", name)
+			}
+		}
+	})
+}
+
+// TestNew tests building the keymap from config file overrides.
+// Priority: P1 - Remapped keys must never shadow another action.
+// Tests defaults, overrides, multiple keys and conflicts.
+func TestNew(t *testing.T) {
+	t.Run("defaults match the documented keys", func(t *testing.T) {
+		km := Default()
+		if action, _ := km.Action(Char('/')); action != Search {
+			t.Errorf("Expected / to search, got %q", action)
+		}
+		if km.Label(JumpFirst) != "g/w" || km.Label(Execute) != "⏎" {
+			t.Errorf("Unexpected labels %q and %q", km.Label(JumpFirst), km.Label(Execute))
+		}
+		if len(km.Keys(Website)) != 0 {
+			t.Errorf("Expected website to be unbound, got %v", km.Keys(Website))
+		}
+	})
+
+	t.Run("overrides replace the default keys", func(t *testing.T) {
+		km, err := New(map[string]string{Search: "ctrl+f", JumpFirst: "home g"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, ok := km.Action(Char('/')); ok {
+			t.Error("Expected / to be unbound after remapping search")
+		}
+		if action, _ := km.Action(Special(gocui.KeyCtrlF)); action != Search {
+			t.Errorf("Expected Ctrl+F to search, got %q", action)
+		}
+		if km.Label(JumpFirst) != "Home/g" {
+			t.Errorf("Expected Home/g, got %q", km.Label(JumpFirst))
+		}
+		if action, _ := km.Action(Char('w')); action != "" {
+			t.Errorf("Expected w to be unbound, got %q", action)
+		}
+	})
+
+	t.Run("rejects unknown actions, fixed actions and conflicts", func(t *testing.T) {
+		cases := []struct {
+			overrides map[string]string
+			want      string
+		}{
+			{map[string]string{"launch": "l"}, "unknown action"},
+			{map[string]string{Quit: "q"}, "cannot be remapped"},
+			{map[string]string{Clear: "g"}, "already used by jump_first"},
+			{map[string]string{Update: "x"}, "already used by cancel_tool"},
+			{map[string]string{Toggle: " "}, "must not be empty"},
+		}
+		for _, tc := range cases {
+			_, err := New(tc.overrides)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("New(%v) error = %v, want %q", tc.overrides, err, tc.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/youpele52/lazysetup/pkg/colors"
+)
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listPattern     = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	orderedPattern  = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	taskPattern     = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	rulePattern     = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	commentPattern  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	imagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	autolinkPattern = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	boldPattern     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	codePattern     = regexp.MustCompile("`([^`]+)`")
+)
+
+// ruleWidth is the width of a rendered horizontal rule
+const ruleWidth = 40
+
+// Render turns GitHub-flavored markdown into plain terminal text with the active theme's
+// ANSI escapes: headings in bold accent, code in the info color, bullets as "•",
+// links as "text (url)"; HTML comments and images are dropped
+func Render(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = commentPattern.ReplaceAllString(source, "")
+
+	var out []string
+	inCode := false
+	blank := true // Collapses runs of blank lines and drops leading ones
+	for _, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, "  "+colors.ANSIInfo+line+colors.ANSIReset)
+			blank = false
+			continue
+		}
+		if trimmed == "" {
+			if !blank {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		out = append(out, renderLine(line, trimmed))
+	}
+	return strings.TrimRight(strings.Join(out, "\n"), "\n")
+}
+
+// renderLine renders one line outside code blocks
+func renderLine(line, trimmed string) string {
+	if rulePattern.MatchString(line) {
+		return strings.Repeat("─", ruleWidth)
+	}
+	if m := headingPattern.FindStringSubmatch(trimmed); m != nil {
+		style := colors.ANSIBold + colors.ANSIAccent
+		return style + inline(m[2], style) + colors.ANSIReset
+	}
+	if m := listPattern.FindStringSubmatch(line); m != nil {
+		bullet, text := "•", m[2]
+		if task := taskPattern.FindStringSubmatch(text); task != nil {
+			bullet, text = "☐", task[2]
+			if task[1] != " " {
+				bullet = "☑"
+			}
+		}
+		return indent(m[1]) + bullet + " " + inline(text, "")
+	}
+	if m := orderedPattern.FindStringSubmatch(line); m != nil {
+		return indent(m[1]) + m[2] + ". " + inline(m[3], "")
+	}
+	if strings.HasPrefix(trimmed, ">") {
+		return "│ " + inline(strings.TrimSpace(strings.TrimLeft(trimmed, ">")), "")
+	}
+	return inline(trimmed, "")
+}
+
+// indent renders list nesting as two spaces per level, whatever the source used
+func indent(prefix string) string {
+	width := len(strings.ReplaceAll(prefix, "\t", "    "))
+	return strings.Repeat("  ", width/2)
+}
+
+// inline renders code spans, bold text and links within a line
+// style is re-applied after each inline reset so a heading keeps its color
+func inline(text, style string) string {
+	// Code spans are rendered first and kept out of the other rules
+	parts := codePattern.Split(text, -1)
+	codes := codePattern.FindAllStringSubmatch(text, -1)
+
+	var b strings.Builder
+	for i, part := range parts {
+		part = imagePattern.ReplaceAllString(part, "$1")
+		part = autolinkPattern.ReplaceAllString(part, "$1")
+		part = linkPattern.ReplaceAllStringFunc(part, func(link string) string {
+			m := linkPattern.FindStringSubmatch(link)
+			if m[1] == m[2] {
+				return m[2]
+			}
+			return m[1] + " (" + m[2] + ")"
+		})
+		part = boldPattern.ReplaceAllStringFunc(part, func(bold string) string {
+			m := boldPattern.FindStringSubmatch(bold)
+			return colors.ANSIBold + m[1] + m[2] + colors.ANSIReset + style
+		})
+		b.WriteString(part)
+		if i < len(codes) {
+			b.WriteString(colors.ANSIInfo + codes[i][1] + colors.ANSIReset + style)
+		}
+	}
+	return b.String()
+}
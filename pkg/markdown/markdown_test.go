@@ -0,0 +1,63 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/youpele52/lazysetup/pkg/colors"
+)
+
+var escapePattern = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// plain strips ANSI escapes so tests compare the visible text
+func plain(s string) string {
+	return escapePattern.ReplaceAllString(s, "")
+}
+
+// TestRender tests rendering release notes markdown for the terminal.
+// Priority: P2 - Raw markdown syntax makes release notes hard to read.
+// Tests headings, lists, code, links, comments and blank lines.
+func TestRender(t *testing.T) {
+	t.Run("renders block elements", func(t *testing.T) {
+		source := "<!-- generated -->\r\n## What's Changed\r\n\r\n\r\n\r\n- Fix **sudo** prompt\n  * nested item\n1. first\n- [x] done\n> quoted\n---\n```sh\nlazysetup self rollback\n```\n"
+		want := strings.Join([]string{
+			"What's Changed",
+			"",
+			"• Fix sudo prompt",
+			"  • nested item",
+			"1. first",
+			"☑ done",
+			"│ quoted",
+			strings.Repeat("─", ruleWidth),
+			"  lazysetup self rollback",
+		}, "\n")
+		if got := plain(Render(source)); got != want {
+			t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+		}
+	})
+
+	t.Run("renders inline elements", func(t *testing.T) {
+		source := "See [the docs](https://example.com/docs), <https://example.com> and ![logo](logo.png) for `--version`."
+		want := "See the docs (https://example.com/docs), https://example.com and logo for --version."
+		if got := plain(Render(source)); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	})
+
+	t.Run("leaves markdown syntax inside code spans alone", func(t *testing.T) {
+		if got := plain(Render("run `**not bold**`")); got != "run **not bold**" {
+			t.Errorf("Expected code span kept verbatim, got %q", got)
+		}
+	})
+
+	t.Run("styles headings and emphasis with theme escapes", func(t *testing.T) {
+		got := Render("# Title\n**bold**")
+		if !strings.Contains(got, colors.ANSIBold+colors.ANSIAccent+"Title") {
+			t.Errorf("Expected bold accent heading, got %q", got)
+		}
+		if !strings.Contains(got, colors.ANSIBold+"bold"+colors.ANSIReset) {
+			t.Errorf("Expected bold text, got %q", got)
+		}
+	})
+}
@@ -4,8 +4,15 @@ import (
 	"context"
 	"sync"
 
+	"github.com/youpele52/lazysetup/pkg/cache"
 	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/history"
+	"github.com/youpele52/lazysetup/pkg/keymap"
+	"github.com/youpele52/lazysetup/pkg/privilege"
+	"github.com/youpele52/lazysetup/pkg/repos"
+	"github.com/youpele52/lazysetup/pkg/retry"
 	"github.com/youpele52/lazysetup/pkg/tools"
+	"github.com/youpele52/lazysetup/pkg/updater"
 )
 
 // Page represents the current UI page being displayed
@@ -49,6 +56,20 @@ type InstallResult struct {
 	Error    string // Error message if installation failed
 	Duration int64  // Time taken to install in seconds
 	Retries  int    // Number of retry attempts made
+	Method   string // Package manager the action ran with
+	Category string // Classified cause of a failure (diagnostics.Category)
+	Hint     string // Suggested remediation for a failure
+	Command  string // Command that failed, kept for AI-assisted resolution
+	Output   string // Output of the failed command, kept for AI-assisted resolution
+
+	RolledBack bool // Whether this result records the rollback (uninstall) of a newly installed tool
+}
+
+// AISuggestion is a provider's suggested fix for one failed tool
+type AISuggestion struct {
+	Tool  string
+	Text  string
+	Error string // Set instead of Text when the provider request failed
 }
 
 // State holds all application state with thread-safe access
@@ -92,6 +113,61 @@ type State struct {
 	CancelCtx         context.Context    // Context for cancelling running installations
 	CancelFunc        context.CancelFunc // Function to cancel the context
 
+	// Version pinning
+	VersionPins map[string]string // Version constraint per tool (e.g. "1.9.x"), empty means latest
+
+	// Per-tool package manager selection
+	ToolMethods     map[string]string // Package manager override per tool, empty means SelectedMethod
+	FallbackMethods []string          // Methods tried in order when a tool's method has no command for it
+	FallbackPolicy  string            // When a tool moves on to the next fallback method (config.Fallback*)
+
+	RetryPolicy retry.Policy    // When failed install, update and uninstall commands are run again
+	Timeouts    config.Timeouts // How long each command may run, per tool and method
+	Flights     config.Flights  // Commands each method runs before and after a batch
+
+	// Per-tool cancellation and queueing
+	ToolCancels    map[string]inFlightTool // Child context of CancelCtx per running tool
+	QueuePaused    bool                    // Whether queued tools wait instead of starting
+	InFlightCursor int                     // Selected entry in the Status panel's running tools list
+
+	// Progress dashboard
+	ToolProgress  map[string]*ToolProgress // Live state per tool of the running action
+	ProgressOrder []string                 // Tools of the running action in selection order
+
+	FlightSteps []FlightStep // Pre-flight and post-flight commands of the running action, in start order
+
+	DurationHistory *history.Store // Past action durations, used for ETAs and hung-tool warnings
+
+	// Application settings from the config file
+	Parallelism int            // Tools run at once by an action
+	LogDir      string         // Where each action's output is logged, empty disables logging
+	LogKeep     int            // How many action logs are kept in LogDir
+	Keymap      *keymap.Keymap // Key bound to each action
+	Downloads   *cache.Cache   // Shared cache of Curl recipe downloads, nil when disabled
+
+	Repos *repos.Store // Vendor repositories lazysetup added, nil when adding them is disabled
+
+	// AI-assisted error resolution
+	AIConfig      config.AIConfig // AI settings from the config file, disabled by default
+	ShowAIPanel   bool            // Whether the AI suggestions side view is shown next to the results
+	AIPending     bool            // Whether suggestions are being requested
+	AIMessage     string          // Panel-level message: the provider in use, or why nothing was sent
+	AISuggestions []AISuggestion  // Suggested fixes for the failed tools of the last action
+
+	// Transactional install state
+	TransactionalMode   bool     // Whether install batches offer a rollback when some tools fail
+	NewlyInstalled      []string // Tools installed by the current batch that were absent before it started
+	ShowRollbackConfirm bool     // Whether to show the rollback confirmation popup
+	RollingBack         bool     // Whether newly installed tools are being rolled back
+
+	// Graceful shutdown
+	RunningActions  int    // Background actions (tool actions, rollbacks, self-updates) not yet finished
+	LastAction      string // Tool action started last, named in the summary printed on exit
+	ShowQuitConfirm bool   // Whether to show the quit confirmation popup
+
+	// Privilege escalation
+	Escalation privilege.Escalation // How each method's commands run as root; sudo until the config is applied
+
 	// Popup state
 	ShowSudoConfirm bool       // Whether to show sudo confirmation popup
 	PendingAction   ActionType // Action waiting for sudo confirmation
@@ -107,7 +183,13 @@ type State struct {
 	UpdateVersion     string // Latest version available
 	UpdateMessage     string // Update status message to display
 	UpdateDownloadURL string // URL to download the update
+	UpdateAssetName   string // File name of the update's release asset
+	UpdateChecksumURL string // URL of the release's SHA256SUMS
 	UpdateMessageTime int64  // Unix timestamp when update message was shown
+
+	// Release notes shown before an update is confirmed
+	UpdateNotes      []updater.ReleaseNote // Notes of every release newer than the running one, newest first
+	ShowReleaseNotes bool                  // Whether the release notes popup is open
 }
 
 func NewState() *State {
@@ -122,6 +204,15 @@ func NewState() *State {
 		ActionScroll:         PanelScrollState{ItemCount: len(config.Actions)},
 		ToolsScroll:          PanelScrollState{ItemCount: len(tools.Tools)},
 		SelectedTools:        make(map[string]bool),
+		VersionPins:          make(map[string]string),
+		ToolMethods:          make(map[string]string),
+		FallbackMethods:      config.DefaultFallbackMethods,
+		FallbackPolicy:       config.DefaultFallbackPolicy,
+		RetryPolicy:          retry.DefaultPolicy(),
+		DurationHistory:      history.New(""),
+		Parallelism:          config.DefaultParallelism,
+		Keymap:               keymap.Default(),
+		Escalation:           privilege.Escalation{Tool: privilege.ToolSudo},
 		InstallResults:       []InstallResult{},
 		ToolStartTimes:       make(map[string]int64),
 		Tools:                tools.Tools,
@@ -159,6 +250,12 @@ func (s *State) ResetActionState() {
 	s.SpinnerFrame = 0
 	s.InstallStartTime = 0
 	s.ToolStartTimes = make(map[string]int64)
+	s.ToolProgress = nil
+	s.ProgressOrder = nil
+	s.FlightSteps = nil
+	s.NewlyInstalled = nil
+	s.ShowRollbackConfirm = false
+	s.RollingBack = false
 	s.SelectedTools = make(map[string]bool)
 	s.ToolsScroll.JumpToFirst()
 	s.ActionScroll.JumpToFirst()
@@ -0,0 +1,76 @@
+package models
+
+import "github.com/youpele52/lazysetup/pkg/config"
+
+// GetAIConfig safely gets the AI settings
+func (s *State) GetAIConfig() config.AIConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.AIConfig
+}
+
+// GetShowAIPanel safely gets whether the AI suggestions view is shown
+func (s *State) GetShowAIPanel() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ShowAIPanel
+}
+
+// SetShowAIPanel safely sets whether the AI suggestions view is shown
+func (s *State) SetShowAIPanel(show bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ShowAIPanel = show
+}
+
+// GetAIPending safely gets whether suggestions are being requested
+func (s *State) GetAIPending() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.AIPending
+}
+
+// SetAIPending safely sets whether suggestions are being requested
+func (s *State) SetAIPending(pending bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.AIPending = pending
+}
+
+// GetAIMessage safely gets the AI panel message
+func (s *State) GetAIMessage() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.AIMessage
+}
+
+// SetAIMessage safely sets the AI panel message
+func (s *State) SetAIMessage(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.AIMessage = message
+}
+
+// AddAISuggestion safely records a suggestion for a failed tool
+func (s *State) AddAISuggestion(suggestion AISuggestion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.AISuggestions = append(s.AISuggestions, suggestion)
+}
+
+// GetAISuggestions safely gets a copy of the suggestions
+func (s *State) GetAISuggestions() []AISuggestion {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	suggestions := make([]AISuggestion, len(s.AISuggestions))
+	copy(suggestions, s.AISuggestions)
+	return suggestions
+}
+
+// ClearAISuggestions safely removes all suggestions and the panel message
+func (s *State) ClearAISuggestions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.AISuggestions = nil
+	s.AIMessage = ""
+}
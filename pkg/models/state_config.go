@@ -0,0 +1,108 @@
+package models
+
+import (
+	"github.com/youpele52/lazysetup/pkg/cache"
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/keymap"
+	"github.com/youpele52/lazysetup/pkg/privilege"
+	"github.com/youpele52/lazysetup/pkg/repos"
+)
+
+// NewStateFromConfig creates the initial state with the config file's settings applied:
+// the default method and action are preselected and parallelism, timeouts and the rest
+// are taken from cfg
+func NewStateFromConfig(cfg *config.UserConfig) *State {
+	state := NewState()
+	state.ApplyUserConfig(cfg)
+	return state
+}
+
+// applyAppConfig selects the default method and action and stores the application settings
+// Callers must hold s.mu
+func (s *State) applyAppConfig(cfg *config.UserConfig) {
+	method := cfg.GetDefaultMethod()
+	for i, known := range s.InstallMethods {
+		if known == method {
+			s.SelectedMethod = method
+			s.PackageManagerScroll.Cursor = i
+			break
+		}
+	}
+	for i, action := range config.ActionNames {
+		if action == cfg.GetDefaultAction() {
+			s.SelectedAction = ActionType(i)
+			s.ActionScroll.Cursor = i
+			break
+		}
+	}
+
+	s.Parallelism = cfg.GetParallelism()
+	if km, err := keymap.New(cfg.Keybindings); err == nil {
+		s.Keymap = km
+	}
+	// Action logs are best effort, an unknown home directory only disables them
+	s.LogDir, _ = cfg.GetLogDir()
+	s.LogKeep = cfg.Logs.GetKeep()
+	s.Downloads = cfg.Cache.Open()
+	s.Repos = cfg.Repos.Open()
+	s.Escalation = cfg.Escalation()
+}
+
+// GetParallelism safely gets how many tools an action runs at once
+func (s *State) GetParallelism() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.Parallelism < 1 {
+		return config.DefaultParallelism
+	}
+	return s.Parallelism
+}
+
+// GetEscalation safely gets how each method's commands run as root
+func (s *State) GetEscalation() privilege.Escalation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Escalation
+}
+
+// SetEscalation safely sets how each method's commands run as root
+func (s *State) SetEscalation(escalation privilege.Escalation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Escalation = escalation
+}
+
+// GetDownloads safely gets the shared download cache, nil when caching is disabled
+func (s *State) GetDownloads() *cache.Cache {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Downloads
+}
+
+// GetRepos safely gets the records of added vendor repositories, nil when adding them is disabled
+func (s *State) GetRepos() *repos.Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Repos
+}
+
+// GetLogDir safely gets where action logs are written, empty when logging is disabled
+func (s *State) GetLogDir() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.LogDir
+}
+
+// GetLogKeep safely gets how many action logs are kept
+func (s *State) GetLogKeep() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.LogKeep
+}
+
+// GetKeymap safely gets the key bound to each action
+func (s *State) GetKeymap() *keymap.Keymap {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Keymap
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+)
+
+// TestNewStateFromConfig tests creating the initial state from the config file.
+// Priority: P1 - The configured default method, action and parallelism must be used at startup.
+// Tests preselection of method and action and the parallelism default.
+func TestNewStateFromConfig(t *testing.T) {
+	t.Run("preselects default method and action", func(t *testing.T) {
+		state := NewStateFromConfig(&config.UserConfig{DefaultMethod: "APT", DefaultAction: "update", Parallelism: 2})
+
+		if state.SelectedMethod != "APT" || state.InstallMethods[state.PackageManagerScroll.Cursor] != "APT" {
+			t.Errorf("Expected APT selected, got %s at cursor %d", state.SelectedMethod, state.PackageManagerScroll.Cursor)
+		}
+		if state.GetSelectedAction() != ActionUpdate || state.ActionScroll.Cursor != int(ActionUpdate) {
+			t.Errorf("Expected update selected, got %v at cursor %d", state.GetSelectedAction(), state.ActionScroll.Cursor)
+		}
+		if state.GetParallelism() != 2 {
+			t.Errorf("Expected parallelism 2, got %d", state.GetParallelism())
+		}
+	})
+
+	t.Run("empty config keeps defaults", func(t *testing.T) {
+		state := NewStateFromConfig(&config.UserConfig{})
+
+		if state.SelectedMethod != config.InstallMethods[0] || state.GetSelectedAction() != ActionCheck {
+			t.Errorf("Expected default method and check, got %s and %v", state.SelectedMethod, state.GetSelectedAction())
+		}
+		if state.GetParallelism() != config.DefaultParallelism {
+			t.Errorf("Expected default parallelism, got %d", state.GetParallelism())
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"context"
+	"sort"
+)
+
+// inFlightTool is the cancellation context of one running tool
+type inFlightTool struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// StartToolContext safely derives a cancellation context for a tool from CancelCtx
+// Cancelling it stops that tool only; cancelling CancelCtx still stops every tool
+func (s *State) StartToolContext(tool string) context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ToolCancels == nil {
+		s.ToolCancels = make(map[string]inFlightTool)
+	}
+	ctx, cancel := context.WithCancel(s.CancelCtx)
+	s.ToolCancels[tool] = inFlightTool{ctx: ctx, cancel: cancel}
+	return ctx
+}
+
+// FinishToolContext safely releases a tool's cancellation context once it stops running
+func (s *State) FinishToolContext(tool string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.ToolCancels[tool]; ok {
+		entry.cancel()
+		delete(s.ToolCancels, tool)
+	}
+}
+
+// GetToolContext safely gets the cancellation context of a running tool
+// Falls back to CancelCtx for tools started without their own context
+func (s *State) GetToolContext(tool string) context.Context {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if entry, ok := s.ToolCancels[tool]; ok {
+		return entry.ctx
+	}
+	return s.CancelCtx
+}
+
+// CancelTool safely cancels one running tool, leaving the others running
+// Returns false when the tool is not running
+func (s *State) CancelTool(tool string) bool {
+	s.mu.RLock()
+	entry, ok := s.ToolCancels[tool]
+	s.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	entry.cancel()
+	return true
+}
+
+// GetInFlightTools safely lists the running tools, longest running first
+func (s *State) GetInFlightTools() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tools := make([]string, 0, len(s.ToolCancels))
+	for tool := range s.ToolCancels {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		ti, tj := s.ToolStartTimes[tools[i]], s.ToolStartTimes[tools[j]]
+		if ti != tj {
+			return ti < tj
+		}
+		return tools[i] < tools[j]
+	})
+	return tools
+}
+
+// GetQueuePaused safely gets whether queued tools are held back
+func (s *State) GetQueuePaused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.QueuePaused
+}
+
+// SetQueuePaused safely sets whether queued tools are held back
+func (s *State) SetQueuePaused(paused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.QueuePaused = paused
+}
+
+// ToggleQueuePaused safely flips whether queued tools are held back
+func (s *State) ToggleQueuePaused() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.QueuePaused = !s.QueuePaused
+}
+
+// GetInFlightCursor safely gets the selected entry of the running tools list
+func (s *State) GetInFlightCursor() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.InFlightCursor
+}
+
+// SetInFlightCursor safely sets the selected entry of the running tools list
+func (s *State) SetInFlightCursor(cursor int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.InFlightCursor = cursor
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+)
+
+// TestToolContexts tests per-tool cancellation.
+// Priority: P0 - Cancelling one stuck tool must not kill the rest of the batch.
+// Tests that cancelling a tool leaves the others running and that aborting still stops all.
+func TestToolContexts(t *testing.T) {
+	t.Run("cancelling one tool leaves the others running", func(t *testing.T) {
+		state := NewState()
+		python := state.StartToolContext("python3")
+		git := state.StartToolContext("git")
+
+		if !state.CancelTool("python3") {
+			t.Fatal("Expected python3 to be cancellable")
+		}
+		if python.Err() == nil {
+			t.Error("Expected python3 context to be cancelled")
+		}
+		if git.Err() != nil {
+			t.Error("Expected git context to keep running")
+		}
+	})
+
+	t.Run("cancelling the action cancels every tool", func(t *testing.T) {
+		state := NewState()
+		python := state.StartToolContext("python3")
+		git := state.StartToolContext("git")
+
+		state.CancelInstallations()
+		if python.Err() == nil || git.Err() == nil {
+			t.Error("Expected every tool context to be cancelled")
+		}
+	})
+
+	t.Run("finished tools leave the running list", func(t *testing.T) {
+		state := NewState()
+		state.StartToolContext("git")
+		state.FinishToolContext("git")
+
+		if len(state.GetInFlightTools()) != 0 {
+			t.Errorf("Expected no running tools, got %v", state.GetInFlightTools())
+		}
+		if state.CancelTool("git") {
+			t.Error("Expected a finished tool not to be cancellable")
+		}
+		if state.GetToolContext("git") != state.GetCancelContext() {
+			t.Error("Expected a finished tool to fall back to the shared context")
+		}
+	})
+
+	t.Run("running tools are listed longest running first", func(t *testing.T) {
+		state := NewState()
+		state.SetToolStartTime("git", 200)
+		state.SetToolStartTime("python3", 100)
+		state.StartToolContext("git")
+		state.StartToolContext("python3")
+
+		tools := state.GetInFlightTools()
+		if len(tools) != 2 || tools[0] != "python3" || tools[1] != "git" {
+			t.Errorf("Expected [python3 git], got %v", tools)
+		}
+	})
+}
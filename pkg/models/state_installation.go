@@ -29,10 +29,14 @@ func (s *State) AddInstallResult(result InstallResult) {
 }
 
 // ClearInstallResults safely clears all installation results
+// AI suggestions belong to the results, so they are cleared and hidden too
 func (s *State) ClearInstallResults() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.InstallResults = []InstallResult{}
+	s.AISuggestions = nil
+	s.AIMessage = ""
+	s.ShowAIPanel = false
 }
 
 // GetInstallResults safely gets a copy of installation results
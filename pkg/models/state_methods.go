@@ -95,6 +95,25 @@ func (s *State) SetSelectedTools(tools map[string]bool) {
 	s.SelectedTools = tools
 }
 
+// GetVersionPin safely gets the version constraint pinned for a tool
+// Returns empty string when the tool is not pinned
+func (s *State) GetVersionPin(tool string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.VersionPins[tool]
+}
+
+// SetVersionPin safely pins a tool to a version constraint; an empty constraint removes the pin
+func (s *State) SetVersionPin(tool, constraint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if constraint == "" {
+		delete(s.VersionPins, tool)
+		return
+	}
+	s.VersionPins[tool] = constraint
+}
+
 // GetCurrentPage safely gets the current page
 func (s *State) GetCurrentPage() Page {
 	s.mu.RLock()
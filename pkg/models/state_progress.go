@@ -0,0 +1,185 @@
+package models
+
+import "github.com/youpele52/lazysetup/pkg/history"
+
+// ToolPhase is where a tool is in the running action
+type ToolPhase string
+
+const (
+	PhaseQueued    ToolPhase = "queued"
+	PhaseRunning   ToolPhase = "running"
+	PhaseRetrying  ToolPhase = "retrying"
+	PhaseSucceeded ToolPhase = "succeeded"
+	PhaseFailed    ToolPhase = "failed"
+)
+
+// ToolProgress is one row of the progress dashboard
+type ToolProgress struct {
+	Tool      string
+	Phase     ToolPhase
+	StartTime int64  // Unix timestamp when the tool started, 0 while queued
+	EndTime   int64  // Unix timestamp when the tool finished, 0 until then
+	Attempt   int    // Current attempt, above 1 once retried
+	LastLine  string // Latest line of command output
+	Expected  int64  // Usual duration in seconds from past runs, 0 when unknown
+}
+
+// Done reports whether the tool has finished, successfully or not
+func (p ToolProgress) Done() bool {
+	return p.Phase == PhaseSucceeded || p.Phase == PhaseFailed
+}
+
+// FlightStage is when a flight command runs relative to a batch's tool commands
+type FlightStage string
+
+const (
+	StagePreflight  FlightStage = "pre-flight"  // Before the first tool command of the method
+	StagePostflight FlightStage = "post-flight" // After every tool finished
+)
+
+// FlightStep is a pre-flight or post-flight command of the running action, one per method
+type FlightStep struct {
+	Stage     FlightStage
+	Method    string
+	Phase     ToolPhase // PhaseRunning until the command finishes
+	StartTime int64     // Unix timestamp when the command started
+	EndTime   int64     // Unix timestamp when the command finished, 0 until then
+}
+
+// Done reports whether the command has finished, successfully or not
+func (f FlightStep) Done() bool {
+	return f.Phase == PhaseSucceeded || f.Phase == PhaseFailed
+}
+
+// InitToolProgress safely starts a dashboard with every tool queued
+func (s *State) InitToolProgress(tools []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ToolProgress = make(map[string]*ToolProgress, len(tools))
+	s.ProgressOrder = append([]string(nil), tools...)
+	s.FlightSteps = nil
+	for _, tool := range tools {
+		s.ToolProgress[tool] = &ToolProgress{Tool: tool, Phase: PhaseQueued}
+	}
+}
+
+// updateToolProgress applies update to a tool's row; callers must hold s.mu
+// Tools without a row (actions that did not init the dashboard) are ignored
+func (s *State) updateToolProgress(tool string, update func(*ToolProgress)) {
+	if progress, ok := s.ToolProgress[tool]; ok {
+		update(progress)
+	}
+}
+
+// MarkToolRunning safely records that a tool left the queue
+func (s *State) MarkToolRunning(tool string, startTime int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateToolProgress(tool, func(p *ToolProgress) {
+		p.Phase = PhaseRunning
+		p.StartTime = startTime
+		p.Attempt = 1
+	})
+}
+
+// MarkToolAttempt safely records the attempt a tool is on; attempts after the first show as
+// retrying, and a fallback method starting over at attempt 1 shows as running again
+func (s *State) MarkToolAttempt(tool string, attempt int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateToolProgress(tool, func(p *ToolProgress) {
+		p.Attempt = attempt
+		p.Phase = PhaseRunning
+		if attempt > 1 {
+			p.Phase = PhaseRetrying
+		}
+	})
+}
+
+// MarkToolDone safely records how a tool finished
+func (s *State) MarkToolDone(tool string, success bool, endTime int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateToolProgress(tool, func(p *ToolProgress) {
+		p.Phase = PhaseFailed
+		if success {
+			p.Phase = PhaseSucceeded
+		}
+		p.EndTime = endTime
+	})
+}
+
+// SetToolExpected safely records how many seconds a tool usually takes
+func (s *State) SetToolExpected(tool string, seconds int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateToolProgress(tool, func(p *ToolProgress) {
+		p.Expected = seconds
+	})
+}
+
+// SetToolLastLine safely records the latest output line of a tool
+func (s *State) SetToolLastLine(tool, line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateToolProgress(tool, func(p *ToolProgress) {
+		p.LastLine = line
+	})
+}
+
+// StartFlightStep safely records that a method's flight command started
+func (s *State) StartFlightStep(stage FlightStage, method string, startTime int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.FlightSteps = append(s.FlightSteps, FlightStep{Stage: stage, Method: method, Phase: PhaseRunning, StartTime: startTime})
+}
+
+// FinishFlightStep safely records how a method's flight command finished
+func (s *State) FinishFlightStep(stage FlightStage, method string, success bool, endTime int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.FlightSteps {
+		step := &s.FlightSteps[i]
+		if step.Stage == stage && step.Method == method && !step.Done() {
+			step.Phase = PhaseFailed
+			if success {
+				step.Phase = PhaseSucceeded
+			}
+			step.EndTime = endTime
+		}
+	}
+}
+
+// GetFlightSteps safely gets a copy of the running action's flight commands in start order
+func (s *State) GetFlightSteps() []FlightStep {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]FlightStep(nil), s.FlightSteps...)
+}
+
+// GetToolProgress safely gets a copy of the dashboard rows in selection order
+func (s *State) GetToolProgress() []ToolProgress {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rows := make([]ToolProgress, 0, len(s.ProgressOrder))
+	for _, tool := range s.ProgressOrder {
+		if progress, ok := s.ToolProgress[tool]; ok {
+			rows = append(rows, *progress)
+		}
+	}
+	return rows
+}
+
+// GetDurationHistory safely gets the store of past action durations
+func (s *State) GetDurationHistory() *history.Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.DurationHistory
+}
+
+// SetDurationHistory safely sets the store of past action durations
+func (s *State) SetDurationHistory(store *history.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.DurationHistory = store
+}
@@ -0,0 +1,95 @@
+package models
+
+import "testing"
+
+// TestToolProgress tests the per-tool rows behind the progress dashboard.
+// Priority: P1 - The dashboard must show each tool's real state during parallel actions.
+// Tests the queued, running, retrying and finished phases and the row order.
+func TestToolProgress(t *testing.T) {
+	t.Run("tools start queued in selection order", func(t *testing.T) {
+		state := NewState()
+		state.InitToolProgress([]string{"git", "python3"})
+
+		rows := state.GetToolProgress()
+		if len(rows) != 2 || rows[0].Tool != "git" || rows[1].Tool != "python3" {
+			t.Fatalf("Expected rows [git python3], got %+v", rows)
+		}
+		for _, row := range rows {
+			if row.Phase != PhaseQueued {
+				t.Errorf("Expected %s to be queued, got %s", row.Tool, row.Phase)
+			}
+		}
+	})
+
+	t.Run("phases follow the tool", func(t *testing.T) {
+		state := NewState()
+		state.InitToolProgress([]string{"git"})
+
+		state.MarkToolRunning("git", 100)
+		state.SetToolLastLine("git", "Unpacking git ...")
+		if row := state.GetToolProgress()[0]; row.Phase != PhaseRunning || row.LastLine != "Unpacking git ..." {
+			t.Errorf("Expected running with last line, got %+v", row)
+		}
+
+		state.MarkToolAttempt("git", 2)
+		if row := state.GetToolProgress()[0]; row.Phase != PhaseRetrying || row.Attempt != 2 {
+			t.Errorf("Expected retrying attempt 2, got %+v", row)
+		}
+
+		state.MarkToolDone("git", true, 105)
+		row := state.GetToolProgress()[0]
+		if row.Phase != PhaseSucceeded || !row.Done() || row.EndTime-row.StartTime != 5 {
+			t.Errorf("Expected succeeded after 5s, got %+v", row)
+		}
+	})
+
+	t.Run("untracked tools are ignored", func(t *testing.T) {
+		state := NewState()
+		state.MarkToolRunning("git", 100)
+		if rows := state.GetToolProgress(); len(rows) != 0 {
+			t.Errorf("Expected no rows, got %+v", rows)
+		}
+	})
+}
+
+// TestFlightSteps tests the pre-flight and post-flight rows of the progress dashboard.
+// Priority: P2 - Index refreshes take a while and must not look like a hung batch.
+// Tests start order, finishing the right step and clearing on a new action.
+func TestFlightSteps(t *testing.T) {
+	t.Run("steps keep their start order", func(t *testing.T) {
+		state := NewState()
+		state.StartFlightStep(StagePreflight, "APT", 100)
+		state.StartFlightStep(StagePreflight, "Homebrew", 101)
+		state.FinishFlightStep(StagePreflight, "Homebrew", false, 103)
+
+		steps := state.GetFlightSteps()
+		if len(steps) != 2 || steps[0].Method != "APT" || steps[1].Method != "Homebrew" {
+			t.Fatalf("Expected steps [APT Homebrew], got %+v", steps)
+		}
+		if steps[0].Phase != PhaseRunning || steps[1].Phase != PhaseFailed || steps[1].EndTime != 103 {
+			t.Errorf("Expected APT running and Homebrew failed, got %+v", steps)
+		}
+	})
+
+	t.Run("pre-flight and post-flight of a method are separate", func(t *testing.T) {
+		state := NewState()
+		state.StartFlightStep(StagePreflight, "APT", 100)
+		state.FinishFlightStep(StagePreflight, "APT", true, 102)
+		state.StartFlightStep(StagePostflight, "APT", 110)
+
+		steps := state.GetFlightSteps()
+		if steps[0].Phase != PhaseSucceeded || steps[1].Phase != PhaseRunning {
+			t.Errorf("Expected pre-flight done and post-flight running, got %+v", steps)
+		}
+	})
+
+	t.Run("a new action clears the steps", func(t *testing.T) {
+		state := NewState()
+		state.StartFlightStep(StagePreflight, "APT", 100)
+		state.InitToolProgress([]string{"git"})
+
+		if steps := state.GetFlightSteps(); len(steps) != 0 {
+			t.Errorf("Expected no steps, got %+v", steps)
+		}
+	})
+}
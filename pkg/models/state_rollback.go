@@ -0,0 +1,66 @@
+package models
+
+// GetTransactionalMode safely gets whether transactional installs are enabled
+func (s *State) GetTransactionalMode() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.TransactionalMode
+}
+
+// ToggleTransactionalMode safely flips transactional install mode
+func (s *State) ToggleTransactionalMode() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.TransactionalMode = !s.TransactionalMode
+}
+
+// AddNewlyInstalled safely records a tool installed by the current batch
+func (s *State) AddNewlyInstalled(tool string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.NewlyInstalled = append(s.NewlyInstalled, tool)
+}
+
+// GetNewlyInstalled safely gets a copy of the tools installed by the current batch
+func (s *State) GetNewlyInstalled() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tools := make([]string, len(s.NewlyInstalled))
+	copy(tools, s.NewlyInstalled)
+	return tools
+}
+
+// ClearNewlyInstalled safely forgets the tools installed by the current batch
+func (s *State) ClearNewlyInstalled() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.NewlyInstalled = nil
+}
+
+// GetShowRollbackConfirm returns whether the rollback confirmation popup is visible
+func (s *State) GetShowRollbackConfirm() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ShowRollbackConfirm
+}
+
+// SetShowRollbackConfirm sets the rollback confirmation popup visibility
+func (s *State) SetShowRollbackConfirm(show bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ShowRollbackConfirm = show
+}
+
+// GetRollingBack safely gets whether a rollback is running
+func (s *State) GetRollingBack() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.RollingBack
+}
+
+// SetRollingBack safely sets whether a rollback is running
+func (s *State) SetRollingBack(rollingBack bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.RollingBack = rollingBack
+}
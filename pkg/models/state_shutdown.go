@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// actionPollInterval is how often WaitForActions checks whether the actions have finished
+const actionPollInterval = 50 * time.Millisecond
+
+// BeginAction safely records that a background action started
+func (s *State) BeginAction() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.RunningActions++
+}
+
+// EndAction safely records that a background action finished
+func (s *State) EndAction() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.RunningActions > 0 {
+		s.RunningActions--
+	}
+}
+
+// GetRunningActions safely gets how many background actions have not finished
+func (s *State) GetRunningActions() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.RunningActions
+}
+
+// WaitForActions waits up to timeout for every background action to finish
+// Returns false when some were still running at the deadline
+func (s *State) WaitForActions(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for s.GetRunningActions() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(actionPollInterval)
+	}
+	return true
+}
+
+// GetLastAction safely gets the tool action started last
+func (s *State) GetLastAction() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.LastAction
+}
+
+// SetLastAction safely sets the tool action started last
+func (s *State) SetLastAction(action string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.LastAction = action
+}
+
+// GetShowQuitConfirm returns whether the quit confirmation popup is visible
+func (s *State) GetShowQuitConfirm() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ShowQuitConfirm
+}
+
+// SetShowQuitConfirm sets the quit confirmation popup visibility
+func (s *State) SetShowQuitConfirm(show bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ShowQuitConfirm = show
+}
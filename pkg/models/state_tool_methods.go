@@ -0,0 +1,139 @@
+package models
+
+import (
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/retry"
+)
+
+// GetToolMethod safely gets the package manager override for a tool
+// Returns empty string when the tool uses the selected method
+func (s *State) GetToolMethod(tool string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ToolMethods[tool]
+}
+
+// SetToolMethod safely sets the package manager override for a tool; an empty method removes it
+func (s *State) SetToolMethod(tool, method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if method == "" {
+		delete(s.ToolMethods, tool)
+		return
+	}
+	s.ToolMethods[tool] = method
+}
+
+// CycleToolMethod safely moves a tool's override to the next install method
+// Cycles through every method and then back to no override
+func (s *State) CycleToolMethod(tool string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := ""
+	current := s.ToolMethods[tool]
+	for i, method := range s.InstallMethods {
+		if current == "" {
+			next = method
+			break
+		}
+		if method == current {
+			if i+1 < len(s.InstallMethods) {
+				next = s.InstallMethods[i+1]
+			}
+			break
+		}
+	}
+
+	if next == "" {
+		delete(s.ToolMethods, tool)
+	} else {
+		s.ToolMethods[tool] = next
+	}
+	return next
+}
+
+// GetPreferredMethod safely gets the method a tool should run with before any fallback
+func (s *State) GetPreferredMethod(tool string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if method, ok := s.ToolMethods[tool]; ok {
+		return method
+	}
+	return s.SelectedMethod
+}
+
+// GetFallbackMethods safely gets a copy of the fallback chain
+func (s *State) GetFallbackMethods() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	methods := make([]string, len(s.FallbackMethods))
+	copy(methods, s.FallbackMethods)
+	return methods
+}
+
+// GetFallbackPolicy safely gets the fallback policy
+func (s *State) GetFallbackPolicy() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.FallbackPolicy
+}
+
+// SetFallbackPolicy safely sets the fallback policy
+func (s *State) SetFallbackPolicy(policy string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.FallbackPolicy = policy
+}
+
+// GetRetryPolicy safely gets the retry policy
+func (s *State) GetRetryPolicy() retry.Policy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.RetryPolicy
+}
+
+// SetRetryPolicy safely sets the retry policy
+func (s *State) SetRetryPolicy(policy retry.Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.RetryPolicy = policy
+}
+
+// ApplyUserConfig safely loads per-tool methods, version pins, the fallback chain, AI settings,
+// the retry policy, timeouts, flight commands and the application settings from the config file
+func (s *State) ApplyUserConfig(cfg *config.UserConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.applyAppConfig(cfg)
+
+	s.FallbackMethods = cfg.FallbackMethods()
+	s.FallbackPolicy = cfg.GetFallbackPolicy()
+	s.AIConfig = cfg.AI
+	s.RetryPolicy = retry.FromConfig(cfg.Retry)
+	s.Timeouts = cfg.CommandTimeouts()
+	s.Flights = cfg.FlightCommands()
+	for tool, toolConfig := range cfg.Tools {
+		if toolConfig.Method != "" {
+			s.ToolMethods[tool] = toolConfig.Method
+		}
+		if toolConfig.Version != "" {
+			s.VersionPins[tool] = toolConfig.Version
+		}
+	}
+}
+
+// GetFlights safely gets the commands each method runs before and after a batch
+func (s *State) GetFlights() config.Flights {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Flights
+}
+
+// GetTimeouts safely gets how long each command may run
+func (s *State) GetTimeouts() config.Timeouts {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Timeouts
+}
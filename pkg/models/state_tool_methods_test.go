@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+)
+
+// TestState_CycleToolMethod tests cycling a tool's package manager override.
+// Priority: P2 - The Tools panel 'm' key must reach every method and return to the default.
+// Tests the full cycle and the preferred method at each step.
+func TestState_CycleToolMethod(t *testing.T) {
+	t.Run("cycles through every method and back to none", func(t *testing.T) {
+		state := NewState()
+		state.SetSelectedMethod("APT")
+
+		for _, want := range state.InstallMethods {
+			if got := state.CycleToolMethod("git"); got != want {
+				t.Fatalf("Expected %s, got %s", want, got)
+			}
+			if got := state.GetPreferredMethod("git"); got != want {
+				t.Errorf("Expected preferred method %s, got %s", want, got)
+			}
+		}
+
+		if got := state.CycleToolMethod("git"); got != "" {
+			t.Errorf("Expected override to be cleared, got %s", got)
+		}
+		if got := state.GetPreferredMethod("git"); got != "APT" {
+			t.Errorf("Expected selected method APT, got %s", got)
+		}
+	})
+}
+
+// TestState_ApplyUserConfig tests loading config file settings into state.
+// Priority: P1 - Config file methods and pins must reach the installer.
+// Tests that per-tool methods, versions and the fallback chain are applied.
+func TestState_ApplyUserConfig(t *testing.T) {
+	t.Run("applies methods, pins and fallback chain", func(t *testing.T) {
+		state := NewState()
+		state.ApplyUserConfig(&config.UserConfig{
+			Fallback: []string{"Homebrew"},
+			Tools: map[string]config.ToolConfig{
+				"lazygit":   {Method: "Curl"},
+				"terraform": {Version: "1.9.x"},
+			},
+		})
+
+		if got := state.GetToolMethod("lazygit"); got != "Curl" {
+			t.Errorf("Expected lazygit method Curl, got %q", got)
+		}
+		if got := state.GetToolMethod("terraform"); got != "" {
+			t.Errorf("Expected no terraform override, got %q", got)
+		}
+		if got := state.GetVersionPin("terraform"); got != "1.9.x" {
+			t.Errorf("Expected terraform pin 1.9.x, got %q", got)
+		}
+		if got := state.GetFallbackMethods(); len(got) != 1 || got[0] != "Homebrew" {
+			t.Errorf("Expected fallback [Homebrew], got %v", got)
+		}
+	})
+}
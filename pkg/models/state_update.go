@@ -0,0 +1,15 @@
+package models
+
+// GetShowReleaseNotes returns whether the release notes popup is open
+func (s *State) GetShowReleaseNotes() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ShowReleaseNotes
+}
+
+// SetShowReleaseNotes sets the release notes popup visibility
+func (s *State) SetShowReleaseNotes(show bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ShowReleaseNotes = show
+}
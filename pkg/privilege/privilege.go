@@ -0,0 +1,238 @@
+package privilege
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/youpele52/lazysetup/pkg/commands"
+)
+
+// Escalation tools; ToolAuto picks the first one installed, ToolNone never escalates
+const (
+	ToolAuto   = "auto"
+	ToolSudo   = "sudo"
+	ToolDoas   = "doas"
+	ToolPkexec = "pkexec"
+	ToolRun0   = "run0"
+	ToolNone   = "none"
+)
+
+// Tools lists the accepted privilege.tool values
+var Tools = []string{ToolAuto, ToolSudo, ToolDoas, ToolPkexec, ToolRun0, ToolNone}
+
+// detectOrder is the order ToolAuto looks for an installed escalation tool in
+var detectOrder = []string{ToolSudo, ToolDoas, ToolRun0, ToolPkexec}
+
+// Policies decide how a method's commands are escalated
+const (
+	PolicyAlways = "always" // The whole command runs as root
+	PolicyInline = "inline" // Only the steps the command marks with sudo run as root
+	PolicyNever  = "never"  // The command runs as the current user
+)
+
+// Policies lists the accepted per-method policy values
+var Policies = []string{PolicyAlways, PolicyInline, PolicyNever}
+
+// DefaultPolicies are the policies of methods that need root; every other method is PolicyNever
+// Curl recipes download as the user and mark the steps that install into system paths
+var DefaultPolicies = map[string]string{
+	"APT":    PolicyAlways,
+	"YUM":    PolicyAlways,
+	"DNF":    PolicyAlways,
+	"Pacman": PolicyAlways,
+	"Curl":   PolicyInline,
+}
+
+// inlineMarker matches the sudo prefixes of the steps an inline command runs as root
+var inlineMarker = regexp.MustCompile(`(^|[;&|(]\s*)sudo\s+`)
+
+// passwordVar is the shell variable an inline command keeps the sudo password in; it is not
+// exported, so it never reaches the environment of the commands the recipe runs
+const passwordVar = "lazysetup_password"
+
+// Escalation decides whether and how a method's commands are run as root
+type Escalation struct {
+	Tool     string            // Escalation tool commands are wrapped with, "" when none is usable
+	Root     bool              // Whether lazysetup already runs as root, so nothing is wrapped
+	Policies map[string]string // Per-method policy overrides of DefaultPolicies
+
+	// Whether the tool may ask for a password on the terminal, as in the CLI; the TUI owns it
+	Interactive bool
+}
+
+// New returns the escalation for this process: tool is resolved with Detect and root is
+// detected from the effective user ID, as in containers that run as root without sudo
+func New(tool string, policies map[string]string) Escalation {
+	return Escalation{
+		Tool:     Detect(tool, exec.LookPath),
+		Root:     IsRoot(),
+		Policies: policies,
+	}
+}
+
+// IsRoot reports whether lazysetup runs as root
+func IsRoot() bool {
+	return os.Geteuid() == 0
+}
+
+// Detect resolves a configured tool to the one commands are wrapped with
+// ToolAuto, or "", returns the first installed tool in detectOrder; ToolNone returns ""
+// A named tool is returned even when lookPath cannot find it, so the command fails
+// with the shell's "not found" rather than silently running without privileges
+func Detect(tool string, lookPath func(string) (string, error)) string {
+	switch tool {
+	case ToolNone:
+		return ""
+	case ToolAuto, "":
+		for _, candidate := range detectOrder {
+			if _, err := lookPath(candidate); err == nil {
+				return candidate
+			}
+		}
+		return ""
+	}
+	return tool
+}
+
+// Policy returns the policy for a method's commands
+func (e Escalation) Policy(method string) string {
+	if policy, ok := e.Policies[method]; ok {
+		return policy
+	}
+	if policy, ok := DefaultPolicies[method]; ok {
+		return policy
+	}
+	return PolicyNever
+}
+
+// Elevates reports whether a method's commands are wrapped with the escalation tool
+func (e Escalation) Elevates(method string) bool {
+	return !e.Root && e.Tool != "" && e.Policy(method) != PolicyNever
+}
+
+// NeedsPassword reports whether a method's command needs the password popup
+// Only sudo reads a password from stdin; doas, pkexec and run0 cannot use it, see PromptError.
+// Inline commands need it only when they mark a step with sudo
+func (e Escalation) NeedsPassword(method, command string) bool {
+	return e.Tool == ToolSudo && e.escalates(method, command)
+}
+
+// escalates reports whether any part of a method's command is run with the escalation tool
+func (e Escalation) escalates(method, command string) bool {
+	if !e.Elevates(method) {
+		return false
+	}
+	return e.Policy(method) == PolicyAlways || inlineMarker.MatchString(command)
+}
+
+// runTerminal runs a command, attached to lazysetup's terminal unless quiet
+var runTerminal = func(quiet bool, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if !quiet {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	}
+	return cmd.Run()
+}
+
+// Authenticate lets sudo or doas ask for the password on the terminal before a method's
+// command runs, when the escalation is Interactive
+// Wrapped commands run in their own process group, where reading the terminal would stop
+// them, so they stay non-interactive and use the credentials cached here. Nothing is asked
+// when the tool already runs without a password; pkexec and run0 ask through polkit
+func (e Escalation) Authenticate(method, command string) error {
+	if !e.Interactive || !e.escalates(method, command) {
+		return nil
+	}
+	var args []string
+	switch e.Tool {
+	case ToolSudo:
+		args = []string{"-v"}
+	case ToolDoas:
+		args = []string{"true"}
+	default:
+		return nil
+	}
+	if runTerminal(true, e.Tool, "-n", "true") == nil {
+		return nil
+	}
+	if err := runTerminal(false, e.Tool, args...); err != nil {
+		return fmt.Errorf("%s authentication failed: %w", e.Tool, err)
+	}
+	return nil
+}
+
+// PromptError returns why a method's command cannot be escalated when the escalation is not
+// Interactive, as in the TUI, which owns the terminal; nil when it can
+// pkexec and run0 ask through polkit, whose text agent would fight the TUI for the terminal,
+// so they are refused. doas cannot be handed a password, so it is refused unless it runs
+// without asking, with a nopass rule or credentials a persist rule cached before lazysetup started
+func (e Escalation) PromptError(method, command string) error {
+	if e.Interactive || !e.escalates(method, command) {
+		return nil
+	}
+	switch e.Tool {
+	case ToolPkexec, ToolRun0:
+		return fmt.Errorf("%s asks for the password through polkit, which cannot share the terminal with lazysetup; set privilege.tool to sudo, or run lazysetup restore or bundle install from a shell", e.Tool)
+	case ToolDoas:
+		if runTerminal(true, ToolDoas, "-n", "true") != nil {
+			return errors.New("doas needs a password, which lazysetup cannot pass to it; add a nopass rule, run doas once before starting lazysetup with a persist rule, or set privilege.tool to sudo")
+		}
+	}
+	return nil
+}
+
+// Wrap returns the command to run for a method's command and the input to feed it
+// password is fed to sudo on stdin so it never appears in the process arguments; without
+// one sudo runs non-interactively and fails if it would have to ask
+// Inline commands get input only when they mark a step, and only the marked steps read it
+// As root, inline sudo markers are removed so recipes also work where sudo is not installed
+func (e Escalation) Wrap(method, command, password string) (string, string) {
+	policy := e.Policy(method)
+	if policy == PolicyNever {
+		return command, ""
+	}
+	if e.Root {
+		if policy == PolicyInline {
+			return inlineMarker.ReplaceAllString(command, "$1"), ""
+		}
+		return command, ""
+	}
+	if e.Tool == "" {
+		return command, ""
+	}
+
+	prefix, input := e.prefix(password)
+	if policy == PolicyInline {
+		if !inlineMarker.MatchString(command) {
+			return command, ""
+		}
+		if input == "" {
+			return inlineMarker.ReplaceAllString(command, "${1}"+prefix+" "), ""
+		}
+		// The password is read once and piped to each marked step alone; the rest of the
+		// recipe, which may run third-party scripts, reads /dev/null instead
+		step := `printf '%s\n' "$` + passwordVar + `" | ` + prefix + " "
+		inline := inlineMarker.ReplaceAllString(command, "${1}"+strings.ReplaceAll(step, "$", "$$"))
+		return "IFS= read -r " + passwordVar + "; exec </dev/null; " + inline, input
+	}
+	return fmt.Sprintf("%s sh -c %s", prefix, commands.ShellQuote(command)), input
+}
+
+// prefix returns the escalation tool's command line and the input it reads
+func (e Escalation) prefix(password string) (string, string) {
+	switch e.Tool {
+	case ToolSudo:
+		if password == "" {
+			return "sudo -n", ""
+		}
+		return "sudo -S -p ''", password + "\n"
+	case ToolDoas:
+		// The TUI owns the terminal, so doas must not prompt on it
+		return "doas -n", ""
+	}
+	return e.Tool, ""
+}
@@ -0,0 +1,318 @@
+package privilege
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// installed returns a lookPath that finds only the named tools
+func installed(tools ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, tool := range tools {
+			if tool == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+// TestDetect tests resolving the configured escalation tool.
+// Priority: P1 - Picking a missing tool makes every APT install fail.
+// Tests auto detection order, none, and explicitly named tools.
+func TestDetect(t *testing.T) {
+	t.Run("auto prefers sudo", func(t *testing.T) {
+		if got := Detect(ToolAuto, installed(ToolDoas, ToolSudo)); got != ToolSudo {
+			t.Errorf("Expected sudo, got %q", got)
+		}
+	})
+
+	t.Run("auto falls back to doas", func(t *testing.T) {
+		if got := Detect("", installed(ToolDoas, ToolPkexec)); got != ToolDoas {
+			t.Errorf("Expected doas, got %q", got)
+		}
+	})
+
+	t.Run("auto finds nothing", func(t *testing.T) {
+		if got := Detect(ToolAuto, installed()); got != "" {
+			t.Errorf("Expected no tool, got %q", got)
+		}
+	})
+
+	t.Run("none never escalates", func(t *testing.T) {
+		if got := Detect(ToolNone, installed(ToolSudo)); got != "" {
+			t.Errorf("Expected no tool, got %q", got)
+		}
+	})
+
+	t.Run("named tool is kept when missing", func(t *testing.T) {
+		if got := Detect(ToolRun0, installed(ToolSudo)); got != ToolRun0 {
+			t.Errorf("Expected run0, got %q", got)
+		}
+	})
+}
+
+// TestPolicy tests the per-method escalation policy.
+// Priority: P1 - DNF and Pacman commands must run as root like APT.
+// Tests defaults, overrides and the password requirement.
+func TestPolicy(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		e := Escalation{Tool: ToolSudo}
+		want := map[string]string{
+			"APT":      PolicyAlways,
+			"DNF":      PolicyAlways,
+			"Pacman":   PolicyAlways,
+			"YUM":      PolicyAlways,
+			"Curl":     PolicyInline,
+			"Homebrew": PolicyNever,
+			"Nix":      PolicyNever,
+		}
+		for method, policy := range want {
+			if got := e.Policy(method); got != policy {
+				t.Errorf("Expected %s for %s, got %s", policy, method, got)
+			}
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		e := Escalation{Tool: ToolSudo, Policies: map[string]string{"APT": PolicyNever, "Nix": PolicyAlways}}
+		if e.Policy("APT") != PolicyNever || e.Policy("Nix") != PolicyAlways {
+			t.Errorf("Expected overrides to apply, got APT=%s Nix=%s", e.Policy("APT"), e.Policy("Nix"))
+		}
+	})
+
+	t.Run("only sudo needs a password", func(t *testing.T) {
+		if !(Escalation{Tool: ToolSudo}).NeedsPassword("APT", "apt-get install -y git") {
+			t.Error("Expected sudo to need a password for APT")
+		}
+		if (Escalation{Tool: ToolSudo}).NeedsPassword("Homebrew", "brew install git") {
+			t.Error("Expected no password for Homebrew")
+		}
+		if (Escalation{Tool: ToolDoas}).NeedsPassword("APT", "apt-get install -y git") {
+			t.Error("Expected no password with doas")
+		}
+		if (Escalation{Tool: ToolSudo, Root: true}).NeedsPassword("APT", "apt-get install -y git") {
+			t.Error("Expected no password as root")
+		}
+	})
+
+	t.Run("inline commands need a password only with markers", func(t *testing.T) {
+		e := Escalation{Tool: ToolSudo}
+		if !e.NeedsPassword("Curl", "curl -fsSL https://example.com/x -o /tmp/x && sudo cp /tmp/x /usr/local/bin/") {
+			t.Error("Expected a password for a recipe that copies with sudo")
+		}
+		if e.NeedsPassword("Curl", "curl -fsSL https://example.com/install.sh | sh") {
+			t.Error("Expected no password for a recipe without sudo")
+		}
+	})
+}
+
+// TestWrap tests building the escalated command.
+// Priority: P0 - The password must never reach the process arguments.
+// Tests each policy, each tool, and running as root.
+func TestWrap(t *testing.T) {
+	const recipe = "curl -fsSL https://example.com/x.tar.gz -o /tmp/x.tar.gz && cd /tmp && tar -xzf x.tar.gz && sudo cp x /usr/local/bin/"
+
+	t.Run("always wraps the whole command", func(t *testing.T) {
+		e := Escalation{Tool: ToolSudo}
+		cmd, input := e.Wrap("APT", "apt-get install -y git && echo 'done'", "secret")
+		want := `sudo -S -p '' sh -c 'apt-get install -y git && echo '\''done'\'''`
+		if cmd != want {
+			t.Errorf("Expected %s, got %s", want, cmd)
+		}
+		if input != "secret\n" {
+			t.Errorf("Expected the password on stdin, got %q", input)
+		}
+	})
+
+	t.Run("sudo without a password runs non-interactively", func(t *testing.T) {
+		cmd, input := Escalation{Tool: ToolSudo}.Wrap("DNF", "dnf install -y git", "")
+		if cmd != "sudo -n sh -c 'dnf install -y git'" || input != "" {
+			t.Errorf("Unexpected command %q with input %q", cmd, input)
+		}
+	})
+
+	t.Run("inline replaces the sudo markers", func(t *testing.T) {
+		cmd, _ := Escalation{Tool: ToolDoas}.Wrap("Curl", recipe, "")
+		want := "curl -fsSL https://example.com/x.tar.gz -o /tmp/x.tar.gz && cd /tmp && tar -xzf x.tar.gz && doas -n cp x /usr/local/bin/"
+		if cmd != want {
+			t.Errorf("Expected %s, got %s", want, cmd)
+		}
+	})
+
+	t.Run("inline without a sudo step gets no input", func(t *testing.T) {
+		script := "curl -fsSL https://example.com/install.sh -o /tmp/install.sh && sh /tmp/install.sh"
+		cmd, input := Escalation{Tool: ToolSudo}.Wrap("Curl", script, "secret")
+		if cmd != script || input != "" {
+			t.Errorf("Expected %s without input, got %s with %q", script, cmd, input)
+		}
+	})
+
+	t.Run("inline feeds the password to the marked steps only", func(t *testing.T) {
+		dir := t.TempDir()
+		fake := "#!/bin/sh\nwhile [ \"$1\" = -S ] || [ \"$1\" = -p ]; do [ \"$1\" = -p ] && shift; shift; done\ncat > \"$SUDO_STDIN\"\n"
+		if err := os.WriteFile(filepath.Join(dir, "sudo"), []byte(fake), 0755); err != nil {
+			t.Fatal(err)
+		}
+		script := filepath.Join(dir, "script.out")
+		sudo := filepath.Join(dir, "sudo.out")
+
+		cmd, input := Escalation{Tool: ToolSudo}.Wrap("Curl", "cat > "+script+" && sudo true", "secret")
+		if strings.Contains(cmd, "secret") {
+			t.Fatalf("Password leaked into the command: %s", cmd)
+		}
+		run := exec.Command("sh", "-c", cmd)
+		run.Stdin = strings.NewReader(input)
+		run.Env = append(os.Environ(), "PATH="+dir+":"+os.Getenv("PATH"), "SUDO_STDIN="+sudo)
+		if out, err := run.CombinedOutput(); err != nil {
+			t.Fatalf("Expected the command to succeed, got %v: %s", err, out)
+		}
+		if got, _ := os.ReadFile(script); len(got) != 0 {
+			t.Errorf("Expected the unmarked step to read nothing, got %q", got)
+		}
+		if got, _ := os.ReadFile(sudo); string(got) != "secret\n" {
+			t.Errorf("Expected sudo to read the password, got %q", got)
+		}
+	})
+
+	t.Run("polkit tools wrap as is", func(t *testing.T) {
+		cmd, _ := Escalation{Tool: ToolRun0}.Wrap("Pacman", "pacman -S git", "")
+		if cmd != "run0 sh -c 'pacman -S git'" {
+			t.Errorf("Unexpected command %q", cmd)
+		}
+	})
+
+	t.Run("root strips inline markers", func(t *testing.T) {
+		cmd, input := Escalation{Root: true}.Wrap("Curl", recipe, "secret")
+		want := "curl -fsSL https://example.com/x.tar.gz -o /tmp/x.tar.gz && cd /tmp && tar -xzf x.tar.gz && cp x /usr/local/bin/"
+		if cmd != want || input != "" {
+			t.Errorf("Expected %s without input, got %s with %q", want, cmd, input)
+		}
+	})
+
+	t.Run("root runs always commands directly", func(t *testing.T) {
+		cmd, _ := Escalation{Tool: ToolSudo, Root: true}.Wrap("APT", "apt-get install -y git", "secret")
+		if cmd != "apt-get install -y git" {
+			t.Errorf("Unexpected command %q", cmd)
+		}
+	})
+
+	t.Run("never leaves the command alone", func(t *testing.T) {
+		cmd, input := Escalation{Tool: ToolSudo}.Wrap("Homebrew", "brew install git", "secret")
+		if cmd != "brew install git" || input != "" {
+			t.Errorf("Unexpected command %q with input %q", cmd, input)
+		}
+	})
+}
+
+// fakeTerminal replaces runTerminal for a test; the quiet -n probe succeeds when cached is
+// true, and every prompting call is recorded in prompts
+func fakeTerminal(t *testing.T, cached bool, prompts *[]string) {
+	t.Helper()
+	original := runTerminal
+	t.Cleanup(func() { runTerminal = original })
+	runTerminal = func(quiet bool, name string, args ...string) error {
+		if quiet {
+			if cached {
+				return nil
+			}
+			return errors.New("a password is required")
+		}
+		*prompts = append(*prompts, strings.Join(append([]string{name}, args...), " "))
+		return nil
+	}
+}
+
+// TestAuthenticate tests asking for the password on the terminal before a CLI command.
+// Priority: P1 - Without it the CLI fails for every user whose sudo needs a password.
+// Tests sudo and doas prompts, cached credentials, the TUI and commands that are not escalated.
+func TestAuthenticate(t *testing.T) {
+	t.Run("sudo and doas prompt", func(t *testing.T) {
+		var prompts []string
+		fakeTerminal(t, false, &prompts)
+		for _, tool := range []string{ToolSudo, ToolDoas} {
+			if err := (Escalation{Tool: tool, Interactive: true}).Authenticate("APT", "apt-get install -y git"); err != nil {
+				t.Errorf("Expected no error for %s, got %v", tool, err)
+			}
+		}
+		if strings.Join(prompts, ", ") != "sudo -v, doas true" {
+			t.Errorf("Unexpected prompts %v", prompts)
+		}
+	})
+
+	t.Run("cached credentials do not prompt", func(t *testing.T) {
+		var prompts []string
+		fakeTerminal(t, true, &prompts)
+		if err := (Escalation{Tool: ToolSudo, Interactive: true}).Authenticate("APT", "apt-get install -y git"); err != nil || len(prompts) != 0 {
+			t.Errorf("Expected no prompt, got %v (%v)", prompts, err)
+		}
+	})
+
+	t.Run("nothing to ask", func(t *testing.T) {
+		var prompts []string
+		fakeTerminal(t, false, &prompts)
+		for _, e := range []Escalation{
+			{Tool: ToolSudo},
+			{Tool: ToolSudo, Interactive: true, Root: true},
+			{Tool: ToolRun0, Interactive: true},
+		} {
+			if err := e.Authenticate("APT", "apt-get install -y git"); err != nil {
+				t.Errorf("Expected no error for %+v, got %v", e, err)
+			}
+		}
+		if err := (Escalation{Tool: ToolSudo, Interactive: true}).Authenticate("Curl", "curl -fsSL https://example.com/install.sh | sh"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if len(prompts) != 0 {
+			t.Errorf("Expected no prompts, got %v", prompts)
+		}
+	})
+}
+
+// TestPromptError tests refusing escalation tools that would prompt on the TUI's terminal.
+// Priority: P1 - They used to fail with "Authorization required" or fight the TUI for the terminal.
+// Tests polkit tools, doas with and without cached credentials, sudo and the CLI.
+func TestPromptError(t *testing.T) {
+	const install = "apt-get install -y git"
+
+	t.Run("polkit tools are refused", func(t *testing.T) {
+		for _, tool := range []string{ToolPkexec, ToolRun0} {
+			err := Escalation{Tool: tool}.PromptError("APT", install)
+			if err == nil || !strings.Contains(err.Error(), "polkit") {
+				t.Errorf("Expected %s to be refused, got %v", tool, err)
+			}
+		}
+	})
+
+	t.Run("doas needs cached credentials", func(t *testing.T) {
+		var prompts []string
+		fakeTerminal(t, false, &prompts)
+		if err := (Escalation{Tool: ToolDoas}).PromptError("APT", install); err == nil || !strings.Contains(err.Error(), "nopass") {
+			t.Errorf("Expected doas to be refused, got %v", err)
+		}
+
+		fakeTerminal(t, true, &prompts)
+		if err := (Escalation{Tool: ToolDoas}).PromptError("APT", install); err != nil {
+			t.Errorf("Expected doas with a nopass rule to run, got %v", err)
+		}
+	})
+
+	t.Run("nothing to refuse", func(t *testing.T) {
+		for _, e := range []Escalation{
+			{Tool: ToolSudo},
+			{Tool: ToolRun0, Interactive: true},
+			{Tool: ToolPkexec, Root: true},
+		} {
+			if err := e.PromptError("APT", install); err != nil {
+				t.Errorf("Expected no error for %+v, got %v", e, err)
+			}
+		}
+		if err := (Escalation{Tool: ToolRun0}).PromptError("Homebrew", "brew install git"); err != nil {
+			t.Errorf("Expected no error for a method that is not escalated, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,235 @@
+package repos
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	maxKeySize = 1 << 20 // Largest signing key accepted from a vendor
+
+	armorHeader = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+	armorFooter = "-----END PGP PUBLIC KEY BLOCK-----"
+
+	publicKeyTag = 6 // OpenPGP packet tag of a public key
+)
+
+// Key is a vendor's OpenPGP signing key, verified against its expected fingerprint
+type Key struct {
+	Packets     []byte // Primary key with its user IDs, subkeys and signatures, binary as APT keyrings store it
+	Fingerprint string // Fingerprint of the primary key, upper-case hex
+}
+
+// Armored returns the key in ASCII armor, as RPM gpgkey files store it
+func (k Key) Armored() string {
+	return armor(k.Packets)
+}
+
+// FetchKey downloads a repository's signing key and verifies its fingerprint, so a key
+// swapped on the vendor's server or in transit is never trusted
+func FetchKey(ctx context.Context, client *http.Client, repo Repo) (Key, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, repo.KeyURL, nil)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to download the %s signing key: %w", repo.Vendor, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to download the %s signing key: %w", repo.Vendor, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Key{}, fmt.Errorf("failed to download the %s signing key: %s", repo.Vendor, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySize))
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to download the %s signing key: %w", repo.Vendor, err)
+	}
+
+	key, err := ParseKey(data)
+	if err != nil {
+		return Key{}, fmt.Errorf("%s signing key: %w", repo.Vendor, err)
+	}
+	if key.Fingerprint != normalizeFingerprint(repo.Fingerprint) {
+		return Key{}, fmt.Errorf("%s signing key has fingerprint %s, expected %s", repo.Vendor, key.Fingerprint, normalizeFingerprint(repo.Fingerprint))
+	}
+	return key, nil
+}
+
+// ParseKey reads an armored or binary OpenPGP public key and computes the fingerprint
+// of its primary key; only version 4 keys are supported
+// A blob holding more than one primary key is rejected, since every key in a keyring is
+// trusted and only the first would have been checked. Packets is rebuilt from the primary
+// key, its user IDs, subkeys and signatures, dropping anything else the blob carried
+func ParseKey(data []byte) (Key, error) {
+	packets := data
+	if bytes.Contains(data, []byte(armorHeader)) {
+		var err error
+		if packets, err = dearmor(data); err != nil {
+			return Key{}, err
+		}
+	}
+
+	var key Key
+	for len(packets) > 0 {
+		tag, body, size, err := nextPacket(packets)
+		if err != nil {
+			return Key{}, err
+		}
+		raw := packets[:size]
+		packets = packets[size:]
+
+		switch {
+		case key.Packets == nil && tag != publicKeyTag:
+			return Key{}, fmt.Errorf("not a public key (packet tag %d)", tag)
+		case tag == publicKeyTag && key.Packets != nil:
+			return Key{}, errors.New("more than one primary key")
+		case tag == publicKeyTag:
+			if len(body) == 0 || body[0] != 4 {
+				return Key{}, errors.New("unsupported key version")
+			}
+			h := sha1.New()
+			h.Write([]byte{0x99, byte(len(body) >> 8), byte(len(body))})
+			h.Write(body)
+			key.Fingerprint = strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+		case !keptTags[tag]:
+			continue
+		}
+		key.Packets = append(key.Packets, raw...)
+	}
+	if key.Packets == nil {
+		return Key{}, errors.New("not an OpenPGP key")
+	}
+	return key, nil
+}
+
+// keptTags are the packets besides the primary key that are written to keyrings:
+// signatures, user IDs, subkeys and user attributes
+var keptTags = map[int]bool{2: true, 13: true, 14: true, 17: true}
+
+// nextPacket returns the tag and body of the first packet in data, in either header format,
+// and the size of the whole packet including its header
+func nextPacket(data []byte) (int, []byte, int, error) {
+	if len(data) < 2 || data[0]&0x80 == 0 {
+		return 0, nil, 0, errors.New("not an OpenPGP key")
+	}
+
+	var tag, length, offset int
+	if data[0]&0x40 != 0 {
+		// New format: one, two or five octet length
+		tag = int(data[0] & 0x3f)
+		switch first := int(data[1]); {
+		case first < 192:
+			length, offset = first, 2
+		case first < 224:
+			if len(data) < 3 {
+				return 0, nil, 0, errors.New("truncated key")
+			}
+			length, offset = (first-192)<<8+int(data[2])+192, 3
+		case first == 255:
+			if len(data) < 6 {
+				return 0, nil, 0, errors.New("truncated key")
+			}
+			length, offset = int(data[2])<<24|int(data[3])<<16|int(data[4])<<8|int(data[5]), 6
+		default:
+			return 0, nil, 0, errors.New("unsupported packet length")
+		}
+	} else {
+		// Old format: the length type is in the low two bits of the tag octet
+		tag = int(data[0]>>2) & 0x0f
+		size := map[byte]int{0: 1, 1: 2, 2: 4}[data[0]&0x03]
+		if size == 0 {
+			return 0, nil, 0, errors.New("unsupported packet length")
+		}
+		if len(data) < 1+size {
+			return 0, nil, 0, errors.New("truncated key")
+		}
+		for _, b := range data[1 : 1+size] {
+			length = length<<8 | int(b)
+		}
+		offset = 1 + size
+	}
+
+	if length < 0 || len(data) < offset+length {
+		return 0, nil, 0, errors.New("truncated key")
+	}
+	return tag, data[offset : offset+length], offset + length, nil
+}
+
+// dearmor decodes the first ASCII-armored key block in data
+// The checksum line is skipped; the fingerprint check is what proves the key
+func dearmor(data []byte) ([]byte, error) {
+	text := string(data)
+	start := strings.Index(text, armorHeader)
+	end := strings.Index(text, armorFooter)
+	if start < 0 || end < start {
+		return nil, errors.New("malformed armored key")
+	}
+
+	lines := strings.Split(text[start+len(armorHeader):end], "\n")
+	var encoded strings.Builder
+	inBody := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case !inBody:
+			// Armor headers (Comment: ..., Version: ...) end at the first blank line
+			if line == "" {
+				inBody = true
+			}
+		case strings.HasPrefix(line, "="):
+		default:
+			encoded.WriteString(line)
+		}
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return nil, fmt.Errorf("malformed armored key: %w", err)
+	}
+	return decoded, nil
+}
+
+// armor encodes binary packets as an ASCII-armored public key block with its CRC-24 checksum
+func armor(packets []byte) string {
+	var b strings.Builder
+	b.WriteString(armorHeader + "\n\n")
+	encoded := base64.StdEncoding.EncodeToString(packets)
+	for len(encoded) > 64 {
+		b.WriteString(encoded[:64] + "\n")
+		encoded = encoded[64:]
+	}
+	b.WriteString(encoded + "\n")
+
+	crc := crc24(packets)
+	b.WriteString("=" + base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}) + "\n")
+	b.WriteString(armorFooter + "\n")
+	return b.String()
+}
+
+// crc24 is the OpenPGP armor checksum (RFC 4880, section 6.1)
+func crc24(data []byte) uint32 {
+	crc := uint32(0xB704CE)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864CFB
+			}
+		}
+	}
+	return crc & 0xFFFFFF
+}
+
+// normalizeFingerprint strips the spaces fingerprints are often written with and upper-cases it
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToUpper(strings.ReplaceAll(fingerprint, " ", ""))
+}
@@ -0,0 +1,149 @@
+package repos
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testKey is a throwaway ed25519 public key generated with gpg for these tests
+const testKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatXCkhYJKwYBBAHaRw8BAQdAqK6QFjKmnGJqlh6g+0ZPmgPazqCcjGJgAbLa
+698uvAe0IWxhenlzZXR1cCB0ZXN0IDx0ZXN0QGV4YW1wbGUuY29tPoiQBBMWCAA4
+FiEEMA0/e2JXbwAOJ88PHWGfb7z2dQMFAmrVwpICGwMFCwkIBwIGFQoJCAsCBBYC
+AwECHgECF4AACgkQHWGfb7z2dQPJTAD/eAe2+0jbyQaBScPtYwLSltEdk0w+yWX0
+E7Yedlpb+hwBAJWsiMCX7/zqDxFZ7afIGWwctobRQZ1bySPG1W2GNbcK
+=rNbV
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+// testFingerprint is the fingerprint gpg reports for testKey
+const testFingerprint = "300D3F7B62576F000E27CF0F1D619F6FBCF67503"
+
+// keyServer serves body as a signing key and returns a repository pointing at it
+func keyServer(t *testing.T, body string, fingerprint string) Repo {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return Repo{Name: "test", Vendor: "Test", KeyURL: server.URL + "/gpg", Fingerprint: fingerprint}
+}
+
+// TestParseKey tests reading vendor signing keys.
+// Priority: P0 - The fingerprint is what decides whether a repository is trusted.
+// Tests armored and binary keys, the armor round trip, appended keys and input that is not a key.
+func TestParseKey(t *testing.T) {
+	t.Run("armored key", func(t *testing.T) {
+		key, err := ParseKey([]byte(testKey))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if key.Fingerprint != testFingerprint {
+			t.Errorf("Expected %s, got %s", testFingerprint, key.Fingerprint)
+		}
+	})
+
+	t.Run("binary key", func(t *testing.T) {
+		armored, _ := ParseKey([]byte(testKey))
+		key, err := ParseKey(armored.Packets)
+		if err != nil || key.Fingerprint != testFingerprint {
+			t.Errorf("Expected %s, got %s (%v)", testFingerprint, key.Fingerprint, err)
+		}
+	})
+
+	t.Run("armor round trip", func(t *testing.T) {
+		key, _ := ParseKey([]byte(testKey))
+		if got := key.Armored(); got != testKey {
+			t.Errorf("Expected the original armor with its checksum, got:\n%s", got)
+		}
+	})
+
+	t.Run("old format header", func(t *testing.T) {
+		key, _ := ParseKey([]byte(testKey))
+		// Rewrite the new format header (0xc6, one octet length) as an old format one
+		oldFormat := append([]byte{0x98}, key.Packets[1:]...)
+		parsed, err := ParseKey(oldFormat)
+		if err != nil || parsed.Fingerprint != testFingerprint {
+			t.Errorf("Expected %s, got %s (%v)", testFingerprint, parsed.Fingerprint, err)
+		}
+	})
+
+	t.Run("second primary key is rejected", func(t *testing.T) {
+		key, _ := ParseKey([]byte(testKey))
+		// A key appended after the vendor's would be trusted by APT and RPM unchecked
+		blob := append(append([]byte(nil), key.Packets...), key.Packets...)
+		if _, err := ParseKey(blob); err == nil || !strings.Contains(err.Error(), "more than one primary key") {
+			t.Errorf("Expected a second primary key to be rejected, got %v", err)
+		}
+		if _, err := ParseKey([]byte(armor(blob))); err == nil {
+			t.Error("Expected a second primary key to be rejected in armor too")
+		}
+	})
+
+	t.Run("other packets are dropped", func(t *testing.T) {
+		key, _ := ParseKey([]byte(testKey))
+		// A trust packet (tag 12) has no place in a keyring
+		blob := append(append([]byte(nil), key.Packets...), 0xcc, 0x02, 0x00, 0x00)
+		parsed, err := ParseKey(blob)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if string(parsed.Packets) != string(key.Packets) {
+			t.Errorf("Expected only the key's own packets, got %d bytes instead of %d", len(parsed.Packets), len(key.Packets))
+		}
+	})
+
+	t.Run("not a key", func(t *testing.T) {
+		if _, err := ParseKey([]byte("<html>Not Found</html>")); err == nil {
+			t.Error("Expected an error for an HTML page")
+		}
+	})
+
+	t.Run("truncated key", func(t *testing.T) {
+		key, _ := ParseKey([]byte(testKey))
+		if _, err := ParseKey(key.Packets[:20]); err == nil {
+			t.Error("Expected an error for a truncated key")
+		}
+	})
+}
+
+// TestFetchKey tests downloading and verifying a vendor's signing key.
+// Priority: P0 - A key that does not match must never be installed.
+// Tests a matching key, a fingerprint written with spaces, a mismatch and a failed download.
+func TestFetchKey(t *testing.T) {
+	t.Run("matching fingerprint", func(t *testing.T) {
+		repo := keyServer(t, testKey, testFingerprint)
+		key, err := FetchKey(context.Background(), http.DefaultClient, repo)
+		if err != nil || key.Fingerprint != testFingerprint {
+			t.Errorf("Expected a verified key, got %s (%v)", key.Fingerprint, err)
+		}
+	})
+
+	t.Run("fingerprint with spaces", func(t *testing.T) {
+		repo := keyServer(t, testKey, "300D 3F7B 6257 6F00 0E27  CF0F 1D61 9F6F BCF6 7503")
+		if _, err := FetchKey(context.Background(), http.DefaultClient, repo); err != nil {
+			t.Errorf("Expected a verified key, got %v", err)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		repo := keyServer(t, testKey, "798AEC654E5C15428C8E42EEAA16FCBCA621E701")
+		_, err := FetchKey(context.Background(), http.DefaultClient, repo)
+		if err == nil || !strings.Contains(err.Error(), testFingerprint) {
+			t.Errorf("Expected a mismatch naming the fingerprint, got %v", err)
+		}
+	})
+
+	t.Run("failed download", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		repo := Repo{Vendor: "Test", KeyURL: server.URL, Fingerprint: testFingerprint}
+		if _, err := FetchKey(context.Background(), http.DefaultClient, repo); err == nil {
+			t.Error("Expected an error for a 404")
+		}
+	})
+}
@@ -0,0 +1,263 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/commands"
+	"github.com/youpele52/lazysetup/pkg/executor"
+)
+
+// Repo is a vendor's package repository for tools the distributions do not ship
+type Repo struct {
+	Name        string            // Short name used in the files lazysetup writes
+	Vendor      string            // Name shown to the user
+	Packages    map[string]string // Package name in the repository per tool
+	KeyURL      string            // Signing key, armored or binary
+	Fingerprint string            // Expected fingerprint of the signing key's primary key
+	APTSource   string            // "URI suite components" of the deb line, empty when there is no APT repository
+	RPMBaseURL  map[string]string // baseurl per RPM method (DNF, YUM); missing when there is no such repository
+}
+
+// Vendors lists the repositories lazysetup can add
+// Fingerprints are the vendors' published ones; a downloaded key must match before it is trusted
+var Vendors = []Repo{
+	{
+		Name:        "hashicorp",
+		Vendor:      "HashiCorp",
+		Packages:    map[string]string{"terraform": "terraform"},
+		KeyURL:      "https://apt.releases.hashicorp.com/gpg",
+		Fingerprint: "798AEC654E5C15428C8E42EEAA16FCBCA621E701",
+		APTSource:   "https://apt.releases.hashicorp.com $(. /etc/os-release && echo $VERSION_CODENAME) main",
+		RPMBaseURL: map[string]string{
+			"DNF": "https://rpm.releases.hashicorp.com/fedora/$releasever/$basearch/stable",
+			"YUM": "https://rpm.releases.hashicorp.com/RHEL/$releasever/$basearch/stable",
+		},
+	},
+	{
+		Name:        "github-cli",
+		Vendor:      "GitHub CLI",
+		Packages:    map[string]string{"gh": "gh"},
+		KeyURL:      "https://cli.github.com/packages/githubcli-archive-keyring.gpg",
+		Fingerprint: "2C6106201985B60E6C7AC87323F3D4EA75716059",
+		APTSource:   "https://cli.github.com/packages stable main",
+		RPMBaseURL: map[string]string{
+			"DNF": "https://cli.github.com/packages/rpm",
+			"YUM": "https://cli.github.com/packages/rpm",
+		},
+	},
+	{
+		Name:        "docker",
+		Vendor:      "Docker",
+		Packages:    map[string]string{"docker": "docker-ce"},
+		KeyURL:      "https://download.docker.com/linux/debian/gpg",
+		Fingerprint: "9DC858229FC7DD38854AE2D88D81803C0EBFCD88",
+		APTSource:   "https://download.docker.com/linux/$(. /etc/os-release && echo $ID) $(. /etc/os-release && echo $VERSION_CODENAME) stable",
+		RPMBaseURL: map[string]string{
+			"DNF": "https://download.docker.com/linux/fedora/$releasever/$basearch/stable",
+			"YUM": "https://download.docker.com/linux/centos/$releasever/$basearch/stable",
+		},
+	},
+	{
+		Name:        "kubernetes",
+		Vendor:      "Kubernetes",
+		Packages:    map[string]string{"kubectl": "kubectl"},
+		KeyURL:      "https://pkgs.k8s.io/core:/stable:/v1.31/deb/Release.key",
+		Fingerprint: "DE15B14486CD377B9E876E1A234654DA9A296436",
+		APTSource:   "https://pkgs.k8s.io/core:/stable:/v1.31/deb/ /",
+		RPMBaseURL: map[string]string{
+			"DNF": "https://pkgs.k8s.io/core:/stable:/v1.31/rpm/",
+			"YUM": "https://pkgs.k8s.io/core:/stable:/v1.31/rpm/",
+		},
+	},
+	{
+		Name:        "helm",
+		Vendor:      "Helm",
+		Packages:    map[string]string{"helm": "helm"},
+		KeyURL:      "https://baltocdn.com/helm/signing.asc",
+		Fingerprint: "81BF832E2F19CD2AA0471959294AC4827C1A168A",
+		APTSource:   "https://baltocdn.com/helm/stable/debian/ all main",
+	},
+}
+
+// Directories the repository files are written to
+const (
+	aptKeyringDir = "/etc/apt/keyrings"
+	aptSourceDir  = "/etc/apt/sources.list.d"
+	rpmKeyDir     = "/etc/pki/rpm-gpg"
+	rpmRepoDir    = "/etc/yum.repos.d"
+
+	filePrefix = "lazysetup-" // Marks the files lazysetup wrote, so it never touches the user's own
+)
+
+// Find returns the repository a tool is installed from with a method
+// ok is false when no vendor repository serves the tool for that method
+func Find(method, tool string) (Repo, bool) {
+	for _, repo := range Vendors {
+		if _, ok := repo.Packages[tool]; ok && repo.Supports(method) {
+			return repo, true
+		}
+	}
+	return Repo{}, false
+}
+
+// Supports reports whether the repository can be added for a method
+func (r Repo) Supports(method string) bool {
+	switch method {
+	case "APT":
+		return r.APTSource != ""
+	case "DNF", "YUM":
+		return r.RPMBaseURL[method] != ""
+	}
+	return false
+}
+
+// KeyPath returns where the repository's signing key is written for a method
+func (r Repo) KeyPath(method string) string {
+	if method == "APT" {
+		return aptKeyringDir + "/" + filePrefix + r.Name + ".gpg"
+	}
+	return rpmKeyDir + "/RPM-GPG-KEY-" + filePrefix + r.Name
+}
+
+// SourcePath returns where the repository definition is written for a method
+func (r Repo) SourcePath(method string) string {
+	if method == "APT" {
+		return aptSourceDir + "/" + filePrefix + r.Name + ".list"
+	}
+	return rpmRepoDir + "/" + filePrefix + r.Name + ".repo"
+}
+
+// Files returns the files adding the repository writes for a method
+func (r Repo) Files(method string) []string {
+	return []string{r.SourcePath(method), r.KeyPath(method)}
+}
+
+// Present reports whether lazysetup has already added the repository for a method
+func (r Repo) Present(method string) bool {
+	_, err := os.Stat(r.SourcePath(method))
+	return err == nil
+}
+
+// Command rewrites a method's command for a tool to use the repository's package name,
+// as Docker's repository ships docker-ce where the distributions ship docker
+func (r Repo) Command(method, tool, cmd string) string {
+	pkg, ok := r.Packages[tool]
+	distro := commands.GetPackageName(tool, method)
+	if !ok || pkg == distro {
+		return cmd
+	}
+	// The package is the last word of the generated commands, pinned or not
+	i := strings.LastIndex(cmd, distro)
+	if i < 0 {
+		return cmd
+	}
+	return cmd[:i] + pkg + cmd[i+len(distro):]
+}
+
+// SetupCommand returns the shell command that installs the verified key at keyFile and
+// writes the repository definition; it must run as root
+// APT's package index is refreshed so the new packages can be installed, and the files are
+// removed again when the repository cannot be read, as for a release the vendor does not serve
+func (r Repo) SetupCommand(method, keyFile string) string {
+	key, source := r.KeyPath(method), r.SourcePath(method)
+	if method == "APT" {
+		deb := fmt.Sprintf("deb [signed-by=%s] %s", key, r.APTSource)
+		return fmt.Sprintf("install -d -m 0755 %s && install -m 0644 %s %s && printf '%%s\\n' \"%s\" > %s && { apt-get update || { rm -f %s %s; exit 1; }; }",
+			aptKeyringDir, commands.ShellQuote(keyFile), key, deb, source, source, key)
+	}
+	lines := []string{
+		"[" + filePrefix + r.Name + "]",
+		"name=" + r.Vendor + " (added by lazysetup)",
+		"baseurl=" + r.RPMBaseURL[method],
+		"enabled=1",
+		"gpgcheck=1",
+		"gpgkey=file://" + key,
+	}
+	quoted := make([]string, len(lines))
+	for i, line := range lines {
+		quoted[i] = commands.ShellQuote(line)
+	}
+	return fmt.Sprintf("install -D -m 0644 %s %s && printf '%%s\\n' %s > %s",
+		commands.ShellQuote(keyFile), key, strings.Join(quoted, " "), source)
+}
+
+// RemoveCommand returns the shell command that deletes the files of a recorded repository;
+// it must run as root
+func RemoveCommand(files []string) string {
+	quoted := make([]string, len(files))
+	for i, file := range files {
+		quoted[i] = commands.ShellQuote(file)
+	}
+	return "rm -f " + strings.Join(quoted, " ")
+}
+
+// Available reports whether a method's configured repositories already have a package,
+// in which case no vendor repository is needed
+func Available(ctx context.Context, method, pkg string, timeout time.Duration) bool {
+	var cmd string
+	switch method {
+	case "APT":
+		cmd = "apt-cache show " + commands.ShellQuote(pkg)
+	case "DNF":
+		cmd = "dnf info -q " + commands.ShellQuote(pkg)
+	case "YUM":
+		cmd = "yum info -q " + commands.ShellQuote(pkg)
+	default:
+		return true
+	}
+	return executor.ExecuteWithTimeout(ctx, cmd, timeout).IsSuccess()
+}
+
+// Prepared is a repository ready to be added: its verified key in a temporary file and
+// the command that installs it
+type Prepared struct {
+	Command     string   // Shell command to run as root
+	Files       []string // Files the command writes, recorded so they can be removed
+	Fingerprint string   // Fingerprint of the verified key
+	keyFile     string
+}
+
+// Cleanup removes the temporary key file
+func (p Prepared) Cleanup() {
+	if p.keyFile != "" {
+		os.Remove(p.keyFile)
+	}
+}
+
+// Prepare downloads and verifies a repository's key for a method and builds the command
+// that adds the repository; callers must call Cleanup once the command has run
+func Prepare(ctx context.Context, client *http.Client, repo Repo, method string) (Prepared, error) {
+	key, err := FetchKey(ctx, client, repo)
+	if err != nil {
+		return Prepared{}, err
+	}
+
+	f, err := os.CreateTemp("", filePrefix+"key-")
+	if err != nil {
+		return Prepared{}, fmt.Errorf("failed to store the %s signing key: %w", repo.Vendor, err)
+	}
+	data := key.Packets
+	if method != "APT" {
+		data = []byte(key.Armored())
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	prepared := Prepared{
+		Command:     repo.SetupCommand(method, f.Name()),
+		Files:       repo.Files(method),
+		Fingerprint: key.Fingerprint,
+		keyFile:     f.Name(),
+	}
+	if err != nil {
+		prepared.Cleanup()
+		return Prepared{}, fmt.Errorf("failed to store the %s signing key: %w", repo.Vendor, err)
+	}
+	return prepared, nil
+}
@@ -0,0 +1,186 @@
+package repos
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestFind tests looking up the vendor repository of a tool.
+// Priority: P1 - Tools outside the vendor table must install as before.
+// Tests APT and RPM lookups, repositories without an RPM variant and other methods.
+func TestFind(t *testing.T) {
+	t.Run("known tools", func(t *testing.T) {
+		for _, tc := range []struct{ method, tool, name string }{
+			{"APT", "terraform", "hashicorp"},
+			{"DNF", "gh", "github-cli"},
+			{"YUM", "docker", "docker"},
+			{"APT", "kubectl", "kubernetes"},
+			{"APT", "helm", "helm"},
+		} {
+			repo, ok := Find(tc.method, tc.tool)
+			if !ok || repo.Name != tc.name {
+				t.Errorf("Expected %s for %s with %s, got %q", tc.name, tc.tool, tc.method, repo.Name)
+			}
+		}
+	})
+
+	t.Run("no rpm repository", func(t *testing.T) {
+		if _, ok := Find("DNF", "helm"); ok {
+			t.Error("Expected no DNF repository for helm")
+		}
+	})
+
+	t.Run("other tools and methods", func(t *testing.T) {
+		if _, ok := Find("APT", "git"); ok {
+			t.Error("Expected no repository for git")
+		}
+		if _, ok := Find("Homebrew", "terraform"); ok {
+			t.Error("Expected no repository for Homebrew")
+		}
+	})
+
+	t.Run("every vendor has a valid fingerprint", func(t *testing.T) {
+		for _, repo := range Vendors {
+			if len(repo.Fingerprint) != 40 || strings.ToUpper(repo.Fingerprint) != repo.Fingerprint {
+				t.Errorf("Expected a 40 digit upper-case fingerprint for %s, got %q", repo.Name, repo.Fingerprint)
+			}
+		}
+	})
+}
+
+// TestCommand tests rewriting commands to the repository's package name.
+// Priority: P1 - Docker's repository ships docker-ce, not docker.
+// Tests plain and pinned commands and tools whose name is unchanged.
+func TestCommand(t *testing.T) {
+	docker, _ := Find("DNF", "docker")
+
+	t.Run("renamed package", func(t *testing.T) {
+		if got := docker.Command("DNF", "docker", "dnf install -y docker"); got != "dnf install -y docker-ce" {
+			t.Errorf("Unexpected command %q", got)
+		}
+	})
+
+	t.Run("pinned package", func(t *testing.T) {
+		if got := docker.Command("YUM", "docker", "yum install -y 'docker-27.*'"); got != "yum install -y 'docker-ce-27.*'" {
+			t.Errorf("Unexpected command %q", got)
+		}
+	})
+
+	t.Run("same name", func(t *testing.T) {
+		terraform, _ := Find("APT", "terraform")
+		if got := terraform.Command("APT", "terraform", "apt-get install -y terraform"); got != "apt-get install -y terraform" {
+			t.Errorf("Unexpected command %q", got)
+		}
+	})
+}
+
+// TestSetupCommand tests the commands that add and remove a repository.
+// Priority: P0 - They run as root and write into /etc.
+// Tests the APT sources entry, the RPM .repo file and the removal command.
+func TestSetupCommand(t *testing.T) {
+	t.Run("apt", func(t *testing.T) {
+		repo, _ := Find("APT", "gh")
+		cmd := repo.SetupCommand("APT", "/tmp/key")
+		for _, want := range []string{
+			"install -m 0644 '/tmp/key' /etc/apt/keyrings/lazysetup-github-cli.gpg",
+			`"deb [signed-by=/etc/apt/keyrings/lazysetup-github-cli.gpg] https://cli.github.com/packages stable main" > /etc/apt/sources.list.d/lazysetup-github-cli.list`,
+			"apt-get update || { rm -f /etc/apt/sources.list.d/lazysetup-github-cli.list /etc/apt/keyrings/lazysetup-github-cli.gpg; exit 1; }",
+		} {
+			if !strings.Contains(cmd, want) {
+				t.Errorf("Expected %q in %s", want, cmd)
+			}
+		}
+	})
+
+	t.Run("rpm", func(t *testing.T) {
+		repo, _ := Find("DNF", "terraform")
+		cmd := repo.SetupCommand("DNF", "/tmp/key")
+		for _, want := range []string{
+			"install -D -m 0644 '/tmp/key' /etc/pki/rpm-gpg/RPM-GPG-KEY-lazysetup-hashicorp",
+			"'[lazysetup-hashicorp]'",
+			"'baseurl=https://rpm.releases.hashicorp.com/fedora/$releasever/$basearch/stable'",
+			"'gpgcheck=1'",
+			"'gpgkey=file:///etc/pki/rpm-gpg/RPM-GPG-KEY-lazysetup-hashicorp'",
+			"> /etc/yum.repos.d/lazysetup-hashicorp.repo",
+		} {
+			if !strings.Contains(cmd, want) {
+				t.Errorf("Expected %q in %s", want, cmd)
+			}
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		repo, _ := Find("APT", "helm")
+		want := "rm -f '/etc/apt/sources.list.d/lazysetup-helm.list' '/etc/apt/keyrings/lazysetup-helm.gpg'"
+		if got := RemoveCommand(repo.Files("APT")); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	})
+}
+
+// TestPrepare tests storing a verified key for the setup command.
+// Priority: P0 - Only a key that matches its fingerprint may reach the setup command.
+// Tests binary keys for APT, armored keys for RPM, appended keys and a mismatch.
+func TestPrepare(t *testing.T) {
+	t.Run("apt gets the binary key", func(t *testing.T) {
+		repo := keyServer(t, testKey, testFingerprint)
+		repo.APTSource = "https://example.com/apt stable main"
+		prepared, err := Prepare(context.Background(), http.DefaultClient, repo, "APT")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		data, err := os.ReadFile(prepared.keyFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, _ := ParseKey([]byte(testKey))
+		if string(data) != string(key.Packets) {
+			t.Error("Expected the binary key in the temporary file")
+		}
+		if !strings.Contains(prepared.Command, prepared.keyFile) || prepared.Fingerprint != testFingerprint {
+			t.Errorf("Unexpected prepared repository %+v", prepared)
+		}
+
+		prepared.Cleanup()
+		if _, err := os.Stat(prepared.keyFile); !os.IsNotExist(err) {
+			t.Error("Expected Cleanup to remove the temporary key")
+		}
+	})
+
+	t.Run("rpm gets the armored key", func(t *testing.T) {
+		repo := keyServer(t, testKey, testFingerprint)
+		repo.RPMBaseURL = map[string]string{"DNF": "https://example.com/rpm"}
+		prepared, err := Prepare(context.Background(), http.DefaultClient, repo, "DNF")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer prepared.Cleanup()
+		data, _ := os.ReadFile(prepared.keyFile)
+		if string(data) != testKey {
+			t.Errorf("Expected the armored key, got:\n%s", data)
+		}
+		if len(prepared.Files) != 2 || prepared.Files[0] != "/etc/yum.repos.d/lazysetup-test.repo" {
+			t.Errorf("Unexpected files %v", prepared.Files)
+		}
+	})
+
+	t.Run("appended key writes nothing", func(t *testing.T) {
+		key, _ := ParseKey([]byte(testKey))
+		repo := keyServer(t, armor(append(append([]byte(nil), key.Packets...), key.Packets...)), testFingerprint)
+		prepared, err := Prepare(context.Background(), http.DefaultClient, repo, "APT")
+		if err == nil || prepared.keyFile != "" {
+			t.Errorf("Expected the blob to be rejected without a key file, got %+v (%v)", prepared, err)
+		}
+	})
+
+	t.Run("mismatch writes nothing", func(t *testing.T) {
+		repo := keyServer(t, testKey, "9DC858229FC7DD38854AE2D88D81803C0EBFCD88")
+		prepared, err := Prepare(context.Background(), http.DefaultClient, repo, "APT")
+		if err == nil || prepared.keyFile != "" {
+			t.Errorf("Expected a mismatch without a key file, got %+v (%v)", prepared, err)
+		}
+	})
+}
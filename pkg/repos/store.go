@@ -0,0 +1,219 @@
+package repos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileVersion is the format version written to the repositories file
+const FileVersion = 1
+
+// Record is a vendor repository lazysetup added, kept until no tool installed from it is left
+type Record struct {
+	Name    string    `json:"name"`
+	Vendor  string    `json:"vendor"`
+	Method  string    `json:"method"`
+	Files   []string  `json:"files"`           // Files written when adding it, removed with it
+	Tools   []string  `json:"tools,omitempty"` // Tools installed from it
+	AddedAt time.Time `json:"added_at"`
+}
+
+type file struct {
+	Version int      `json:"version"`
+	Records []Record `json:"records"`
+}
+
+// Store keeps the vendor repositories lazysetup added
+// Safe for concurrent use; a nil Store records nothing, which disables adding repositories
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	records []Record
+}
+
+// Path returns the repositories file, $XDG_STATE_HOME/lazysetup/repos.json
+// (~/.local/state when XDG_STATE_HOME is unset)
+func Path() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate state directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "lazysetup", "repos.json"), nil
+}
+
+// NewStore returns an empty store that saves to path; an empty path keeps it in memory only
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load reads the repositories file at path
+// A missing file is not an error and yields an empty store
+func Load(path string) (*Store, error) {
+	store := NewStore(path)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return store, fmt.Errorf("failed to read repository records: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return store, fmt.Errorf("failed to parse repository records %s: %w", path, err)
+	}
+	if f.Version > FileVersion {
+		return store, fmt.Errorf("unsupported repository records version %d", f.Version)
+	}
+	store.records = f.Records
+	return store, nil
+}
+
+// Save writes the store to its path, replacing the file atomically
+func (s *Store) Save() error {
+	if s == nil || s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	data, err := json.MarshalIndent(file{Version: FileVersion, Records: s.records}, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode repository records: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write repository records: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write repository records: %w", err)
+	}
+	return nil
+}
+
+// Add records a repository added for a method, replacing an earlier record of it
+func (s *Store) Add(record Record) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, r := range s.records {
+		if r.Name == record.Name && r.Method == record.Method {
+			s.records[i] = record
+			return
+		}
+	}
+	s.records = append(s.records, record)
+}
+
+// Recorded reports whether lazysetup added a repository for a method
+func (s *Store) Recorded(name, method string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.records {
+		if r.Name == name && r.Method == method {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTool records that a tool was installed from a repository lazysetup added
+// Returns false when the repository is not recorded, as when the user added it themselves
+func (s *Store) AddTool(name, method, tool string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.records {
+		r := &s.records[i]
+		if r.Name != name || r.Method != method {
+			continue
+		}
+		for _, known := range r.Tools {
+			if known == tool {
+				return true
+			}
+		}
+		r.Tools = append(r.Tools, tool)
+		return true
+	}
+	return false
+}
+
+// InstalledFrom returns the recorded repository a tool was installed from with a method
+func (s *Store) InstalledFrom(method, tool string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.records {
+		if r.Method != method {
+			continue
+		}
+		for _, known := range r.Tools {
+			if known == tool {
+				return r.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// RemoveTool forgets that a tool was installed from a repository for a method
+// When it was the repository's last tool, the record is dropped and returned with
+// last set, so its files can be removed
+func (s *Store) RemoveTool(method, tool string) (record Record, last bool) {
+	if s == nil {
+		return Record{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.records {
+		r := &s.records[i]
+		if r.Method != method {
+			continue
+		}
+		for j, known := range r.Tools {
+			if known != tool {
+				continue
+			}
+			r.Tools = append(r.Tools[:j:j], r.Tools[j+1:]...)
+			if len(r.Tools) > 0 {
+				return *r, false
+			}
+			removed := *r
+			s.records = append(s.records[:i:i], s.records[i+1:]...)
+			return removed, true
+		}
+	}
+	return Record{}, false
+}
+
+// Records returns a copy of the recorded repositories
+func (s *Store) Records() []Record {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Record(nil), s.records...)
+}
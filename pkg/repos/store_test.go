@@ -0,0 +1,96 @@
+package repos
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestStore tests the records of added repositories.
+// Priority: P1 - Uninstall must remove a repository only with its last tool.
+// Tests tool bookkeeping, persistence and the nil store.
+func TestStore(t *testing.T) {
+	files := []string{"/etc/apt/sources.list.d/lazysetup-hashicorp.list", "/etc/apt/keyrings/lazysetup-hashicorp.gpg"}
+	added := Record{Name: "hashicorp", Vendor: "HashiCorp", Method: "APT", Files: files, AddedAt: time.Unix(1700000000, 0).UTC()}
+
+	t.Run("tools keep the repository", func(t *testing.T) {
+		s := NewStore("")
+		s.Add(added)
+		if !s.AddTool("hashicorp", "APT", "terraform") || !s.AddTool("hashicorp", "APT", "vault") {
+			t.Fatal("Expected tools to be recorded")
+		}
+		if name, ok := s.InstalledFrom("APT", "vault"); !ok || name != "hashicorp" {
+			t.Errorf("Expected vault from hashicorp, got %q", name)
+		}
+
+		if record, last := s.RemoveTool("APT", "terraform"); last || record.Name != "hashicorp" {
+			t.Errorf("Expected the repository to stay for vault, got %+v last=%v", record, last)
+		}
+		record, last := s.RemoveTool("APT", "vault")
+		if !last || len(record.Files) != 2 {
+			t.Errorf("Expected the last tool to release the repository, got %+v last=%v", record, last)
+		}
+		if s.Recorded("hashicorp", "APT") {
+			t.Error("Expected the record to be dropped")
+		}
+	})
+
+	t.Run("unrecorded repositories", func(t *testing.T) {
+		s := NewStore("")
+		if s.AddTool("hashicorp", "APT", "terraform") {
+			t.Error("Expected no record for a repository lazysetup did not add")
+		}
+		if record, last := s.RemoveTool("APT", "terraform"); last || record.Name != "" {
+			t.Errorf("Expected nothing to release, got %+v", record)
+		}
+	})
+
+	t.Run("methods are separate", func(t *testing.T) {
+		s := NewStore("")
+		s.Add(added)
+		s.AddTool("hashicorp", "APT", "terraform")
+		if _, ok := s.InstalledFrom("DNF", "terraform"); ok {
+			t.Error("Expected no DNF record")
+		}
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "lazysetup", "repos.json")
+		s := NewStore(path)
+		s.Add(added)
+		s.AddTool("hashicorp", "APT", "terraform")
+		if err := s.Save(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		records := loaded.Records()
+		if len(records) != 1 || records[0].Vendor != "HashiCorp" || len(records[0].Tools) != 1 || !records[0].AddedAt.Equal(added.AddedAt) {
+			t.Errorf("Unexpected records %+v", records)
+		}
+	})
+
+	t.Run("missing and unsupported files", func(t *testing.T) {
+		dir := t.TempDir()
+		if s, err := Load(filepath.Join(dir, "missing.json")); err != nil || len(s.Records()) != 0 {
+			t.Errorf("Expected an empty store, got %v", err)
+		}
+		path := filepath.Join(dir, "future.json")
+		os.WriteFile(path, []byte(`{"version": 99, "records": []}`), 0644)
+		if _, err := Load(path); err == nil {
+			t.Error("Expected an error for a newer file version")
+		}
+	})
+
+	t.Run("nil store", func(t *testing.T) {
+		var s *Store
+		s.Add(added)
+		if s.AddTool("hashicorp", "APT", "terraform") || s.Recorded("hashicorp", "APT") || s.Save() != nil {
+			t.Error("Expected a nil store to record nothing")
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/diagnostics"
+)
+
+// Policy decides whether and when a failed command is run again
+type Policy struct {
+	MaxAttempts int                    // Total attempts including the first; 1 disables retries
+	BaseDelay   time.Duration          // Wait before the first retry, doubled for each further retry
+	MaxDelay    time.Duration          // Upper bound for a single wait
+	Jitter      float64                // Fraction of the wait randomized either way (0.2 = ±20%)
+	Retryable   []diagnostics.Category // Failure categories worth retrying
+}
+
+// DefaultPolicy retries transient failures (network, lock held, unclassified) twice
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+		Retryable:   diagnostics.RetryableCategories(),
+	}
+}
+
+// ShouldRetry reports whether failures of this category are retried
+func (p Policy) ShouldRetry(category diagnostics.Category) bool {
+	for _, retryable := range p.Retryable {
+		if retryable == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the wait before the given retry (1 for the first retry)
+// The wait grows exponentially from BaseDelay, is capped at MaxDelay and then jittered
+func (p Policy) Delay(retry int) time.Duration {
+	if retry < 1 || p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		spread := float64(delay) * p.Jitter
+		delay += time.Duration((rand.Float64()*2 - 1) * spread)
+	}
+	return delay
+}
+
+// Do runs attempt until it succeeds, fails with a category the policy does not retry,
+// runs out of attempts or ctx is done. Waits between attempts end early on cancellation
+// Returns the number of attempts made
+func (p Policy) Do(ctx context.Context, attempt func() (bool, diagnostics.Category)) int {
+	attempts := 0
+	for {
+		attempts++
+		ok, category := attempt()
+		if ok || attempts >= p.MaxAttempts || !p.ShouldRetry(category) {
+			return attempts
+		}
+		if err := Sleep(ctx, p.Delay(attempts)); err != nil {
+			return attempts
+		}
+	}
+}
+
+// Sleep waits for d or until ctx is done, returning ctx.Err() in the latter case
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// FromConfig applies the config file's retry settings on top of DefaultPolicy
+// Category names are assumed valid; config.UserConfig.Validate rejects unknown ones
+func FromConfig(cfg config.RetryConfig) Policy {
+	policy := DefaultPolicy()
+	if cfg.MaxAttempts > 0 {
+		policy.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.BaseDelay > 0 {
+		policy.BaseDelay = cfg.BaseDelay
+	}
+	if cfg.MaxDelay > 0 {
+		policy.MaxDelay = cfg.MaxDelay
+	}
+	if len(cfg.Categories) > 0 {
+		policy.Retryable = nil
+		for _, name := range cfg.Categories {
+			if category, ok := diagnostics.ParseCategory(name); ok {
+				policy.Retryable = append(policy.Retryable, category)
+			}
+		}
+	}
+	return policy
+}
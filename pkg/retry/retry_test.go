@@ -0,0 +1,137 @@
+package retry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/diagnostics"
+)
+
+// TestPolicy_Delay tests the backoff between attempts.
+// Priority: P1 - Waits that do not grow hammer busy mirrors, uncapped waits stall a batch.
+// Tests exponential growth, the MaxDelay cap and that jitter stays within bounds.
+func TestPolicy_Delay(t *testing.T) {
+	t.Run("doubles for each retry", func(t *testing.T) {
+		p := Policy{BaseDelay: time.Second, MaxDelay: time.Minute}
+		for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second} {
+			if got := p.Delay(retry); got != want {
+				t.Errorf("Expected retry %d to wait %v, got %v", retry, want, got)
+			}
+		}
+	})
+
+	t.Run("is capped at MaxDelay", func(t *testing.T) {
+		p := Policy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+		if got := p.Delay(10); got != 5*time.Second {
+			t.Errorf("Expected delay capped at 5s, got %v", got)
+		}
+	})
+
+	t.Run("jitter stays within bounds", func(t *testing.T) {
+		p := Policy{BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: 0.2}
+		for i := 0; i < 100; i++ {
+			got := p.Delay(1)
+			if got < 800*time.Millisecond || got > 1200*time.Millisecond {
+				t.Fatalf("Expected jittered delay within ±20%% of 1s, got %v", got)
+			}
+		}
+	})
+
+	t.Run("no wait before the first attempt", func(t *testing.T) {
+		if got := DefaultPolicy().Delay(0); got != 0 {
+			t.Errorf("Expected no delay for retry 0, got %v", got)
+		}
+	})
+}
+
+// TestPolicy_Do tests when attempts stop.
+// Priority: P0 - Retrying permanent failures or ignoring cancellation wastes minutes per tool.
+// Tests success, non-retryable categories, the attempt limit and cancellation during a wait.
+func TestPolicy_Do(t *testing.T) {
+	policy := Policy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		Retryable:   []diagnostics.Category{diagnostics.CategoryNetwork},
+	}
+
+	t.Run("stops on success", func(t *testing.T) {
+		calls := 0
+		attempts := policy.Do(context.Background(), func() (bool, diagnostics.Category) {
+			calls++
+			return calls == 2, diagnostics.CategoryNetwork
+		})
+		if attempts != 2 {
+			t.Errorf("Expected 2 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("does not retry permanent failures", func(t *testing.T) {
+		attempts := policy.Do(context.Background(), func() (bool, diagnostics.Category) {
+			return false, diagnostics.CategoryNotFound
+		})
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts)
+		}
+	})
+
+	t.Run("gives up after MaxAttempts", func(t *testing.T) {
+		attempts := policy.Do(context.Background(), func() (bool, diagnostics.Category) {
+			return false, diagnostics.CategoryNetwork
+		})
+		if attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("cancellation ends the wait", func(t *testing.T) {
+		slow := policy
+		slow.BaseDelay = time.Hour
+		slow.MaxDelay = time.Hour
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		start := time.Now()
+		attempts := slow.Do(ctx, func() (bool, diagnostics.Category) {
+			return false, diagnostics.CategoryNetwork
+		})
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Expected cancellation to end the wait, took %v", elapsed)
+		}
+	})
+}
+
+// TestFromConfig tests building a policy from the config file.
+// Priority: P2 - Config overrides must apply without dropping defaults.
+// Tests that unset fields keep defaults and set fields override them.
+func TestFromConfig(t *testing.T) {
+	t.Run("empty config keeps defaults", func(t *testing.T) {
+		got := FromConfig(config.RetryConfig{})
+		want := DefaultPolicy()
+		if got.MaxAttempts != want.MaxAttempts || got.BaseDelay != want.BaseDelay || got.MaxDelay != want.MaxDelay {
+			t.Errorf("Expected default policy, got %+v", got)
+		}
+		if len(got.Retryable) != len(want.Retryable) {
+			t.Errorf("Expected default categories, got %v", got.Retryable)
+		}
+	})
+
+	t.Run("overrides apply", func(t *testing.T) {
+		got := FromConfig(config.RetryConfig{
+			MaxAttempts: 5,
+			BaseDelay:   2 * time.Second,
+			Categories:  []string{"network failure"},
+		})
+		if got.MaxAttempts != 5 || got.BaseDelay != 2*time.Second {
+			t.Errorf("Expected overrides applied, got %+v", got)
+		}
+		if !got.ShouldRetry(diagnostics.CategoryNetwork) || got.ShouldRetry(diagnostics.CategoryLockHeld) {
+			t.Errorf("Expected only network to be retryable, got %v", got.Retryable)
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/cache"
+	"github.com/youpele52/lazysetup/pkg/commands"
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/executor"
+	"github.com/youpele52/lazysetup/pkg/privilege"
+)
+
+// RestoreStatus describes what restoring a single tool did
+type RestoreStatus string
+
+const (
+	RestorePresent   RestoreStatus = "present"   // Already installed with the recorded version
+	RestoreInstalled RestoreStatus = "installed" // Missing and installed with the recorded version
+	RestoreDrift     RestoreStatus = "drift"     // Installed, but the version differs from the lockfile
+	RestoreFailed    RestoreStatus = "failed"    // Missing and could not be installed
+)
+
+// RestoreResult is the outcome of restoring one tool from a lockfile
+type RestoreResult struct {
+	Tool      string
+	Status    RestoreStatus
+	Wanted    string // Version recorded in the lockfile
+	Got       string // Version found after restoring
+	Installed bool   // Whether this run installed the tool
+	Error     string
+}
+
+// RestoreOptions controls how a lockfile is restored
+// Exact pins every tool without an explicit pin to its recorded version
+// Check and Install default to the real check and install commands when nil; they honor
+// Timeouts; the default install fetches Curl downloads through Downloads, which may be nil, and
+// escalates as Escalation says
+// Preflight runs once, before the first missing tool is installed; with the default install
+// it defaults to the method's pre-flight command from Flights, so a fresh container's package
+// index is refreshed. A failure is passed to PreflightFailed and the restore goes on
+type RestoreOptions struct {
+	Method     string
+	Exact      bool
+	Timeouts   config.Timeouts
+	Downloads  *cache.Cache
+	Escalation privilege.Escalation
+	Flights    config.Flights
+	Check      func(ctx context.Context, tool string) ToolState
+	Install    func(ctx context.Context, method, tool, pin string) error
+
+	Preflight       func(ctx context.Context, method string) error
+	PreflightFailed func(method string, err error)
+}
+
+// InstallTool runs the install command for a tool with the given method and optional version pin,
+// giving up after timeout
+// Curl recipes run in their own work directory, with their download fetched through downloads
+// The command is escalated without a password; an Interactive escalation first lets sudo or
+// doas ask for it on the terminal
+func InstallTool(ctx context.Context, method, tool, pin string, timeout time.Duration, downloads *cache.Cache, escalation privilege.Escalation) error {
+	cmd, err := commands.GetPinnedInstallCommand(method, tool, pin)
+	if err != nil {
+		return err
+	}
+	if cmd == "" {
+		return fmt.Errorf("%s for %s via %s", constants.NoInstallCommandError, tool, method)
+	}
+	if method == "Curl" {
+		prepared, err := downloads.Prepare(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		defer prepared.Cleanup()
+		cmd = prepared.Command
+	}
+
+	return runEscalated(ctx, method, cmd, timeout, escalation)
+}
+
+// runPreflight runs a method's pre-flight command for installs, if it has one
+func runPreflight(ctx context.Context, method string, flights config.Flights, timeout time.Duration, escalation privilege.Escalation) error {
+	cmd := flights.PreflightCommand(method, constants.ToolActionInstall)
+	if cmd == "" {
+		return nil
+	}
+	return runEscalated(ctx, method, cmd, timeout, escalation)
+}
+
+// runEscalated runs a method's command escalated without a password, failing with the
+// last line of its output
+func runEscalated(ctx context.Context, method, cmd string, timeout time.Duration, escalation privilege.Escalation) error {
+	if err := escalation.Authenticate(method, cmd); err != nil {
+		return err
+	}
+	cmd, _ = escalation.Wrap(method, cmd, "")
+	result := executor.ExecuteWithTimeout(ctx, cmd, timeout)
+	if !result.IsSuccess() {
+		if output := strings.TrimSpace(result.Output); output != "" {
+			return fmt.Errorf("%s", lastLine(output))
+		}
+		return fmt.Errorf("%s", result.GetErrorMessage())
+	}
+	return nil
+}
+
+// Restore installs every tool the lockfile records as installed but which is missing here,
+// and reports version drift for tools whose local version differs from the recorded one
+func Restore(ctx context.Context, lock *Lockfile, opts RestoreOptions) []RestoreResult {
+	if opts.Check == nil {
+		opts.Check = func(ctx context.Context, tool string) ToolState {
+			return CheckTool(ctx, tool, opts.Timeouts.Check())
+		}
+	}
+	if opts.Install == nil {
+		opts.Install = func(ctx context.Context, method, tool, pin string) error {
+			return InstallTool(ctx, method, tool, pin, opts.Timeouts.Action(method, tool), opts.Downloads, opts.Escalation)
+		}
+		if opts.Preflight == nil {
+			opts.Preflight = func(ctx context.Context, method string) error {
+				return runPreflight(ctx, method, opts.Flights, opts.Timeouts.Action(method, ""), opts.Escalation)
+			}
+		}
+	}
+	preflightDone := false
+
+	var results []RestoreResult
+	for _, wanted := range lock.InstalledTools() {
+		pin := wanted.Pin
+		if pin == "" && opts.Exact {
+			pin = wanted.Version
+		}
+		result := RestoreResult{Tool: wanted.Name, Wanted: wanted.Version}
+		if pin != "" {
+			result.Wanted = pin
+		}
+
+		local := opts.Check(ctx, wanted.Name)
+		if !local.Installed {
+			if !preflightDone && opts.Preflight != nil {
+				preflightDone = true
+				if err := opts.Preflight(ctx, opts.Method); err != nil && opts.PreflightFailed != nil {
+					opts.PreflightFailed(opts.Method, err)
+				}
+			}
+			if err := opts.Install(ctx, opts.Method, wanted.Name, pin); err != nil {
+				result.Status = RestoreFailed
+				result.Error = err.Error()
+				results = append(results, result)
+				continue
+			}
+			result.Installed = true
+			local = opts.Check(ctx, wanted.Name)
+		}
+
+		result.Got = local.Version
+		switch {
+		case hasDrifted(wanted, pin, local.Version):
+			result.Status = RestoreDrift
+		case result.Installed:
+			result.Status = RestoreInstalled
+		default:
+			result.Status = RestorePresent
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// hasDrifted reports whether the local version differs from what the lockfile asks for
+// A valid pin is matched as a constraint, otherwise the recorded version must match exactly
+func hasDrifted(wanted ToolState, pin, local string) bool {
+	if pin != "" {
+		if constraint, err := commands.ParseVersionConstraint(pin); err == nil {
+			return !constraint.Matches(local)
+		}
+	}
+	return wanted.Version != "" && local != wanted.Version
+}
+
+// lastLine returns the last non-empty line of command output
+func lastLine(output string) string {
+	lines := strings.Split(output, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
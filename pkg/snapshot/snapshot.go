@@ -0,0 +1,169 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/commands"
+	"github.com/youpele52/lazysetup/pkg/config"
+	"github.com/youpele52/lazysetup/pkg/executor"
+	"github.com/youpele52/lazysetup/pkg/tools"
+)
+
+// LockfileVersion is the schema version written to new lockfiles
+const LockfileVersion = 1
+
+// DefaultLockfile is the path used by `lazysetup export` when none is given
+const DefaultLockfile = "lazysetup.lock"
+
+// Lockfile records a machine's tool environment so it can be reproduced elsewhere
+type Lockfile struct {
+	Version        int         `json:"version"`
+	CreatedAt      string      `json:"created_at"`
+	OS             string      `json:"os"`
+	Arch           string      `json:"arch"`
+	PackageManager string      `json:"package_manager"`
+	Tools          []ToolState `json:"tools"`
+}
+
+// ToolState is the installed state and version of one tool
+// Pin is an optional, hand-edited version constraint (e.g. "1.9.x") that restore installs
+type ToolState struct {
+	Name      string `json:"name"`
+	Installed bool   `json:"installed"`
+	Version   string `json:"version,omitempty"`
+	Pin       string `json:"pin,omitempty"`
+}
+
+// versionPattern matches the first dotted version number in check command output
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// ParseVersion extracts a version number from check command output
+// Falls back to the first non-empty line when no dotted version is present
+func ParseVersion(output string) string {
+	if match := versionPattern.FindString(output); match != "" {
+		return match
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// CheckTool runs the tool's check command, giving up after timeout, and reports whether
+// it is installed and its version
+func CheckTool(ctx context.Context, tool string, timeout time.Duration) ToolState {
+	state := ToolState{Name: tool}
+	cmd := commands.GetToolCheckCommand(tool)
+	if cmd == "" {
+		return state
+	}
+
+	result := executor.ExecuteWithTimeout(ctx, cmd, timeout)
+	if !result.IsSuccess() {
+		return state
+	}
+	state.Installed = true
+	state.Version = ParseVersion(result.Output)
+	return state
+}
+
+// IsMethodAvailable reports whether a package manager's check command succeeds
+func IsMethodAvailable(ctx context.Context, method string) bool {
+	cmd := commands.GetCheckCommand(method)
+	if cmd == "" {
+		return false
+	}
+	return executor.ExecuteWithTimeout(ctx, cmd, config.DefaultManagerCheckTimeout).IsSuccess()
+}
+
+// DetectPackageManager returns the first available package manager in config.InstallMethods order
+// Curl is only chosen when no real package manager is available
+func DetectPackageManager(ctx context.Context) string {
+	for _, method := range config.InstallMethods {
+		if method == "Curl" {
+			continue
+		}
+		if IsMethodAvailable(ctx, method) {
+			return method
+		}
+	}
+	if IsMethodAvailable(ctx, "Curl") {
+		return "Curl"
+	}
+	return ""
+}
+
+// Capture records the detected package manager and the state of every known tool
+// Tools are checked concurrently, each within checkTimeout, and listed in tools.Tools order
+func Capture(ctx context.Context, checkTimeout time.Duration) *Lockfile {
+	lock := &Lockfile{
+		Version:        LockfileVersion,
+		CreatedAt:      time.Now().UTC().Format(time.RFC3339),
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		PackageManager: DetectPackageManager(ctx),
+		Tools:          make([]ToolState, len(tools.Tools)),
+	}
+
+	var wg sync.WaitGroup
+	for i, tool := range tools.Tools {
+		wg.Add(1)
+		go func(i int, tool string) {
+			defer wg.Done()
+			lock.Tools[i] = CheckTool(ctx, tool, checkTimeout)
+		}(i, tool)
+	}
+	wg.Wait()
+
+	return lock
+}
+
+// Save writes the lockfile as indented JSON
+func Save(path string, lock *Lockfile) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
+
+// Load reads and validates a lockfile
+func Load(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	var lock Lockfile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+	if lock.Version == 0 || lock.Version > LockfileVersion {
+		return nil, fmt.Errorf("unsupported lockfile version %d", lock.Version)
+	}
+	return &lock, nil
+}
+
+// InstalledTools returns the tools the lockfile records as installed
+func (l *Lockfile) InstalledTools() []ToolState {
+	var installed []ToolState
+	for _, tool := range l.Tools {
+		if tool.Installed {
+			installed = append(installed, tool)
+		}
+	}
+	return installed
+}
@@ -0,0 +1,244 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseVersion_ExtractsDottedVersion tests version extraction from check output.
+// Priority: P1 - Drift detection compares the versions parsed here.
+// Tests common check command output formats and the first-line fallback.
+func TestParseVersion_ExtractsDottedVersion(t *testing.T) {
+	cases := map[string]string{
+		"git version 2.39.5": "2.39.5",
+		"tmux 3.3a":          "3.3",
+		"v20.19.5\n":         "20.19.5",
+		"jq-1.6":             "1.6",
+		"ripgrep 14.1.0\n-SIMD -AVX (compiled)\n":    "14.1.0",
+		"\nsomething without numbers\nsecond line\n": "something without numbers",
+		"": "",
+	}
+
+	for output, want := range cases {
+		if got := ParseVersion(output); got != want {
+			t.Errorf("ParseVersion(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
+
+// TestSaveLoad_RoundTrip tests lockfile persistence.
+// Priority: P1 - Restore depends on reading back exactly what export wrote.
+// Tests that a saved lockfile loads with the same content and that bad versions are rejected.
+func TestSaveLoad_RoundTrip(t *testing.T) {
+	t.Run("round trips tools and package manager", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "lazysetup.lock")
+		lock := &Lockfile{
+			Version:        LockfileVersion,
+			PackageManager: "APT",
+			Tools: []ToolState{
+				{Name: "git", Installed: true, Version: "2.39.5"},
+				{Name: "k9s"},
+			},
+		}
+
+		if err := Save(path, lock); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+
+		if loaded.PackageManager != "APT" || len(loaded.Tools) != 2 {
+			t.Errorf("Unexpected lockfile: %+v", loaded)
+		}
+		if installed := loaded.InstalledTools(); len(installed) != 1 || installed[0].Version != "2.39.5" {
+			t.Errorf("Expected only git 2.39.5 installed, got %+v", installed)
+		}
+	})
+
+	t.Run("rejects unsupported version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "lazysetup.lock")
+		if err := Save(path, &Lockfile{Version: LockfileVersion + 1}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Error("Expected error for unsupported lockfile version")
+		}
+	})
+}
+
+// TestRestore_InstallsMissingAndReportsDrift tests restore planning.
+// Priority: P1 - Restore must install only what is missing and surface version drift.
+// Tests present, installed, drift and failed outcomes with fake check/install functions.
+func TestRestore_InstallsMissingAndReportsDrift(t *testing.T) {
+	lock := &Lockfile{
+		Version: LockfileVersion,
+		Tools: []ToolState{
+			{Name: "git", Installed: true, Version: "2.39.5"},
+			{Name: "jq", Installed: true, Version: "1.7"},
+			{Name: "tmux", Installed: true, Version: "3.3"},
+			{Name: "k9s", Installed: true, Version: "0.32.0"},
+			{Name: "bat", Installed: false},
+		},
+	}
+
+	local := map[string]ToolState{
+		"git": {Name: "git", Installed: true, Version: "2.39.5"},
+		"jq":  {Name: "jq", Installed: true, Version: "1.6"},
+	}
+	var installed []string
+
+	results := Restore(context.Background(), lock, RestoreOptions{
+		Method: "APT",
+		Check: func(ctx context.Context, tool string) ToolState {
+			return local[tool]
+		},
+		Install: func(ctx context.Context, method, tool, pin string) error {
+			installed = append(installed, tool)
+			if tool == "k9s" {
+				return errors.New("unable to locate package k9s")
+			}
+			local[tool] = ToolState{Name: tool, Installed: true, Version: "3.3"}
+			return nil
+		},
+	})
+
+	want := map[string]RestoreStatus{
+		"git":  RestorePresent,
+		"jq":   RestoreDrift,
+		"tmux": RestoreInstalled,
+		"k9s":  RestoreFailed,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(results))
+	}
+	for _, result := range results {
+		if result.Status != want[result.Tool] {
+			t.Errorf("%s: expected %s, got %s", result.Tool, want[result.Tool], result.Status)
+		}
+	}
+	if len(installed) != 2 {
+		t.Errorf("Expected only missing tools to be installed, got %v", installed)
+	}
+}
+
+// TestRestore_HonorsPins tests version pins during restore.
+// Priority: P1 - Pinned team environments must install and compare against the pin.
+// Tests that explicit pins and Exact mode are passed to Install and used for drift detection.
+func TestRestore_HonorsPins(t *testing.T) {
+	lock := &Lockfile{
+		Version: LockfileVersion,
+		Tools: []ToolState{
+			{Name: "terraform", Installed: true, Version: "1.9.2", Pin: "1.9.x"},
+			{Name: "node", Installed: true, Version: "20.11.0"},
+		},
+	}
+
+	t.Run("explicit pin accepts any matching version", func(t *testing.T) {
+		pins := map[string]string{}
+		results := Restore(context.Background(), lock, RestoreOptions{
+			Method: "Curl",
+			Check: func(ctx context.Context, tool string) ToolState {
+				if _, ok := pins[tool]; ok {
+					return ToolState{Name: tool, Installed: true, Version: "1.9.8"}
+				}
+				return ToolState{Name: tool, Installed: tool == "node", Version: "20.11.0"}
+			},
+			Install: func(ctx context.Context, method, tool, pin string) error {
+				pins[tool] = pin
+				return nil
+			},
+		})
+
+		if pins["terraform"] != "1.9.x" {
+			t.Errorf("Expected terraform to be installed with pin 1.9.x, got %q", pins["terraform"])
+		}
+		if results[0].Status != RestoreInstalled {
+			t.Errorf("Expected 1.9.8 to satisfy 1.9.x, got %s", results[0].Status)
+		}
+	})
+
+	t.Run("exact mode pins recorded versions", func(t *testing.T) {
+		pins := map[string]string{}
+		Restore(context.Background(), lock, RestoreOptions{
+			Method: "Curl",
+			Exact:  true,
+			Check: func(ctx context.Context, tool string) ToolState {
+				return ToolState{Name: tool}
+			},
+			Install: func(ctx context.Context, method, tool, pin string) error {
+				pins[tool] = pin
+				return nil
+			},
+		})
+
+		if pins["node"] != "20.11.0" {
+			t.Errorf("Expected node pinned to 20.11.0, got %q", pins["node"])
+		}
+		if pins["terraform"] != "1.9.x" {
+			t.Errorf("Expected explicit pin to win over exact mode, got %q", pins["terraform"])
+		}
+	})
+}
+
+// TestRestore_Preflight tests refreshing the package index before restoring.
+// Priority: P1 - Restoring into a fresh container fails with "Unable to locate package" without it.
+// Tests that it runs once before the first install, not at all when nothing is missing,
+// and that a failure is reported without stopping the restore.
+func TestRestore_Preflight(t *testing.T) {
+	lock := &Lockfile{
+		Version: LockfileVersion,
+		Tools: []ToolState{
+			{Name: "git", Installed: true, Version: "2.39.5"},
+			{Name: "jq", Installed: true, Version: "1.7"},
+			{Name: "tmux", Installed: true, Version: "3.3"},
+		},
+	}
+
+	restore := func(missing map[string]bool, preflightErr error) (events []string) {
+		Restore(context.Background(), lock, RestoreOptions{
+			Method: "APT",
+			Check: func(ctx context.Context, tool string) ToolState {
+				return ToolState{Name: tool, Installed: !missing[tool]}
+			},
+			Install: func(ctx context.Context, method, tool, pin string) error {
+				events = append(events, "install "+tool)
+				return nil
+			},
+			Preflight: func(ctx context.Context, method string) error {
+				events = append(events, "preflight "+method)
+				return preflightErr
+			},
+			PreflightFailed: func(method string, err error) {
+				events = append(events, "failed "+err.Error())
+			},
+		})
+		return events
+	}
+
+	t.Run("once before the first install", func(t *testing.T) {
+		events := restore(map[string]bool{"jq": true, "tmux": true}, nil)
+		want := []string{"preflight APT", "install jq", "install tmux"}
+		if strings.Join(events, ", ") != strings.Join(want, ", ") {
+			t.Errorf("Expected %v, got %v", want, events)
+		}
+	})
+
+	t.Run("not when nothing is missing", func(t *testing.T) {
+		if events := restore(nil, nil); len(events) != 0 {
+			t.Errorf("Expected nothing to run, got %v", events)
+		}
+	})
+
+	t.Run("failure is reported and the restore goes on", func(t *testing.T) {
+		events := restore(map[string]bool{"git": true}, errors.New("network unreachable"))
+		want := []string{"preflight APT", "failed network unreachable", "install git"}
+		if strings.Join(events, ", ") != strings.Join(want, ", ") {
+			t.Errorf("Expected %v, got %v", want, events)
+		}
+	})
+}
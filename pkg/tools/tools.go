@@ -1,5 +1,7 @@
 package tools
 
+import "time"
+
 var Tools = []string{
 	// Version Control & Build (fundamental)
 	"git",
@@ -60,3 +62,27 @@ var Tools = []string{
 	"tldr",
 	"lazysql",
 }
+
+// DefaultTimeouts overrides the default install, update and uninstall timeout for tools
+// that routinely need much longer (source builds) or should never need long (small binaries)
+var DefaultTimeouts = map[string]time.Duration{
+	// Often built from source (pyenv, Nix, Curl recipes)
+	"python3": 45 * time.Minute,
+	"node":    30 * time.Minute,
+	"nvim":    30 * time.Minute,
+	"docker":  30 * time.Minute,
+	"wget":    30 * time.Minute,
+	"rsync":   30 * time.Minute,
+	"tree":    30 * time.Minute,
+
+	// Single small binaries
+	"jq":      5 * time.Minute,
+	"fzf":     5 * time.Minute,
+	"ripgrep": 5 * time.Minute,
+	"fd":      5 * time.Minute,
+	"bat":     5 * time.Minute,
+	"eza":     5 * time.Minute,
+	"zoxide":  5 * time.Minute,
+	"delta":   5 * time.Minute,
+	"just":    5 * time.Minute,
+}
@@ -40,5 +40,5 @@ func Layout(state *models.State) func(*gocui.Gui) error {
 func getSpinner(frame int) string {
 	spins := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 	spinner := spins[frame%len(spins)]
-	return fmt.Sprintf("%s%s%s", colors.ANSIMagenta, spinner, colors.ANSIReset)
+	return fmt.Sprintf("%s%s%s", colors.ANSIAccent, spinner, colors.ANSIReset)
 }
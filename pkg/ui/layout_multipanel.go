@@ -7,6 +7,8 @@ import (
 	"github.com/jesseduffield/gocui"
 	"github.com/youpele52/lazysetup/pkg/colors"
 	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/handlers"
+	"github.com/youpele52/lazysetup/pkg/keymap"
 	"github.com/youpele52/lazysetup/pkg/models"
 	"github.com/youpele52/lazysetup/pkg/ui/messages"
 )
@@ -189,7 +191,18 @@ func layoutMultiPanel(g *gocui.Gui, state *models.State, maxX, maxY int) error {
 	}
 
 	// Panel 0: Status/Results (right - full height, read-only, scrollable)
-	if v, err := g.SetView(constants.PanelProgress, leftPanelWidth+1, 0, maxX-1, panelHeight); err != nil {
+	// Shares its space with the AI suggestions view when that is open
+	statusEndX := maxX - 1
+	if state.GetShowAIPanel() {
+		statusEndX = leftPanelWidth + (maxX-leftPanelWidth)/2
+		if err := renderAISuggestionsPanel(g, statusEndX+1, 0, maxX-1, panelHeight, state); err != nil {
+			return err
+		}
+	} else {
+		g.DeleteView(constants.PanelAISuggestions)
+	}
+
+	if v, err := g.SetView(constants.PanelProgress, leftPanelWidth+1, 0, statusEndX, panelHeight); err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
@@ -218,7 +231,7 @@ func layoutMultiPanel(g *gocui.Gui, state *models.State, maxX, maxY int) error {
 			elapsed := time.Now().Unix() - state.UpdateMessageTime
 			if elapsed < 10 {
 				v.Clear()
-				fmt.Fprintf(v, "%s%s%s\n\n", colors.ANSIYellow, state.UpdateMessage, colors.ANSIReset)
+				fmt.Fprintf(v, "%s%s%s\n\n", colors.ANSIWarning, state.UpdateMessage, colors.ANSIReset)
 				fmt.Fprint(v, constants.Logo)
 				return nil
 			} else {
@@ -230,12 +243,12 @@ func layoutMultiPanel(g *gocui.Gui, state *models.State, maxX, maxY int) error {
 		// Show validation errors
 		if errorMsg != "" {
 			v.Clear()
-			fmt.Fprintf(v, "%s%s%s\n", colors.ANSIRed, errorMsg, colors.ANSIReset)
+			fmt.Fprintf(v, "%s✗ %s%s\n", colors.ANSIFailure, errorMsg, colors.ANSIReset)
 			return nil
 		}
 
-		// Auto-clear results after 40 seconds
-		if installationDone && completionTime > 0 {
+		// Auto-clear results after 40 seconds (kept while a rollback or AI suggestions are shown)
+		if installationDone && completionTime > 0 && !state.GetShowRollbackConfirm() && !state.GetShowAIPanel() {
 			elapsed := time.Now().Unix() - completionTime
 			if elapsed >= 40 {
 				// Clear and reset
@@ -260,6 +273,15 @@ func layoutMultiPanel(g *gocui.Gui, state *models.State, maxX, maxY int) error {
 				SpinnerFrame:     state.GetSpinnerFrame(),
 				InstallOutput:    state.GetInstallOutput(),
 				Action:           state.GetSelectedAction(),
+				RollingBack:      state.GetRollingBack(),
+				Tools:            state.GetToolProgress(),
+				Flights:          state.GetFlightSteps(),
+				QueuePaused:      state.GetQueuePaused(),
+				Now:              time.Now().Unix(),
+				Parallelism:      state.GetParallelism(),
+			}
+			if activePanel == models.PanelStatus {
+				params.SelectedTool = handlers.SelectedInFlightTool(state)
 			}
 			message := messages.BuildInstallationProgressMessage(params)
 			fmt.Fprint(v, message)
@@ -268,7 +290,7 @@ func layoutMultiPanel(g *gocui.Gui, state *models.State, maxX, maxY int) error {
 			// Latest results at top, oldest at bottom
 			selectedAction := state.GetSelectedAction()
 			newResults := results[lastRenderedCount:]
-			message := messages.BuildNewResultsMessage(newResults, selectedAction)
+			message := messages.BuildNewResultsMessage(newResults, selectedAction, state.GetSelectedMethod())
 			fmt.Fprint(v, message)
 			state.LastRenderedResultCount = len(results)
 		} else if len(results) == 0 {
@@ -277,7 +299,7 @@ func layoutMultiPanel(g *gocui.Gui, state *models.State, maxX, maxY int) error {
 
 			// Show Nix disclaimer if Nix is selected as package manager
 			if state.GetSelectedMethod() == "Nix" {
-				fmt.Fprintf(v, "%s%s%s\n\n", colors.ANSIYellow, constants.NixDisclaimer, colors.ANSIReset)
+				fmt.Fprintf(v, "%s%s%s\n\n", colors.ANSIWarning, constants.NixDisclaimer, colors.ANSIReset)
 			}
 
 			fmt.Fprint(v, constants.Logo)
@@ -296,24 +318,7 @@ func layoutMultiPanel(g *gocui.Gui, state *models.State, maxX, maxY int) error {
 
 	if v, err := g.View("status_bar"); err == nil {
 		v.Clear()
-		if state.GetIsSearchMode() {
-			// Search mode status
-			fmt.Fprintf(v, "Type to filter | ↑↓: Nav | Space: Toggle | /: Exit | Esc: Exit | ⏎: Confirm")
-		} else if state.GetActivePanel() == models.PanelTools {
-			// Normal tools panel status
-			if state.UpdateAvailable {
-				fmt.Fprintf(v, "Tab/0-3: Panels | ↑↓: Nav | g/w: First | G/s: Last | /: Search | Space: Toggle | ⏎: Confirm | C: Clear | U: Update | Ctrl+C: Quit")
-			} else {
-				fmt.Fprintf(v, "Tab/0-3: Panels | ↑↓: Nav | g/w: First | G/s: Last | /: Search | Space: Toggle | ⏎: Confirm | C: Clear | Esc: Back | Ctrl+C: Quit")
-			}
-		} else {
-			// Other panels status
-			if state.UpdateAvailable {
-				fmt.Fprintf(v, "Tab/0-3: Panels | ↑↓: Nav | g/w: First | G/s: Last | Space: Toggle | ⏎: Confirm | C: Clear | U: Update | Ctrl+C: Quit")
-			} else {
-				fmt.Fprintf(v, "Tab/0-3: Panels | ↑↓: Nav | g/w: First | G/s: Last | Space: Toggle | ⏎: Confirm | C: Clear | Esc: Back | Ctrl+C: Quit")
-			}
-		}
+		fmt.Fprint(v, statusBarHints(state))
 	}
 
 	// Render sudo confirmation popup if needed
@@ -326,6 +331,33 @@ func layoutMultiPanel(g *gocui.Gui, state *models.State, maxX, maxY int) error {
 		g.DeleteView(constants.PopupConfirm)
 	}
 
+	// Render rollback confirmation popup if a transactional batch partially failed
+	if state.GetShowRollbackConfirm() {
+		if err := renderRollbackConfirmPopup(g, maxX, maxY, state); err != nil {
+			return err
+		}
+	} else {
+		g.DeleteView(constants.PopupRollback)
+	}
+
+	// Render the release notes popup opened by the update key
+	if state.GetShowReleaseNotes() {
+		if err := renderReleaseNotesPopup(g, maxX, maxY, state); err != nil {
+			return err
+		}
+	} else {
+		g.DeleteView(constants.PopupReleaseNotes)
+	}
+
+	// Render the quit confirmation last so it covers any other popup
+	if state.GetShowQuitConfirm() {
+		if err := renderQuitConfirmPopup(g, maxX, maxY, state); err != nil {
+			return err
+		}
+	} else {
+		g.DeleteView(constants.PopupQuit)
+	}
+
 	return nil
 }
 
@@ -356,7 +388,7 @@ func renderTerminalTooSmallView(g *gocui.Gui, maxX, maxY, minPanelHeight, requir
 
 	if v, err := g.View(viewName); err == nil {
 		v.Clear()
-		fmt.Fprintf(v, "\n\n%s", colors.ANSIRed)
+		fmt.Fprintf(v, "\n\n%s", colors.ANSIFailure)
 		fmt.Fprintf(v, "  ╔════════════════════════════════════════════════════════╗\n")
 		fmt.Fprintf(v, "  ║                                                        ║\n")
 		fmt.Fprintf(v, "  ║     TERMINAL WINDOW TOO SMALL                          ║\n")
@@ -375,3 +407,31 @@ func renderTerminalTooSmallView(g *gocui.Gui, maxX, maxY, minPanelHeight, requir
 
 	return nil
 }
+
+// statusBarHints lists the keys of the active panel, with remapped keys shown as configured
+func statusBarHints(state *models.State) string {
+	km := state.GetKeymap()
+	if km == nil {
+		km = keymap.Default()
+	}
+	panels := km.Label(keymap.NextPanel) + "/0-3: Panels"
+	nav := fmt.Sprintf("%s | ↑↓: Nav | %s: First | %s: Last", panels, km.Label(keymap.JumpFirst), km.Label(keymap.JumpLast))
+	ending := "Esc: Back | Ctrl+C: Quit"
+	if state.UpdateAvailable {
+		ending = km.Label(keymap.Update) + ": Update | Ctrl+C: Quit"
+	}
+
+	switch {
+	case state.GetIsSearchMode():
+		return fmt.Sprintf("Type to filter | ↑↓: Nav | %s: Toggle | %s: Exit | Esc: Exit | %s: Confirm",
+			km.Label(keymap.Toggle), km.Label(keymap.Search), km.Label(keymap.Execute))
+	case state.GetActivePanel() == models.PanelStatus && state.GetInstallStartTime() > 0 && !state.GetInstallationDone():
+		// Running tools list in the status panel
+		return panels + " | ↑↓: Select tool | X: Cancel tool | P: Pause queue | Esc Esc: Cancel all | Ctrl+C: Quit"
+	case state.GetActivePanel() == models.PanelTools:
+		return fmt.Sprintf("%s | %s: Search | %s: Toggle | M: Method | T: Rollback | %s: Confirm | %s: Clear | %s",
+			nav, km.Label(keymap.Search), km.Label(keymap.Toggle), km.Label(keymap.Execute), km.Label(keymap.Clear), ending)
+	}
+	return fmt.Sprintf("%s | %s: Toggle | %s: Confirm | %s: Clear | %s",
+		nav, km.Label(keymap.Toggle), km.Label(keymap.Execute), km.Label(keymap.Clear), ending)
+}
@@ -8,7 +8,10 @@ import (
 	"github.com/youpele52/lazysetup/pkg/colors"
 	"github.com/youpele52/lazysetup/pkg/config"
 	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/handlers"
 	"github.com/youpele52/lazysetup/pkg/models"
+	"github.com/youpele52/lazysetup/pkg/ui/messages"
+	"github.com/youpele52/lazysetup/pkg/version"
 )
 
 // PanelParams groups common parameters for panel rendering
@@ -124,13 +127,13 @@ func renderPackageManagerPanel(params PackageManagerParams) error {
 
 			if activePanel == models.PanelPackageManager {
 				if i == cursor {
-					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIMagenta, marker, method, colors.ANSIReset)
+					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIAccent, marker, method, colors.ANSIReset)
 				} else {
-					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIGreen, marker, method, colors.ANSIReset)
+					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIActive, marker, method, colors.ANSIReset)
 				}
 			} else {
 				if i == cursor {
-					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIMagenta, marker, method, colors.ANSIReset)
+					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIAccent, marker, method, colors.ANSIReset)
 				} else {
 					fmt.Fprintf(v, "%s %s\n", marker, method)
 				}
@@ -230,13 +233,13 @@ func renderActionPanel(params ActionPanelParams) error {
 
 			if activePanel == models.PanelAction {
 				if i == cursor {
-					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIMagenta, marker, action, colors.ANSIReset)
+					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIAccent, marker, action, colors.ANSIReset)
 				} else {
-					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIGreen, marker, action, colors.ANSIReset)
+					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIActive, marker, action, colors.ANSIReset)
 				}
 			} else {
 				if i == cursor {
-					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIMagenta, marker, action, colors.ANSIReset)
+					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIAccent, marker, action, colors.ANSIReset)
 				} else {
 					fmt.Fprintf(v, "%s %s\n", marker, action)
 				}
@@ -279,6 +282,9 @@ func renderToolsPanel(params ToolsPanelParams) error {
 	if v, err := g.View(constants.PanelTools); err == nil {
 		// Keep title unchanged regardless of search mode
 		v.Title = "[3]-" + constants.TitleTools
+		if state.GetTransactionalMode() {
+			v.Title += constants.TitleTransactional
+		}
 
 		if activePanel == models.PanelTools {
 			v.FgColor = colors.ActiveBorderColor
@@ -359,9 +365,9 @@ func renderToolsPanel(params ToolsPanelParams) error {
 		// Render search bar if in search mode
 		if state.GetIsSearchMode() {
 			// "Search:" in white (same as title), query in cyan/blue
-			fmt.Fprintf(v, "%sSearch:%s %s%s%s\n", colors.ANSIWhite, colors.ANSIReset, colors.ANSICyan, state.GetSearchQuery(), colors.ANSIReset)
+			fmt.Fprintf(v, "%sSearch:%s %s%s%s\n", colors.ANSIText, colors.ANSIReset, colors.ANSIInfo, state.GetSearchQuery(), colors.ANSIReset)
 			// "Matches:" in green
-			fmt.Fprintf(v, "%sMatches: %d/%d%s\n", colors.ANSIGreen, len(displayToolList), len(state.Tools), colors.ANSIReset)
+			fmt.Fprintf(v, "%sMatches: %d/%d%s\n", colors.ANSIActive, len(displayToolList), len(state.Tools), colors.ANSIReset)
 		}
 
 		// Set scroll position
@@ -374,18 +380,27 @@ func renderToolsPanel(params ToolsPanelParams) error {
 			if state.SelectedTools[tool] {
 				marker = constants.CheckboxSelected
 			}
+			name := constants.GetToolDisplayName(tool)
+			if method := state.GetToolMethod(tool); method != "" {
+				name += fmt.Sprintf(constants.ToolMethodSuffix, method)
+			}
+			if i == cursor {
+				marker = constants.CursorMarker + marker
+			} else {
+				marker = constants.CursorBlank + marker
+			}
 
 			if activePanel == models.PanelTools {
 				if i == cursor {
-					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIMagenta, marker, constants.GetToolDisplayName(tool), colors.ANSIReset)
+					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIAccent, marker, name, colors.ANSIReset)
 				} else {
-					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIGreen, marker, constants.GetToolDisplayName(tool), colors.ANSIReset)
+					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIActive, marker, name, colors.ANSIReset)
 				}
 			} else {
 				if i == cursor {
-					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIMagenta, marker, constants.GetToolDisplayName(tool), colors.ANSIReset)
+					fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIAccent, marker, name, colors.ANSIReset)
 				} else {
-					fmt.Fprintf(v, "%s %s\n", marker, constants.GetToolDisplayName(tool))
+					fmt.Fprintf(v, "%s %s\n", marker, name)
 				}
 			}
 		}
@@ -438,3 +453,127 @@ func renderSudoConfirmPopup(g *gocui.Gui, maxX, maxY int, state *models.State) e
 
 	return nil
 }
+
+// renderRollbackConfirmPopup renders a centered popup offering to roll back
+// the tools a partially failed transactional install batch newly installed
+func renderRollbackConfirmPopup(g *gocui.Gui, maxX, maxY int, state *models.State) error {
+	popupWidth := 60
+	popupHeight := 10
+	x0 := (maxX - popupWidth) / 2
+	y0 := (maxY - popupHeight) / 2
+	x1 := x0 + popupWidth
+	y1 := y0 + popupHeight
+
+	if v, err := g.SetView(constants.PopupRollback, x0, y0, x1, y1); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Wrap = true
+		v.FgColor = colors.TextPrimary
+		v.Editable = false
+	}
+
+	if v, err := g.View(constants.PopupRollback); err == nil {
+		v.Title = constants.RollbackConfirmTitle
+		v.Clear()
+		fmt.Fprintf(v, constants.RollbackConfirmMessage, strings.Join(state.GetNewlyInstalled(), ", "))
+	}
+
+	// Bring popup to front
+	g.SetViewOnTop(constants.PopupRollback)
+	g.SetCurrentView(constants.PopupRollback)
+
+	return nil
+}
+
+// renderQuitConfirmPopup renders a centered popup asking whether to cancel the running
+// actions and quit
+func renderQuitConfirmPopup(g *gocui.Gui, maxX, maxY int, state *models.State) error {
+	popupWidth := 64
+	popupHeight := 12
+	x0 := (maxX - popupWidth) / 2
+	y0 := (maxY - popupHeight) / 2
+	x1 := x0 + popupWidth
+	y1 := y0 + popupHeight
+
+	if v, err := g.SetView(constants.PopupQuit, x0, y0, x1, y1); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Wrap = true
+		v.FgColor = colors.TextPrimary
+		v.Editable = false
+	}
+
+	if v, err := g.View(constants.PopupQuit); err == nil {
+		v.Title = constants.QuitConfirmTitle
+		v.Clear()
+		fmt.Fprint(v, handlers.QuitConfirmMessage(state))
+	}
+
+	// Bring popup to front
+	g.SetViewOnTop(constants.PopupQuit)
+	g.SetCurrentView(constants.PopupQuit)
+
+	return nil
+}
+
+// renderReleaseNotesPopup renders the notes of the pending releases in a centered popup
+// The content is written once when the popup opens so scrolling is not reset every frame
+func renderReleaseNotesPopup(g *gocui.Gui, maxX, maxY int, state *models.State) error {
+	popupWidth := min(maxX-4, 90)
+	popupHeight := maxY - 4
+	x0 := (maxX - popupWidth) / 2
+	y0 := (maxY - popupHeight) / 2
+	x1 := x0 + popupWidth
+	y1 := y0 + popupHeight
+
+	if v, err := g.SetView(constants.PopupReleaseNotes, x0, y0, x1, y1); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Wrap = true
+		v.FgColor = colors.TextPrimary
+		v.Editable = false
+		v.Title = fmt.Sprintf(constants.ReleaseNotesTitle, version.Version, state.UpdateVersion)
+		fmt.Fprint(v, messages.BuildReleaseNotesMessage(state.UpdateNotes))
+	}
+
+	// Bring popup to front
+	g.SetViewOnTop(constants.PopupReleaseNotes)
+	g.SetCurrentView(constants.PopupReleaseNotes)
+
+	return nil
+}
+
+// renderAISuggestionsPanel renders suggested fixes for failed tools beside the results
+func renderAISuggestionsPanel(g *gocui.Gui, x0, y0, x1, y1 int, state *models.State) error {
+	v, err := g.SetView(constants.PanelAISuggestions, x0, y0, x1, y1)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Wrap = true
+		v.Title = constants.TitleAISuggestions
+		v.FgColor = colors.TextPrimary
+	}
+	v.Clear()
+
+	if message := state.GetAIMessage(); message != "" {
+		fmt.Fprintf(v, "%s%s%s\n\n", colors.ANSIInfo, message, colors.ANSIReset)
+	}
+
+	for _, suggestion := range state.GetAISuggestions() {
+		fmt.Fprintf(v, "%s%s%s\n", colors.ANSIAccent, suggestion.Tool, colors.ANSIReset)
+		if suggestion.Error != "" {
+			fmt.Fprintf(v, "%s%s%s\n\n", colors.ANSIFailure, suggestion.Error, colors.ANSIReset)
+			continue
+		}
+		fmt.Fprintf(v, "%s\n\n", suggestion.Text)
+	}
+
+	if state.GetAIPending() {
+		fmt.Fprintf(v, "%s%s%s\n", colors.ANSIWarning, constants.AIRequesting, colors.ANSIReset)
+	}
+	return nil
+}
@@ -44,16 +44,21 @@ func layoutToolsPage(g *gocui.Gui, state *models.State, maxX, maxY int) error {
 			} else {
 				marker = constants.CheckboxUnselected
 			}
+			if i == state.ToolsScroll.Cursor {
+				marker = constants.CursorMarker + marker
+			} else {
+				marker = constants.CursorBlank + marker
+			}
 			// Active panel: green text by default
 			if i == state.ToolsScroll.Cursor {
 				// Cursor position: magenta text (will have magenta background from highlight)
-				fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIMagenta, marker, constants.GetToolDisplayName(tool), colors.ANSIReset)
+				fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIAccent, marker, constants.GetToolDisplayName(tool), colors.ANSIReset)
 			} else if selected {
 				// Selected item: magenta text
-				fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIMagenta, marker, constants.GetToolDisplayName(tool), colors.ANSIReset)
+				fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIAccent, marker, constants.GetToolDisplayName(tool), colors.ANSIReset)
 			} else {
 				// Unselected item: green text
-				fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIGreen, marker, constants.GetToolDisplayName(tool), colors.ANSIReset)
+				fmt.Fprintf(v, "%s%s %s%s\n", colors.ANSIActive, marker, constants.GetToolDisplayName(tool), colors.ANSIReset)
 			}
 		}
 		v.SetCursor(0, state.ToolsScroll.Cursor)
@@ -0,0 +1,192 @@
+package messages
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/youpele52/lazysetup/pkg/colors"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/history"
+	"github.com/youpele52/lazysetup/pkg/models"
+)
+
+const (
+	progressBarWidth = 20 // Cells in the overall progress bar
+	lastLineWidth    = 60 // Runes of a tool's latest output line shown in its row
+)
+
+// addDashboard renders the overall progress bar and one row per tool of the running action,
+// with pre-flight commands above the tools and post-flight commands below them
+func addDashboard(mb *MessageBuilder, params ProgressMessageParams, spinnerFrame string) {
+	mb.AddLine(progressBar(params.Tools))
+	if eta, ok := estimateRemaining(params); ok {
+		mb.AddLine(fmt.Sprintf(constants.ETALine, formatSeconds(eta)))
+	}
+	if params.QueuePaused {
+		mb.AddLine(fmt.Sprintf("%s%s%s", colors.ANSIWarning, constants.QueuePaused, colors.ANSIReset))
+	}
+	if params.SelectedTool != "" {
+		mb.AddLine(constants.InFlightHeader)
+	}
+	mb.AddLine("")
+
+	nameWidth := 0
+	for _, row := range params.Tools {
+		if len(row.Tool) > nameWidth {
+			nameWidth = len(row.Tool)
+		}
+	}
+	addFlightRows(mb, params, models.StagePreflight, spinnerFrame)
+	for _, row := range params.Tools {
+		mb.AddLine(dashboardRow(row, params, nameWidth, spinnerFrame))
+	}
+	addFlightRows(mb, params, models.StagePostflight, spinnerFrame)
+}
+
+// addFlightRows renders the flight commands of one stage, set apart from the tool rows by a blank line
+func addFlightRows(mb *MessageBuilder, params ProgressMessageParams, stage models.FlightStage, spinnerFrame string) {
+	var rows []string
+	for _, step := range params.Flights {
+		if step.Stage != stage {
+			continue
+		}
+		icon, color := phaseIcon(step.Phase, spinnerFrame)
+		end := params.Now
+		if step.Done() {
+			end = step.EndTime
+		}
+		rows = append(rows, fmt.Sprintf("  %s%s %s %s %s%s %5ds", color, icon, step.Stage, step.Method, step.Phase, colors.ANSIReset, end-step.StartTime))
+	}
+	if len(rows) == 0 {
+		return
+	}
+	if stage == models.StagePostflight {
+		mb.AddLine("")
+	}
+	for _, row := range rows {
+		mb.AddLine(row)
+	}
+	if stage == models.StagePreflight {
+		mb.AddLine("")
+	}
+}
+
+// phaseIcon returns the state icon and color of a dashboard row
+func phaseIcon(phase models.ToolPhase, spinnerFrame string) (string, string) {
+	switch phase {
+	case models.PhaseRunning:
+		return spinnerFrame, colors.ANSIProgress
+	case models.PhaseRetrying:
+		return "↻", colors.ANSIWarning
+	case models.PhaseSucceeded:
+		return "✓", colors.ANSISuccess
+	case models.PhaseFailed:
+		return "✗", colors.ANSIFailure
+	}
+	return "·", colors.ANSIText
+}
+
+// progressBar renders how many tools have finished, with running and failed counts
+func progressBar(rows []models.ToolProgress) string {
+	done, running, failed := 0, 0, 0
+	for _, row := range rows {
+		switch row.Phase {
+		case models.PhaseSucceeded:
+			done++
+		case models.PhaseFailed:
+			done++
+			failed++
+		case models.PhaseRunning, models.PhaseRetrying:
+			running++
+		}
+	}
+	filled := done * progressBarWidth / len(rows)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	line := fmt.Sprintf("[%s] %d/%d done, %d running", bar, done, len(rows), running)
+	if failed > 0 {
+		line += fmt.Sprintf(", %s%d failed%s", colors.ANSIFailure, failed, colors.ANSIReset)
+	}
+	return line
+}
+
+// dashboardRow renders one tool: selection marker, state icon, name, state, elapsed time
+// and its latest output line
+func dashboardRow(row models.ToolProgress, params ProgressMessageParams, nameWidth int, spinnerFrame string) string {
+	marker := " "
+	if row.Tool == params.SelectedTool {
+		marker = colors.ANSIInfo + "▸" + colors.ANSIReset
+	}
+
+	icon, color := phaseIcon(row.Phase, spinnerFrame)
+	phase := string(row.Phase)
+	if row.Phase == models.PhaseRetrying {
+		phase = fmt.Sprintf("%s (attempt %d)", row.Phase, row.Attempt)
+	}
+
+	elapsed := ""
+	switch {
+	case row.Done():
+		elapsed = fmt.Sprintf("%ds", row.EndTime-row.StartTime)
+	case row.StartTime > 0:
+		elapsed = fmt.Sprintf("%ds", params.Now-row.StartTime)
+	}
+
+	line := fmt.Sprintf("%s %s%s %-*s %-9s%s %5s", marker, color, icon, nameWidth, row.Tool, phase, colors.ANSIReset, elapsed)
+	if !row.Done() && row.StartTime > 0 && history.ProbablyHung(params.Now-row.StartTime, row.Expected) {
+		line += fmt.Sprintf("  %s"+constants.ProbablyHung+"%s", colors.ANSIFailure, formatSeconds(row.Expected), colors.ANSIReset)
+	}
+	if row.LastLine != "" && !row.Done() {
+		line += "  " + truncateRunes(row.LastLine, lastLineWidth)
+	}
+	return line
+}
+
+// estimateRemaining returns the seconds until the action finishes, from each tool's usual
+// duration; tools without history count as the average of those with it
+// ok is false when no tool has history
+func estimateRemaining(params ProgressMessageParams) (int64, bool) {
+	var total, known int64
+	for _, row := range params.Tools {
+		if row.Expected > 0 {
+			total += row.Expected
+			known++
+		}
+	}
+	if known == 0 {
+		return 0, false
+	}
+	average := total / known
+
+	var running, queued []int64
+	for _, row := range params.Tools {
+		expected := row.Expected
+		if expected == 0 {
+			expected = average
+		}
+		switch {
+		case row.Done():
+		case row.StartTime > 0:
+			running = append(running, expected-(params.Now-row.StartTime))
+		default:
+			queued = append(queued, expected)
+		}
+	}
+	return history.EstimateRemaining(running, queued, params.Parallelism), true
+}
+
+// formatSeconds renders a duration as "45s" or "2m05s"
+func formatSeconds(seconds int64) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%ds", seconds)
+	}
+	return fmt.Sprintf("%dm%02ds", seconds/60, seconds%60)
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut with "…"
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -9,10 +9,6 @@ import (
 	"github.com/youpele52/lazysetup/pkg/models"
 )
 
-const (
-	ANSIBlue = "\033[1;34m"
-)
-
 type ProgressMessageParams struct {
 	SelectedMethod   string
 	CurrentTool      string
@@ -22,19 +18,29 @@ type ProgressMessageParams struct {
 	SpinnerFrame     int
 	InstallOutput    string
 	Action           models.ActionType
+	RollingBack      bool
+	Tools            []models.ToolProgress // Dashboard rows; empty for actions that do not track tools
+	Flights          []models.FlightStep   // Pre-flight and post-flight commands shown around the tool rows
+	SelectedTool     string                // Running tool selected in the Status panel, "" when none
+	QueuePaused      bool                  // Whether queued tools are held back
+	Now              int64                 // Unix timestamp used for elapsed times
+	Parallelism      int                   // Tools run at once, used for the ETA
 }
 
 func BuildInstallationProgressMessage(params ProgressMessageParams) string {
 	mb := NewMessageBuilder()
 
 	// Spinner animation for in-progress installation
-	spinnerColor := ANSIBlue
+	spinnerColor := colors.ANSIProgress
 	spinnerFrames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 	spinnerFrame := spinnerFrames[params.SpinnerFrame%len(spinnerFrames)]
 	actionVerb := getActionVerb(params.Action)
+	if params.RollingBack {
+		actionVerb = constants.ToolActionRollback
+	}
 
 	if params.InstallationDone {
-		mb.AddLine(fmt.Sprintf("%sInstallation completed!%s", colors.ANSIGreen, colors.ANSIReset))
+		mb.AddLine(fmt.Sprintf("%sInstallation completed!%s", colors.ANSISuccess, colors.ANSIReset))
 	} else {
 		// Progress line with spinner and action
 		progressLine := fmt.Sprintf(
@@ -46,8 +52,13 @@ func BuildInstallationProgressMessage(params ProgressMessageParams) string {
 		)
 		mb.AddLine(progressLine)
 
+		if len(params.Tools) > 0 {
+			addDashboard(mb, params, spinnerFrame)
+			return mb.Build()
+		}
+
 		// Tool name on next line
-		toolLine := fmt.Sprintf("%s✓ %s%s", colors.ANSIGreen, params.CurrentTool, colors.ANSIReset)
+		toolLine := fmt.Sprintf("%s✓ %s%s", colors.ANSISuccess, params.CurrentTool, colors.ANSIReset)
 		mb.AddLine(toolLine)
 
 		// Show install output if available
@@ -64,16 +75,24 @@ func BuildInstallationProgressMessage(params ProgressMessageParams) string {
 	return mb.Build()
 }
 
-func BuildNewResultsMessage(results []models.InstallResult, action models.ActionType) string {
+// BuildNewResultsMessage renders results newest first
+// Tools that ran with a method other than selectedMethod are marked "via <method>"
+func BuildNewResultsMessage(results []models.InstallResult, action models.ActionType, selectedMethod string) string {
 	mb := NewMessageBuilder()
 
 	for i := len(results) - 1; i >= 0; i-- {
 		result := results[i]
 		verb := getActionVerb(action)
 
-		if result.Success {
-			successLine := fmt.Sprintf("%s✓ %s%s",
-				colors.ANSIGreen, result.Tool, colors.ANSIReset)
+		if result.RolledBack {
+			addRollbackResult(mb, result)
+		} else if result.Success {
+			successLine := fmt.Sprintf("%s✓ %s%s%s",
+				colors.ANSISuccess, result.Tool, viaMethod(result, selectedMethod), colors.ANSIReset)
+			if retries := formatRetries(result.Retries); retries != "" {
+				successLine = fmt.Sprintf("%s✓ %s%s (%s)%s",
+					colors.ANSISuccess, result.Tool, viaMethod(result, selectedMethod), retries, colors.ANSIReset)
+			}
 			mb.AddLine(successLine)
 
 			// For check action, show version output on success (white/default color)
@@ -87,8 +106,8 @@ func BuildNewResultsMessage(results []models.InstallResult, action models.Action
 				}
 			}
 		} else {
-			failedLine := fmt.Sprintf("%s✗ %s - %s failed (%ds)%s",
-				colors.ANSIRed, result.Tool, verb, result.Duration, colors.ANSIReset)
+			failedLine := fmt.Sprintf("%s✗ %s%s - %s failed (%ds%s)%s",
+				colors.ANSIFailure, result.Tool, viaMethod(result, selectedMethod), verb, result.Duration, retryNote(result.Retries), colors.ANSIReset)
 			mb.AddLine(failedLine)
 
 			if result.Error != "" {
@@ -96,12 +115,13 @@ func BuildNewResultsMessage(results []models.InstallResult, action models.Action
 				for _, errLine := range errorLines {
 					if strings.TrimSpace(errLine) != "" {
 						displayLine := fmt.Sprintf("%s  %s%s",
-							colors.ANSIRed, strings.TrimSpace(errLine), colors.ANSIReset)
+							colors.ANSIFailure, strings.TrimSpace(errLine), colors.ANSIReset)
 						mb.AddLine(displayLine)
 						break
 					}
 				}
 			}
+			addFailureHint(mb, result)
 		}
 		mb.AddBlankLine()
 	}
@@ -109,6 +129,61 @@ func BuildNewResultsMessage(results []models.InstallResult, action models.Action
 	return mb.Build()
 }
 
+// formatRetries describes how many times an action was retried, or "" when it was not
+func formatRetries(retries int) string {
+	switch {
+	case retries <= 0:
+		return ""
+	case retries == 1:
+		return "1 retry"
+	default:
+		return fmt.Sprintf("%d retries", retries)
+	}
+}
+
+// retryNote returns ", N retries" for use after a duration, or "" when the action was not retried
+func retryNote(retries int) string {
+	if formatted := formatRetries(retries); formatted != "" {
+		return ", " + formatted
+	}
+	return ""
+}
+
+// viaMethod returns " via <method>" when a result ran with a method other than the selected one
+func viaMethod(result models.InstallResult, selectedMethod string) string {
+	if result.Method == "" || result.Method == selectedMethod {
+		return ""
+	}
+	return " via " + result.Method
+}
+
+// addRollbackResult renders the outcome of rolling back a newly installed tool
+func addRollbackResult(mb *MessageBuilder, result models.InstallResult) {
+	if result.Success {
+		mb.AddLine(fmt.Sprintf("%s↺ %s - rolled back (%ds%s)%s",
+			colors.ANSIWarning, result.Tool, result.Duration, retryNote(result.Retries), colors.ANSIReset))
+		return
+	}
+
+	mb.AddLine(fmt.Sprintf("%s✗ %s - %s failed (%ds%s)%s",
+		colors.ANSIFailure, result.Tool, constants.ToolActionRollback, result.Duration, retryNote(result.Retries), colors.ANSIReset))
+	for _, errLine := range strings.Split(result.Error, "\n") {
+		if strings.TrimSpace(errLine) != "" {
+			mb.AddLine(fmt.Sprintf("%s  %s%s", colors.ANSIFailure, strings.TrimSpace(errLine), colors.ANSIReset))
+			break
+		}
+	}
+	addFailureHint(mb, result)
+}
+
+// addFailureHint renders the classified cause of a failure and what to do about it
+func addFailureHint(mb *MessageBuilder, result models.InstallResult) {
+	if result.Hint == "" {
+		return
+	}
+	mb.AddLine(fmt.Sprintf("%s  → %s: %s%s", colors.ANSIWarning, result.Category, result.Hint, colors.ANSIReset))
+}
+
 func getActionVerb(action models.ActionType) string {
 	switch action {
 	case models.ActionInstall:
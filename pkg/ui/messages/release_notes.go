@@ -0,0 +1,39 @@
+package messages
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/youpele52/lazysetup/pkg/colors"
+	"github.com/youpele52/lazysetup/pkg/constants"
+	"github.com/youpele52/lazysetup/pkg/markdown"
+	"github.com/youpele52/lazysetup/pkg/updater"
+)
+
+// BuildReleaseNotesMessage renders the notes of each pending release, newest first, each
+// under its version and publication date
+func BuildReleaseNotesMessage(notes []updater.ReleaseNote) string {
+	mb := NewMessageBuilder()
+	mb.AddLine(fmt.Sprintf("%s%s%s", colors.ANSIText, constants.ReleaseNotesHint, colors.ANSIReset))
+
+	for _, note := range notes {
+		mb.AddBlankLine()
+		heading := fmt.Sprintf(constants.ReleaseNotesVersion, note.Version)
+		if date, _, ok := strings.Cut(note.PublishedAt, "T"); ok {
+			heading += " · " + date
+		}
+		mb.AddLine(fmt.Sprintf("%s%s%s%s", colors.ANSIBold, colors.ANSISuccess, heading, colors.ANSIReset))
+		mb.AddLine(strings.Repeat("─", len([]rune(heading))))
+
+		body := markdown.Render(note.Body)
+		if body == "" {
+			body = constants.ReleaseNotesEmpty
+		}
+		mb.AddLine(body)
+		if note.URL != "" {
+			mb.AddBlankLine()
+			mb.AddLine(fmt.Sprintf(constants.ReleaseNotesLink, note.URL))
+		}
+	}
+	return mb.Build()
+}
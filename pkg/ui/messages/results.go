@@ -14,11 +14,18 @@ func BuildInstallationResultsMessage(results []models.InstallResult, action mode
 	failureCount := 0
 	isCheckAction := action == models.ActionCheck
 
+	rolledBackCount := 0
+
 	for i := len(results) - 1; i >= 0; i-- {
 		result := results[i]
-		if result.Success {
+		if result.RolledBack {
+			addRollbackResult(mb, result)
+			if result.Success {
+				rolledBackCount++
+			}
+		} else if result.Success {
 			if isCheckAction && result.Error != "" {
-				mb.AddLine(fmt.Sprintf("%s✓ %s%s", colors.ANSIGreen, result.Tool, colors.ANSIReset))
+				mb.AddLine(fmt.Sprintf("%s✓ %s%s", colors.ANSISuccess, result.Tool, colors.ANSIReset))
 				versionLines := strings.Split(strings.TrimSpace(result.Error), "\n")
 				for _, versionLine := range versionLines {
 					if strings.TrimSpace(versionLine) != "" {
@@ -27,24 +34,25 @@ func BuildInstallationResultsMessage(results []models.InstallResult, action mode
 				}
 			} else {
 				verb := getActionVerb(action)
-				successLine := fmt.Sprintf("%s✓ %s - %s successful (%ds)%s", colors.ANSIGreen, result.Tool, verb, result.Duration, colors.ANSIReset)
+				successLine := fmt.Sprintf("%s✓ %s - %s successful (%ds%s)%s", colors.ANSISuccess, result.Tool, verb, result.Duration, retryNote(result.Retries), colors.ANSIReset)
 				mb.AddLine(successLine)
 			}
 			successCount++
 		} else {
 			verb := getActionVerb(action)
-			failedLine := fmt.Sprintf("%s✗ %s - %s failed (%ds)%s", colors.ANSIRed, result.Tool, verb, result.Duration, colors.ANSIReset)
+			failedLine := fmt.Sprintf("%s✗ %s - %s failed (%ds%s)%s", colors.ANSIFailure, result.Tool, verb, result.Duration, retryNote(result.Retries), colors.ANSIReset)
 			mb.AddLine(failedLine)
 			if result.Error != "" {
 				errorLines := strings.Split(result.Error, "\n")
 				for _, errLine := range errorLines {
 					if strings.TrimSpace(errLine) != "" {
-						displayLine := fmt.Sprintf("%s  %s%s", colors.ANSIRed, strings.TrimSpace(errLine), colors.ANSIReset)
+						displayLine := fmt.Sprintf("%s  %s%s", colors.ANSIFailure, strings.TrimSpace(errLine), colors.ANSIReset)
 						mb.AddLine(displayLine)
 						break
 					}
 				}
 			}
+			addFailureHint(mb, result)
 			failureCount++
 		}
 		mb.AddBlankLine()
@@ -52,6 +60,9 @@ func BuildInstallationResultsMessage(results []models.InstallResult, action mode
 
 	mb.AddSeparator()
 	mb.AddLine(fmt.Sprintf("Total: %d Success, %d Failed", successCount, failureCount))
+	if rolledBackCount > 0 {
+		mb.AddLine(fmt.Sprintf("Rolled back: %d", rolledBackCount))
+	}
 	mb.AddSeparator()
 
 	return mb.Build()
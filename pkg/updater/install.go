@@ -0,0 +1,284 @@
+package updater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ChecksumsAsset is the release asset listing the SHA-256 of every other asset
+const ChecksumsAsset = "SHA256SUMS"
+
+// BackupSuffix is appended to the executable's path for the copy kept by an update
+const BackupSuffix = ".previous"
+
+// smokeTestTimeout bounds how long the new binary may take to print its version
+const smokeTestTimeout = 10 * time.Second
+
+// Download describes the release asset an update installs
+type Download struct {
+	URL         string // Asset download URL
+	Name        string // Asset file name, as listed in SHA256SUMS
+	ChecksumURL string // URL of the release's SHA256SUMS
+	Version     string // Version the new binary must report, "" to skip the check
+}
+
+// DownloadAndInstall replaces the running executable with a verified release asset
+// The asset's SHA-256 must match the release's SHA256SUMS, archives are extracted, and the
+// new binary must run `--version` successfully before it atomically replaces the executable.
+// The replaced executable is kept next to it with BackupSuffix for RollbackUpdate
+func DownloadAndInstall(d Download) error {
+	execPath, err := executablePath()
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 5 * time.Minute}
+	return install(client, d, execPath)
+}
+
+// RollbackUpdate restores the executable an update replaced
+// Returns the path that was restored
+func RollbackUpdate() (string, error) {
+	execPath, err := executablePath()
+	if err != nil {
+		return "", err
+	}
+	return execPath, rollback(execPath)
+}
+
+// executablePath returns the running executable with symlinks resolved, so a symlinked
+// install replaces its target rather than the link
+func executablePath() (string, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+	return resolved, nil
+}
+
+// install downloads, verifies, smoke-tests and swaps in the asset for execPath
+func install(client *http.Client, d Download, execPath string) error {
+	if d.URL == "" {
+		return fmt.Errorf("no download URL available for this platform")
+	}
+	if d.ChecksumURL == "" {
+		return fmt.Errorf("release has no %s, refusing to install an unverified binary", ChecksumsAsset)
+	}
+
+	sums, err := fetch(client, d.ChecksumURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", ChecksumsAsset, err)
+	}
+	want, ok := parseChecksums(sums)[d.Name]
+	if !ok {
+		return fmt.Errorf("%s has no entry for %s", ChecksumsAsset, d.Name)
+	}
+
+	asset, err := fetch(client, d.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	sum := sha256.Sum256(asset)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", d.Name, want, got)
+	}
+
+	binary, err := extractBinary(d.Name, asset)
+	if err != nil {
+		return err
+	}
+
+	// Stage next to the executable so the final rename stays on one filesystem
+	dir := filepath.Dir(execPath)
+	staged, err := writeTemp(dir, ".lazysetup-update-*", bytes.NewReader(binary))
+	if err != nil {
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+	defer os.Remove(staged)
+
+	if err := smokeTest(staged, d.Version); err != nil {
+		return err
+	}
+	if err := backup(execPath); err != nil {
+		return err
+	}
+	if err := os.Rename(staged, execPath); err != nil {
+		return fmt.Errorf("failed to replace executable: %w", err)
+	}
+	return nil
+}
+
+// fetch downloads url into memory
+func fetch(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parseChecksums reads sha256sum output: "<hex>  <name>" per line, "*name" in binary mode
+func parseChecksums(data []byte) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// extractBinary returns the lazysetup executable inside a .tar.gz, .tgz or .zip asset,
+// or the asset itself when it is a raw binary
+func extractBinary(name string, asset []byte) ([]byte, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz"):
+		return extractTarGz(asset)
+	case strings.HasSuffix(lower, ".zip"):
+		return extractZip(asset)
+	}
+	return asset, nil
+}
+
+// isBinaryEntry reports whether an archive entry is the lazysetup executable
+func isBinaryEntry(entry string) bool {
+	base := path.Base(entry)
+	return base == "lazysetup" || base == "lazysetup.exe"
+}
+
+func extractTarGz(asset []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(asset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("archive does not contain a lazysetup binary")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && isBinaryEntry(header.Name) {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+func extractZip(asset []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(asset), int64(len(asset)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() || !isBinaryEntry(file.Name) {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("archive does not contain a lazysetup binary")
+}
+
+// smokeTest runs the staged binary with --version and checks it reports wantVersion
+func smokeTest(binary, wantVersion string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), smokeTestTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, binary, "--version").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("new version failed to start, keeping the current one: %w", err)
+	}
+	got := strings.TrimPrefix(strings.TrimSpace(string(output)), "v")
+	if want := strings.TrimPrefix(wantVersion, "v"); want != "" && got != want {
+		return fmt.Errorf("new binary reports version %q, expected %s; keeping the current one", got, want)
+	}
+	return nil
+}
+
+// backup copies the executable to its BackupSuffix path, replacing any older backup
+func backup(execPath string) error {
+	current, err := os.Open(execPath)
+	if err != nil {
+		return fmt.Errorf("failed to back up current version: %w", err)
+	}
+	defer current.Close()
+	tmp, err := writeTemp(filepath.Dir(execPath), ".lazysetup-backup-*", current)
+	if err != nil {
+		return fmt.Errorf("failed to back up current version: %w", err)
+	}
+	if err := os.Rename(tmp, execPath+BackupSuffix); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to back up current version: %w", err)
+	}
+	return nil
+}
+
+// rollback moves the backup over the executable
+func rollback(execPath string) error {
+	backupPath := execPath + BackupSuffix
+	if _, err := os.Stat(backupPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no previous version to restore (%s not found)", backupPath)
+		}
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	if err := os.Rename(backupPath, execPath); err != nil {
+		return fmt.Errorf("failed to restore previous version: %w", err)
+	}
+	return nil
+}
+
+// writeTemp writes r to a new executable file in dir and returns its path
+func writeTemp(dir, pattern string, r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
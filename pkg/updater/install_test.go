@@ -0,0 +1,265 @@
+package updater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeBinary is a script standing in for a lazysetup release that prints its version
+func fakeBinary(version string) []byte {
+	return []byte("#!/bin/sh\necho " + version + "\n")
+}
+
+// tarGz packs a single file into a .tar.gz archive
+func tarGz(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// zipOf packs a single file into a .zip archive
+func zipOf(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+// releaseServer serves one asset and a SHA256SUMS listing sumOf's checksum for it
+func releaseServer(t *testing.T, name string, asset, sumOf []byte) (*httptest.Server, Download) {
+	t.Helper()
+	sum := sha256.Sum256(sumOf)
+	sums := hex.EncodeToString(sum[:]) + "  " + name + "\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + ChecksumsAsset:
+			w.Write([]byte(sums))
+		case "/" + name:
+			w.Write(asset)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, Download{
+		URL:         server.URL + "/" + name,
+		Name:        name,
+		ChecksumURL: server.URL + "/" + ChecksumsAsset,
+		Version:     "9.9.9",
+	}
+}
+
+// installedExecutable writes a fake current version and returns its path
+func installedExecutable(t *testing.T) string {
+	t.Helper()
+	execPath := filepath.Join(t.TempDir(), "lazysetup")
+	if err := os.WriteFile(execPath, fakeBinary("1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return execPath
+}
+
+// TestInstall tests verified self-update installation.
+// Priority: P0 - A bad download must never replace the working binary.
+// Tests checksum verification, archives, the smoke test, backups and rollback.
+func TestInstall(t *testing.T) {
+	t.Run("installs a verified raw binary and keeps a backup", func(t *testing.T) {
+		execPath := installedExecutable(t)
+		binary := fakeBinary("9.9.9")
+		server, d := releaseServer(t, "lazysetup-9.9.9-linux-amd64", binary, binary)
+
+		if err := install(server.Client(), d, execPath); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got, _ := os.ReadFile(execPath); !bytes.Equal(got, binary) {
+			t.Errorf("Expected executable replaced, got %q", got)
+		}
+		if got, _ := os.ReadFile(execPath + BackupSuffix); !bytes.Equal(got, fakeBinary("1.0.0")) {
+			t.Errorf("Expected previous version backed up, got %q", got)
+		}
+
+		if err := rollback(execPath); err != nil {
+			t.Fatalf("Expected rollback to succeed, got %v", err)
+		}
+		if got, _ := os.ReadFile(execPath); !bytes.Equal(got, fakeBinary("1.0.0")) {
+			t.Errorf("Expected previous version restored, got %q", got)
+		}
+		if err := rollback(execPath); err == nil {
+			t.Error("Expected a second rollback to fail without a backup")
+		}
+	})
+
+	t.Run("extracts the binary from a tar.gz archive", func(t *testing.T) {
+		execPath := installedExecutable(t)
+		archive := tarGz(t, "lazysetup-9.9.9/lazysetup", fakeBinary("9.9.9"))
+		server, d := releaseServer(t, "lazysetup-9.9.9-linux-amd64.tar.gz", archive, archive)
+
+		if err := install(server.Client(), d, execPath); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got, _ := os.ReadFile(execPath); !bytes.Equal(got, fakeBinary("9.9.9")) {
+			t.Errorf("Expected extracted binary installed, got %q", got)
+		}
+	})
+
+	t.Run("rejects a checksum mismatch", func(t *testing.T) {
+		execPath := installedExecutable(t)
+		server, d := releaseServer(t, "lazysetup-9.9.9-linux-amd64", fakeBinary("9.9.9"), []byte("something else"))
+
+		err := install(server.Client(), d, execPath)
+		if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Errorf("Expected checksum mismatch, got %v", err)
+		}
+		assertUnchanged(t, execPath)
+	})
+
+	t.Run("rejects a release without SHA256SUMS", func(t *testing.T) {
+		execPath := installedExecutable(t)
+		server, d := releaseServer(t, "lazysetup-9.9.9-linux-amd64", fakeBinary("9.9.9"), fakeBinary("9.9.9"))
+		d.ChecksumURL = ""
+
+		if err := install(server.Client(), d, execPath); err == nil {
+			t.Error("Expected error without SHA256SUMS")
+		}
+		assertUnchanged(t, execPath)
+	})
+
+	t.Run("rejects a binary that fails the smoke test", func(t *testing.T) {
+		execPath := installedExecutable(t)
+		broken := []byte("#!/bin/sh\nexit 1\n")
+		server, d := releaseServer(t, "lazysetup-9.9.9-linux-amd64", broken, broken)
+
+		if err := install(server.Client(), d, execPath); err == nil {
+			t.Error("Expected smoke test failure")
+		}
+		assertUnchanged(t, execPath)
+	})
+
+	t.Run("rejects a binary reporting the wrong version", func(t *testing.T) {
+		execPath := installedExecutable(t)
+		binary := fakeBinary("9.9.8")
+		server, d := releaseServer(t, "lazysetup-9.9.9-linux-amd64", binary, binary)
+
+		if err := install(server.Client(), d, execPath); err == nil {
+			t.Error("Expected version mismatch")
+		}
+		assertUnchanged(t, execPath)
+	})
+}
+
+// assertUnchanged checks that a failed install left the executable alone and made no backup
+func assertUnchanged(t *testing.T, execPath string) {
+	t.Helper()
+	if got, _ := os.ReadFile(execPath); !bytes.Equal(got, fakeBinary("1.0.0")) {
+		t.Errorf("Expected executable unchanged, got %q", got)
+	}
+	if _, err := os.Stat(execPath + BackupSuffix); err == nil {
+		t.Error("Expected no backup after a failed install")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(execPath))
+	if len(entries) != 1 {
+		t.Errorf("Expected no leftover staged files, got %d entries", len(entries))
+	}
+}
+
+// TestExtractBinary tests pulling the lazysetup executable out of a release asset.
+// Priority: P1 - A wrong extraction installs an archive or the wrong file as the binary.
+// Tests .tar.gz, .tgz and .zip archives, raw binaries, and archives without a binary.
+func TestExtractBinary(t *testing.T) {
+	binary := fakeBinary("9.9.9")
+
+	t.Run("extracts the binary from each archive type", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			asset []byte
+		}{
+			{"lazysetup_linux_amd64.tar.gz", tarGz(t, "lazysetup_linux_amd64/lazysetup", binary)},
+			{"lazysetup_linux_amd64.tgz", tarGz(t, "lazysetup", binary)},
+			{"lazysetup_windows_amd64.zip", zipOf(t, "lazysetup_windows_amd64/lazysetup.exe", binary)},
+			{"LAZYSETUP_DARWIN_ARM64.ZIP", zipOf(t, "lazysetup", binary)},
+		}
+		for _, c := range cases {
+			got, err := extractBinary(c.name, c.asset)
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", c.name, err)
+				continue
+			}
+			if !bytes.Equal(got, binary) {
+				t.Errorf("%s: expected the binary, got %q", c.name, got)
+			}
+		}
+	})
+
+	t.Run("returns raw binaries unchanged", func(t *testing.T) {
+		got, err := extractBinary("lazysetup-linux-amd64", binary)
+		if err != nil || !bytes.Equal(got, binary) {
+			t.Errorf("Expected the raw binary, got %q (err %v)", got, err)
+		}
+	})
+
+	t.Run("rejects archives without a lazysetup binary", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			asset []byte
+		}{
+			{"lazysetup.tar.gz", tarGz(t, "README.md", []byte("docs"))},
+			{"lazysetup.zip", zipOf(t, "lazysetup-helper", binary)},
+		}
+		for _, c := range cases {
+			if _, err := extractBinary(c.name, c.asset); err == nil || !strings.Contains(err.Error(), "does not contain a lazysetup binary") {
+				t.Errorf("%s: expected a missing binary error, got %v", c.name, err)
+			}
+		}
+	})
+
+	t.Run("rejects corrupt archives", func(t *testing.T) {
+		for _, name := range []string{"lazysetup.tar.gz", "lazysetup.zip"} {
+			if _, err := extractBinary(name, binary); err == nil {
+				t.Errorf("%s: expected an error for a corrupt archive", name)
+			}
+		}
+	})
+}
+
+// TestParseChecksums tests reading SHA256SUMS.
+// Priority: P1 - Misparsing SHA256SUMS would reject or, worse, skip valid checks.
+// Tests text and binary mode entries and malformed lines.
+func TestParseChecksums(t *testing.T) {
+	t.Run("reads text and binary mode entries", func(t *testing.T) {
+		sums := parseChecksums([]byte("ABC123  lazysetup-linux-amd64\ndef456 *lazysetup-darwin-arm64\nnot a checksum line\n"))
+		if sums["lazysetup-linux-amd64"] != "abc123" || sums["lazysetup-darwin-arm64"] != "def456" {
+			t.Errorf("Unexpected checksums %v", sums)
+		}
+		if len(sums) != 2 {
+			t.Errorf("Expected 2 entries, got %d", len(sums))
+		}
+	})
+}
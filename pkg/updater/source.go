@@ -0,0 +1,224 @@
+package updater
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/youpele52/lazysetup/pkg/version"
+)
+
+// DefaultEndpoint is the releases API of the lazysetup repository on GitHub
+const DefaultEndpoint = "https://api.github.com/repos/" + version.GitHubOwner + "/" + version.GitHubRepo
+
+// Release channels decide which releases are offered as updates
+const (
+	ChannelStable     = "stable"     // Only the latest full release
+	ChannelPrerelease = "prerelease" // The newest release, including prereleases
+)
+
+// Channels lists the accepted release channels
+var Channels = []string{ChannelStable, ChannelPrerelease}
+
+// releasesPageSize is how many recent releases are looked at for the prerelease channel
+// and for the release notes of the versions between the running one and the latest
+const releasesPageSize = 20
+
+// Source is where updates are looked up
+type Source struct {
+	Endpoint string // Repository API URL serving GitHub's releases API, DefaultEndpoint when ""
+	Channel  string // One of Channels, ChannelStable when ""
+}
+
+// endpoint returns the repository API URL without a trailing slash
+func (s Source) endpoint() string {
+	if s.Endpoint == "" {
+		return DefaultEndpoint
+	}
+	return strings.TrimSuffix(s.Endpoint, "/")
+}
+
+// channel returns the release channel
+func (s Source) channel() string {
+	if s.Channel == "" {
+		return ChannelStable
+	}
+	return s.Channel
+}
+
+// fetchRelease returns the newest release on the source's channel
+// The stable channel asks for the latest release, which GitHub never resolves to a
+// prerelease or draft; the prerelease channel picks the newest of the recent releases
+func fetchRelease(client *http.Client, source Source) (*GitHubRelease, error) {
+	if source.channel() == ChannelStable {
+		var release GitHubRelease
+		if err := getJSON(client, source.endpoint()+"/releases/latest", &release); err != nil {
+			return nil, err
+		}
+		return &release, nil
+	}
+
+	var releases []GitHubRelease
+	url := fmt.Sprintf("%s/releases?per_page=%d", source.endpoint(), releasesPageSize)
+	if err := getJSON(client, url, &releases); err != nil {
+		return nil, err
+	}
+	var newest *GitHubRelease
+	for i := range releases {
+		if releases[i].Draft {
+			continue
+		}
+		if newest == nil || isNewerVersion(newest.TagName, releases[i].TagName) {
+			newest = &releases[i]
+		}
+	}
+	if newest == nil {
+		return nil, errors.New("no releases found")
+	}
+	return newest, nil
+}
+
+// fetchPending returns the releases on the source's channel that are newer than the
+// running version, up to and including latest, newest first
+// When the release list cannot be fetched only latest is returned
+func fetchPending(client *http.Client, source Source, latest *GitHubRelease) []GitHubRelease {
+	var releases []GitHubRelease
+	url := fmt.Sprintf("%s/releases?per_page=%d", source.endpoint(), releasesPageSize)
+	if err := getJSON(client, url, &releases); err != nil {
+		return []GitHubRelease{*latest}
+	}
+
+	pending := []GitHubRelease{*latest}
+	for _, release := range releases {
+		if release.Draft || release.TagName == latest.TagName ||
+			(release.Prerelease && source.channel() == ChannelStable) ||
+			!isNewerVersion(version.Version, release.TagName) ||
+			isNewerVersion(latest.TagName, release.TagName) {
+			continue
+		}
+		pending = append(pending, release)
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return isNewerVersion(pending[j].TagName, pending[i].TagName)
+	})
+	return pending
+}
+
+// getJSON fetches url from the releases API and decodes the response into v
+func getJSON(client *http.Client, url string, v any) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "lazysetup-updater")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("releases API returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// cachedCheck is the result of the last successful update check
+type cachedCheck struct {
+	CheckedAt time.Time       `json:"checked_at"`
+	Endpoint  string          `json:"endpoint"`
+	Channel   string          `json:"channel"`
+	Release   GitHubRelease   `json:"release"`
+	Pending   []GitHubRelease `json:"pending,omitempty"` // Releases newer than the version that checked, newest first
+}
+
+// CachePath returns the update check cache, $XDG_CACHE_HOME/lazysetup/update-check.json
+// (~/.cache when XDG_CACHE_HOME is unset)
+func CachePath() (string, error) {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate cache directory: %w", err)
+		}
+		dir = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(dir, "lazysetup", "update-check.json"), nil
+}
+
+// CheckForUpdatesCached is CheckForUpdates, answered from the cache at cachePath when
+// the same source was checked less than interval ago
+// Successful checks are written back to the cache; a cache that cannot be read or
+// written only means the releases API is asked again
+func CheckForUpdatesCached(source Source, cachePath string, interval time.Duration) *UpdateInfo {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return checkCached(client, source, cachePath, interval, time.Now())
+}
+
+// checkCached answers from the cache when it is fresh, otherwise checks and saves the result
+func checkCached(client *http.Client, source Source, cachePath string, interval time.Duration, now time.Time) *UpdateInfo {
+	if cached, ok := readCache(cachePath); ok &&
+		cached.Endpoint == source.endpoint() && cached.Channel == source.channel() &&
+		!cached.CheckedAt.After(now) && now.Sub(cached.CheckedAt) < interval {
+		return updateInfo(&cached.Release, cached.Pending)
+	}
+
+	release, err := fetchRelease(client, source)
+	if err != nil {
+		return &UpdateInfo{CurrentVersion: version.Version, Error: err}
+	}
+	var pending []GitHubRelease
+	if isNewerVersion(version.Version, release.TagName) {
+		pending = fetchPending(client, source, release)
+	}
+	writeCache(cachePath, cachedCheck{
+		CheckedAt: now,
+		Endpoint:  source.endpoint(),
+		Channel:   source.channel(),
+		Release:   *release,
+		Pending:   pending,
+	})
+	return updateInfo(release, pending)
+}
+
+// readCache loads the last successful check; ok is false when there is none
+func readCache(path string) (cachedCheck, bool) {
+	var cached cachedCheck
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cached, false
+	}
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return cached, false
+	}
+	return cached, true
+}
+
+// writeCache saves a successful check, replacing the file atomically
+func writeCache(path string, cached cachedCheck) error {
+	data, err := json.MarshalIndent(cached, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,187 @@
+package updater
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// releasesServer stands in for the releases API of a repository
+// It counts requests so tests can tell whether the cache answered
+func releasesServer(t *testing.T, releases []GitHubRelease) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/repos/acme/lazysetup/releases/latest":
+			for _, release := range releases {
+				if !release.Prerelease && !release.Draft {
+					json.NewEncoder(w).Encode(release)
+					return
+				}
+			}
+			http.NotFound(w, r)
+		case "/repos/acme/lazysetup/releases":
+			json.NewEncoder(w).Encode(releases)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+var testReleases = []GitHubRelease{
+	{TagName: "v99.1.0-rc.1", Prerelease: true},
+	{TagName: "v99.2.0", Draft: true},
+	{TagName: "v99.0.0", Body: "## Fixed\n- things"},
+	{TagName: "v98.0.0", Body: "## Added\n- stuff"},
+	{TagName: "v0.1.0"},
+}
+
+// TestFetchRelease tests looking up the newest release on a channel.
+// Priority: P1 - The wrong channel would offer prereleases to stable users.
+// Tests the stable and prerelease channels, drafts and API errors.
+func TestFetchRelease(t *testing.T) {
+	server, _ := releasesServer(t, testReleases)
+	endpoint := server.URL + "/repos/acme/lazysetup/"
+
+	t.Run("stable channel takes the latest full release", func(t *testing.T) {
+		release, err := fetchRelease(server.Client(), Source{Endpoint: endpoint})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if release.TagName != "v99.0.0" {
+			t.Errorf("Expected v99.0.0, got %s", release.TagName)
+		}
+	})
+
+	t.Run("prerelease channel takes the newest release but skips drafts", func(t *testing.T) {
+		release, err := fetchRelease(server.Client(), Source{Endpoint: endpoint, Channel: ChannelPrerelease})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if release.TagName != "v99.1.0-rc.1" {
+			t.Errorf("Expected v99.1.0-rc.1, got %s", release.TagName)
+		}
+	})
+
+	t.Run("reports API errors", func(t *testing.T) {
+		if _, err := fetchRelease(server.Client(), Source{Endpoint: server.URL + "/repos/acme/missing"}); err == nil {
+			t.Error("Expected error for a missing repository")
+		}
+	})
+}
+
+// TestCheckCached tests caching update checks.
+// Priority: P1 - Startup must not ask the releases API more than once per interval.
+// Tests fresh and stale caches, source changes and failed checks.
+func TestCheckCached(t *testing.T) {
+	server, requests := releasesServer(t, testReleases)
+	source := Source{Endpoint: server.URL + "/repos/acme/lazysetup"}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("answers from the cache within the interval", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "update-check.json")
+		atomic.StoreInt32(requests, 0)
+
+		first := checkCached(server.Client(), source, path, time.Hour, now)
+		second := checkCached(server.Client(), source, path, time.Hour, now.Add(30*time.Minute))
+		if first.Error != nil || !first.Available || first.LatestVersion != "99.0.0" {
+			t.Fatalf("Expected 99.0.0 available, got %+v", first)
+		}
+		if second.LatestVersion != "99.0.0" || !second.Available {
+			t.Errorf("Expected cached 99.0.0, got %+v", second)
+		}
+		if len(second.Notes) != 2 {
+			t.Errorf("Expected cached notes of 2 releases, got %d", len(second.Notes))
+		}
+		if got := atomic.LoadInt32(requests); got != 2 {
+			t.Errorf("Expected 2 requests for one check, got %d", got)
+		}
+	})
+
+	t.Run("checks again once the interval has passed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "update-check.json")
+		atomic.StoreInt32(requests, 0)
+
+		checkCached(server.Client(), source, path, time.Hour, now)
+		checkCached(server.Client(), source, path, time.Hour, now.Add(2*time.Hour))
+		if got := atomic.LoadInt32(requests); got != 4 {
+			t.Errorf("Expected 4 requests for two checks, got %d", got)
+		}
+	})
+
+	t.Run("checks again when the channel changes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "update-check.json")
+		atomic.StoreInt32(requests, 0)
+
+		checkCached(server.Client(), source, path, time.Hour, now)
+		info := checkCached(server.Client(), Source{Endpoint: source.Endpoint, Channel: ChannelPrerelease}, path, time.Hour, now)
+		if info.LatestVersion != "99.1.0-rc.1" {
+			t.Errorf("Expected prerelease 99.1.0-rc.1, got %s", info.LatestVersion)
+		}
+		if got := atomic.LoadInt32(requests); got != 4 {
+			t.Errorf("Expected 4 requests for two checks, got %d", got)
+		}
+	})
+
+	t.Run("does not cache failed checks", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "update-check.json")
+		missing := Source{Endpoint: server.URL + "/repos/acme/missing"}
+		atomic.StoreInt32(requests, 0)
+
+		if info := checkCached(server.Client(), missing, path, time.Hour, now); info.Error == nil {
+			t.Error("Expected error for a missing repository")
+		}
+		checkCached(server.Client(), missing, path, time.Hour, now)
+		if got := atomic.LoadInt32(requests); got != 2 {
+			t.Errorf("Expected 2 requests, got %d", got)
+		}
+	})
+}
+
+// TestUpdateInfo_Notes tests collecting the release notes of every pending release.
+// Priority: P2 - Users several versions behind should see everything that changed.
+// Tests intermediate releases, ordering and channel filtering.
+func TestUpdateInfo_Notes(t *testing.T) {
+	server, _ := releasesServer(t, testReleases)
+	endpoint := server.URL + "/repos/acme/lazysetup"
+	versions := func(info *UpdateInfo) []string {
+		var out []string
+		for _, note := range info.Notes {
+			out = append(out, note.Version)
+		}
+		return out
+	}
+
+	t.Run("stable channel lists full releases newer than the running version", func(t *testing.T) {
+		info := checkCached(server.Client(), Source{Endpoint: endpoint}, filepath.Join(t.TempDir(), "c.json"), time.Hour, time.Now())
+		if got := versions(info); len(got) != 2 || got[0] != "99.0.0" || got[1] != "98.0.0" {
+			t.Errorf("Expected [99.0.0 98.0.0], got %v", got)
+		}
+		if info.Notes[1].Body != "## Added\n- stuff" {
+			t.Errorf("Expected 98.0.0 notes, got %q", info.Notes[1].Body)
+		}
+	})
+
+	t.Run("prerelease channel includes prereleases", func(t *testing.T) {
+		info := checkCached(server.Client(), Source{Endpoint: endpoint, Channel: ChannelPrerelease}, filepath.Join(t.TempDir(), "c.json"), time.Hour, time.Now())
+		if got := versions(info); len(got) != 3 || got[0] != "99.1.0-rc.1" {
+			t.Errorf("Expected [99.1.0-rc.1 99.0.0 98.0.0], got %v", got)
+		}
+	})
+
+	t.Run("falls back to the latest release when the list is unavailable", func(t *testing.T) {
+		latest := &GitHubRelease{TagName: "v99.0.0", Body: "notes"}
+		pending := fetchPending(server.Client(), Source{Endpoint: server.URL + "/repos/acme/missing"}, latest)
+		if len(pending) != 1 || pending[0].TagName != "v99.0.0" {
+			t.Errorf("Expected only the latest release, got %v", pending)
+		}
+	})
+}
@@ -1,9 +1,7 @@
 package updater
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"os/exec"
@@ -21,6 +19,8 @@ type GitHubRelease struct {
 	Body        string  `json:"body"`
 	HTMLURL     string  `json:"html_url"`
 	PublishedAt string  `json:"published_at"`
+	Prerelease  bool    `json:"prerelease"`
+	Draft       bool    `json:"draft"`
 	Assets      []Asset `json:"assets"`
 }
 
@@ -39,62 +39,90 @@ type UpdateInfo struct {
 	ReleaseURL     string
 	ReleaseNotes   string
 	DownloadURL    string
+	AssetName      string        // File name of the asset at DownloadURL
+	ChecksumURL    string        // URL of the release's SHA256SUMS, "" when it has none
+	Notes          []ReleaseNote // Every release newer than the current version, newest first
 	Error          error
 }
 
-// CheckForUpdates checks GitHub for the latest release
-func CheckForUpdates() *UpdateInfo {
-	info := &UpdateInfo{
-		CurrentVersion: version.Version,
-	}
+// ReleaseNote is the changelog of one release
+type ReleaseNote struct {
+	Version     string // Without the "v" prefix
+	URL         string // Release page
+	Body        string // Markdown release notes
+	PublishedAt string // RFC 3339 publication time
+}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest",
-		version.GitHubOwner, version.GitHubRepo)
+// Download returns what DownloadAndInstall needs to install this update
+func (info *UpdateInfo) Download() Download {
+	return Download{
+		URL:         info.DownloadURL,
+		Name:        info.AssetName,
+		ChecksumURL: info.ChecksumURL,
+		Version:     info.LatestVersion,
+	}
+}
 
+// CheckForUpdates asks the source for its newest release on the configured channel
+func CheckForUpdates(source Source) *UpdateInfo {
 	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
+	release, err := fetchRelease(client, source)
 	if err != nil {
-		info.Error = err
-		return info
+		return &UpdateInfo{CurrentVersion: version.Version, Error: err}
 	}
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "lazysetup-updater")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		info.Error = err
-		return info
+	var pending []GitHubRelease
+	if isNewerVersion(version.Version, release.TagName) {
+		pending = fetchPending(client, source, release)
 	}
-	defer resp.Body.Close()
+	return updateInfo(release, pending)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		info.Error = fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-		return info
+// updateInfo describes release as an update from the running version, with the notes of
+// the pending releases still newer than it
+func updateInfo(release *GitHubRelease, pending []GitHubRelease) *UpdateInfo {
+	info := &UpdateInfo{
+		CurrentVersion: version.Version,
+		LatestVersion:  strings.TrimPrefix(release.TagName, "v"),
+		ReleaseURL:     release.HTMLURL,
+		ReleaseNotes:   release.Body,
+		ChecksumURL:    findChecksumURL(release.Assets),
 	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		info.Error = err
-		return info
+	if asset, ok := findAsset(release.Assets); ok {
+		info.DownloadURL = asset.BrowserDownloadURL
+		info.AssetName = asset.Name
 	}
-
-	var release GitHubRelease
-	if err := json.Unmarshal(body, &release); err != nil {
-		info.Error = err
+	info.Available = isNewerVersion(info.CurrentVersion, info.LatestVersion)
+	if !info.Available {
 		return info
 	}
-
-	info.LatestVersion = strings.TrimPrefix(release.TagName, "v")
-	info.ReleaseURL = release.HTMLURL
-	info.ReleaseNotes = release.Body
-	info.DownloadURL = findDownloadURL(release.Assets)
-	info.Available = isNewerVersion(info.CurrentVersion, info.LatestVersion)
-
+	for _, r := range pending {
+		if isNewerVersion(info.CurrentVersion, r.TagName) {
+			info.Notes = append(info.Notes, ReleaseNote{
+				Version:     strings.TrimPrefix(r.TagName, "v"),
+				URL:         r.HTMLURL,
+				Body:        r.Body,
+				PublishedAt: r.PublishedAt,
+			})
+		}
+	}
+	if len(info.Notes) == 0 {
+		info.Notes = []ReleaseNote{{Version: info.LatestVersion, URL: release.HTMLURL, Body: release.Body, PublishedAt: release.PublishedAt}}
+	}
 	return info
 }
 
-// findDownloadURL finds the appropriate download URL for the current OS/arch
-func findDownloadURL(assets []Asset) string {
+// findChecksumURL returns the download URL of the release's SHA256SUMS, or ""
+func findChecksumURL(assets []Asset) string {
+	for _, asset := range assets {
+		if asset.Name == ChecksumsAsset {
+			return asset.BrowserDownloadURL
+		}
+	}
+	return ""
+}
+
+// findAsset finds the release asset for the current OS/arch: a raw binary or an archive
+func findAsset(assets []Asset) (Asset, bool) {
 	osName := runtime.GOOS
 	arch := runtime.GOARCH
 
@@ -108,8 +136,8 @@ func findDownloadURL(assets []Asset) string {
 	for _, asset := range assets {
 		name := strings.ToLower(asset.Name)
 
-		// Check if asset matches OS
-		if !strings.Contains(name, osName) {
+		// Check if asset matches OS, skipping per-asset checksums and signatures
+		if !strings.Contains(name, osName) || strings.HasSuffix(name, ".sha256") || strings.HasSuffix(name, ".sig") || strings.HasSuffix(name, ".asc") {
 			continue
 		}
 
@@ -117,16 +145,17 @@ func findDownloadURL(assets []Asset) string {
 		if archList, ok := archNames[arch]; ok {
 			for _, archName := range archList {
 				if strings.Contains(name, archName) {
-					return asset.BrowserDownloadURL
+					return asset, true
 				}
 			}
 		}
 	}
 
-	return ""
+	return Asset{}, false
 }
 
 // isNewerVersion compares two semantic versions
+// A prerelease ("1.2.0-rc.1") is older than its release and prerelease labels compare as text
 func isNewerVersion(current, latest string) bool {
 	current = strings.TrimPrefix(current, "v")
 	latest = strings.TrimPrefix(latest, "v")
@@ -135,6 +164,19 @@ func isNewerVersion(current, latest string) bool {
 		return false
 	}
 
+	current, currentLabel, _ := strings.Cut(current, "-")
+	latest, latestLabel, _ := strings.Cut(latest, "-")
+	if current == latest {
+		switch {
+		case currentLabel == "":
+			return false
+		case latestLabel == "":
+			return true
+		default:
+			return latestLabel > currentLabel
+		}
+	}
+
 	currentParts := strings.Split(current, ".")
 	latestParts := strings.Split(latest, ".")
 
@@ -153,78 +195,6 @@ func isNewerVersion(current, latest string) bool {
 	return len(latestParts) > len(currentParts)
 }
 
-// DownloadAndInstall downloads and installs the update
-func DownloadAndInstall(downloadURL string) error {
-	if downloadURL == "" {
-		return fmt.Errorf("no download URL available for %s/%s", runtime.GOOS, runtime.GOARCH)
-	}
-
-	// Get current executable path
-	execPath, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
-	}
-
-	// Download to temp file
-	tmpFile, err := os.CreateTemp("", "lazysetup-update-*")
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer os.Remove(tmpFile.Name())
-
-	client := &http.Client{Timeout: 5 * time.Minute}
-	resp, err := client.Get(downloadURL)
-	if err != nil {
-		return fmt.Errorf("failed to download update: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status %d", resp.StatusCode)
-	}
-
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
-		return fmt.Errorf("failed to write update: %w", err)
-	}
-	tmpFile.Close()
-
-	// Make executable
-	if err := os.Chmod(tmpFile.Name(), 0755); err != nil {
-		return fmt.Errorf("failed to set permissions: %w", err)
-	}
-
-	// Replace current executable
-	if err := os.Rename(tmpFile.Name(), execPath); err != nil {
-		// Try copy if rename fails (cross-device)
-		if err := copyFile(tmpFile.Name(), execPath); err != nil {
-			return fmt.Errorf("failed to replace executable: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// copyFile copies a file from src to dst
-func copyFile(src, dst string) error {
-	source, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer source.Close()
-
-	dest, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer dest.Close()
-
-	if _, err := io.Copy(dest, source); err != nil {
-		return err
-	}
-
-	return os.Chmod(dst, 0755)
-}
-
 // RestartApplication restarts the application
 func RestartApplication() error {
 	execPath, err := os.Executable()
@@ -1,6 +1,7 @@
 package updater
 
 import (
+	"runtime"
 	"testing"
 )
 
@@ -101,64 +102,94 @@ func TestIsNewerVersion_EdgeCases(t *testing.T) {
 			t.Error("Expected 1.10.0 to be newer than 1.9.0")
 		}
 	})
+
+	t.Run("release is newer than its prerelease", func(t *testing.T) {
+		if !isNewerVersion("1.2.0-rc.1", "1.2.0") || isNewerVersion("1.2.0", "1.2.0-rc.1") {
+			t.Error("Expected 1.2.0 to be newer than 1.2.0-rc.1")
+		}
+	})
+
+	t.Run("compares prerelease labels", func(t *testing.T) {
+		if !isNewerVersion("1.2.0-rc.1", "1.2.0-rc.2") {
+			t.Error("Expected 1.2.0-rc.2 to be newer than 1.2.0-rc.1")
+		}
+		if !isNewerVersion("1.1.0", "1.2.0-rc.1") {
+			t.Error("Expected 1.2.0-rc.1 to be newer than 1.1.0")
+		}
+	})
 }
 
-// TestFindDownloadURL_CorrectAssetFound tests that findDownloadURL finds the correct asset for current OS/arch.
+// TestFindAsset tests that findAsset finds the correct asset for the current OS/arch.
 // Priority: P1 - Update download depends on finding the correct binary for the platform.
-// Tests asset matching for current platform, no matching assets, and empty assets list.
-func TestFindDownloadURL_CorrectAssetFound(t *testing.T) {
-	t.Run("finds darwin-amd64 asset", func(t *testing.T) {
+// Tests platform matching, architecture variants, archives, checksum and signature files, and no match.
+func TestFindAsset(t *testing.T) {
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	other := "plan9"
+	if runtime.GOOS == other {
+		other = "aix"
+	}
+
+	t.Run("finds the asset for this platform", func(t *testing.T) {
 		assets := []Asset{
-			{Name: "lazysetup-v1.0.0-linux-amd64", BrowserDownloadURL: "https://example.com/linux-amd64"},
-			{Name: "lazysetup-v1.0.0-darwin-amd64", BrowserDownloadURL: "https://example.com/darwin-amd64"},
-			{Name: "lazysetup-v1.0.0-darwin-arm64", BrowserDownloadURL: "https://example.com/darwin-arm64"},
+			{Name: "lazysetup-v1.0.0-" + other + "-" + runtime.GOARCH, BrowserDownloadURL: "https://example.com/other"},
+			{Name: "lazysetup-v1.0.0-" + platform, BrowserDownloadURL: "https://example.com/this"},
+		}
+		asset, ok := findAsset(assets)
+		if !ok || asset.BrowserDownloadURL != "https://example.com/this" {
+			t.Errorf("Expected the %s asset, got %+v (found %v)", platform, asset, ok)
 		}
-
-		// This test will find the asset matching the current runtime
-		url := findDownloadURL(assets)
-		// URL should be non-empty if current OS/arch matches one of the assets
-		t.Logf("Found URL for current platform: %s", url)
 	})
 
-	t.Run("returns empty for no matching assets", func(t *testing.T) {
+	t.Run("returns nothing for other platforms", func(t *testing.T) {
 		assets := []Asset{
-			{Name: "lazysetup-v1.0.0-windows-amd64.exe", BrowserDownloadURL: "https://example.com/windows"},
+			{Name: "lazysetup-v1.0.0-" + other + "-" + runtime.GOARCH, BrowserDownloadURL: "https://example.com/other"},
+		}
+		if asset, ok := findAsset(assets); ok {
+			t.Errorf("Expected no asset, got %+v", asset)
 		}
-
-		// On non-Windows systems, this should return empty
-		url := findDownloadURL(assets)
-		t.Logf("URL for non-matching assets: '%s'", url)
 	})
 
 	t.Run("handles empty assets list", func(t *testing.T) {
-		assets := []Asset{}
-		url := findDownloadURL(assets)
-		if url != "" {
-			t.Errorf("Expected empty URL for empty assets, got '%s'", url)
+		if asset, ok := findAsset(nil); ok {
+			t.Errorf("Expected no asset, got %+v", asset)
 		}
 	})
-}
 
-// TestFindDownloadURL_ArchitectureMatching tests architecture variant matching (x86_64, aarch64).
-// Priority: P2 - No match returns empty, handles different architecture naming conventions.
-// Tests amd64 variants (x86_64, x64) and arm64 variants (aarch64).
-func TestFindDownloadURL_ArchitectureMatching(t *testing.T) {
-	t.Run("matches amd64 variants", func(t *testing.T) {
-		// Test that x86_64 and x64 are recognized as amd64
-		assets := []Asset{
-			{Name: "lazysetup-darwin-x86_64", BrowserDownloadURL: "https://example.com/x86_64"},
+	t.Run("matches architecture variants", func(t *testing.T) {
+		variants := map[string][]string{"amd64": {"x86_64", "x64"}, "arm64": {"aarch64"}, "386": {"i386"}}[runtime.GOARCH]
+		if variants == nil {
+			t.Skipf("no architecture variants for %s", runtime.GOARCH)
+		}
+		for _, variant := range variants {
+			name := "lazysetup-" + runtime.GOOS + "-" + variant
+			if asset, ok := findAsset([]Asset{{Name: name}}); !ok || asset.Name != name {
+				t.Errorf("Expected %s to match %s, got %+v", name, runtime.GOARCH, asset)
+			}
+		}
+	})
+
+	t.Run("matches archives", func(t *testing.T) {
+		for _, name := range []string{"lazysetup_1.0.0_" + runtime.GOOS + "_" + runtime.GOARCH + ".tar.gz", "lazysetup-" + platform + ".zip"} {
+			if asset, ok := findAsset([]Asset{{Name: name}}); !ok || asset.Name != name {
+				t.Errorf("Expected archive %s to match, got %+v", name, asset)
+			}
 		}
-		url := findDownloadURL(assets)
-		t.Logf("URL for x86_64 variant: '%s'", url)
 	})
 
-	t.Run("matches arm64 variants", func(t *testing.T) {
-		// Test that aarch64 is recognized as arm64
+	t.Run("skips checksums and signatures", func(t *testing.T) {
+		archive := "lazysetup-" + platform + ".tar.gz"
 		assets := []Asset{
-			{Name: "lazysetup-darwin-aarch64", BrowserDownloadURL: "https://example.com/aarch64"},
+			{Name: archive + ".sha256"},
+			{Name: archive + ".sig"},
+			{Name: archive + ".asc"},
+			{Name: archive},
+		}
+		if asset, ok := findAsset(assets); !ok || asset.Name != archive {
+			t.Errorf("Expected %s, got %+v", archive, asset)
+		}
+		if asset, ok := findAsset(assets[:3]); ok {
+			t.Errorf("Expected checksums and signatures alone to match nothing, got %+v", asset)
 		}
-		url := findDownloadURL(assets)
-		t.Logf("URL for aarch64 variant: '%s'", url)
 	})
 }
 
@@ -236,21 +267,9 @@ func TestGitHubRelease_Structure(t *testing.T) {
 // Tests that DownloadAndInstall returns an error when given an empty URL.
 func TestDownloadAndInstall_EmptyURL(t *testing.T) {
 	t.Run("returns error for empty URL", func(t *testing.T) {
-		err := DownloadAndInstall("")
+		err := DownloadAndInstall(Download{})
 		if err == nil {
 			t.Error("Expected error for empty download URL")
 		}
 	})
 }
-
-// TestCopyFile_NonExistentSource tests error handling for non-existent source file.
-// Priority: P2 - Helper function validation for cross-device copy fallback.
-// Tests that copyFile returns an error when source file doesn't exist.
-func TestCopyFile_NonExistentSource(t *testing.T) {
-	t.Run("returns error for non-existent source", func(t *testing.T) {
-		err := copyFile("/nonexistent/path/file", "/tmp/dest")
-		if err == nil {
-			t.Error("Expected error for non-existent source file")
-		}
-	})
-}